@@ -0,0 +1,1326 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: api/featureatlas/v1/featureatlas.proto
+
+package featureatlasv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Feature is a feature catalog entry.
+type Feature struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID such as FT-000123.
+	Id      string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name    string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Summary string   `protobuf:"bytes,3,opt,name=summary,proto3" json:"summary,omitempty"`
+	Owner   string   `protobuf:"bytes,4,opt,name=owner,proto3" json:"owner,omitempty"`
+	Tags    []string `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Lifecycle status: proposed, active, deprecated or removed.
+	Status string `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	// Successor of a deprecated or removed feature.
+	ReplacedBy string `protobuf:"bytes,7,opt,name=replaced_by,json=replacedBy,proto3" json:"replaced_by,omitempty"`
+	// Increases with every update; the REST API's ETag.
+	Version       int64                  `protobuf:"varint,8,opt,name=version,proto3" json:"version,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Feature) Reset() {
+	*x = Feature{}
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Feature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Feature) ProtoMessage() {}
+
+func (x *Feature) ProtoReflect() protoreflect.Message {
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Feature.ProtoReflect.Descriptor instead.
+func (*Feature) Descriptor() ([]byte, []int) {
+	return file_api_featureatlas_v1_featureatlas_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Feature) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Feature) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Feature) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *Feature) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *Feature) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Feature) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Feature) GetReplacedBy() string {
+	if x != nil {
+		return x.ReplacedBy
+	}
+	return ""
+}
+
+func (x *Feature) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Feature) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Feature) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+// Client is a registered client certificate.
+type Client struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Hex SHA-256 of the certificate.
+	Fingerprint string `protobuf:"bytes,1,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	Name        string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Role: user, editor, maintainer or admin.
+	Role string `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"`
+	// Feature owners the client may edit with features:write:own.
+	Teams     []string               `protobuf:"bytes,4,rep,name=teams,proto3" json:"teams,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Revoked   bool                   `protobuf:"varint,6,opt,name=revoked,proto3" json:"revoked,omitempty"`
+	RevokedAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=revoked_at,json=revokedAt,proto3" json:"revoked_at,omitempty"`
+	// "clients-file" when declared in the server's clients file.
+	Source string `protobuf:"bytes,8,opt,name=source,proto3" json:"source,omitempty"`
+	// Certificate replaced by a renewal, accepted until the new one is used.
+	PreviousFingerprint string `protobuf:"bytes,9,opt,name=previous_fingerprint,json=previousFingerprint,proto3" json:"previous_fingerprint,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Client) Reset() {
+	*x = Client{}
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Client) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Client) ProtoMessage() {}
+
+func (x *Client) ProtoReflect() protoreflect.Message {
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Client.ProtoReflect.Descriptor instead.
+func (*Client) Descriptor() ([]byte, []int) {
+	return file_api_featureatlas_v1_featureatlas_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Client) GetFingerprint() string {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return ""
+}
+
+func (x *Client) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Client) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *Client) GetTeams() []string {
+	if x != nil {
+		return x.Teams
+	}
+	return nil
+}
+
+func (x *Client) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Client) GetRevoked() bool {
+	if x != nil {
+		return x.Revoked
+	}
+	return false
+}
+
+func (x *Client) GetRevokedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RevokedAt
+	}
+	return nil
+}
+
+func (x *Client) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Client) GetPreviousFingerprint() string {
+	if x != nil {
+		return x.PreviousFingerprint
+	}
+	return ""
+}
+
+type GetMeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMeRequest) Reset() {
+	*x = GetMeRequest{}
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMeRequest) ProtoMessage() {}
+
+func (x *GetMeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMeRequest.ProtoReflect.Descriptor instead.
+func (*GetMeRequest) Descriptor() ([]byte, []int) {
+	return file_api_featureatlas_v1_featureatlas_proto_rawDescGZIP(), []int{2}
+}
+
+// Me describes the authenticated client.
+type Me struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Role  string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	// Permissions the role grants.
+	Permissions []string `protobuf:"bytes,3,rep,name=permissions,proto3" json:"permissions,omitempty"`
+	Teams       []string `protobuf:"bytes,4,rep,name=teams,proto3" json:"teams,omitempty"`
+	// Fingerprint of the registered client.
+	Fingerprint string `protobuf:"bytes,5,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	// Fingerprint of the presented certificate; differs when an identity rule
+	// matched.
+	CertFingerprint string `protobuf:"bytes,6,opt,name=cert_fingerprint,json=certFingerprint,proto3" json:"cert_fingerprint,omitempty"`
+	Subject         string `protobuf:"bytes,7,opt,name=subject,proto3" json:"subject,omitempty"`
+	// "fingerprint", or the identity rule that matched.
+	MatchedBy     string `protobuf:"bytes,8,opt,name=matched_by,json=matchedBy,proto3" json:"matched_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Me) Reset() {
+	*x = Me{}
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Me) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Me) ProtoMessage() {}
+
+func (x *Me) ProtoReflect() protoreflect.Message {
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Me.ProtoReflect.Descriptor instead.
+func (*Me) Descriptor() ([]byte, []int) {
+	return file_api_featureatlas_v1_featureatlas_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Me) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Me) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *Me) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+func (x *Me) GetTeams() []string {
+	if x != nil {
+		return x.Teams
+	}
+	return nil
+}
+
+func (x *Me) GetFingerprint() string {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return ""
+}
+
+func (x *Me) GetCertFingerprint() string {
+	if x != nil {
+		return x.CertFingerprint
+	}
+	return ""
+}
+
+func (x *Me) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *Me) GetMatchedBy() string {
+	if x != nil {
+		return x.MatchedBy
+	}
+	return ""
+}
+
+type SearchFeaturesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Search query in the REST API's query language; empty lists the catalog
+	// by ID.
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Page size; 0 means 20, and at most 500.
+	Limit int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// next_cursor of the previous page.
+	Cursor        string `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchFeaturesRequest) Reset() {
+	*x = SearchFeaturesRequest{}
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchFeaturesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchFeaturesRequest) ProtoMessage() {}
+
+func (x *SearchFeaturesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchFeaturesRequest.ProtoReflect.Descriptor instead.
+func (*SearchFeaturesRequest) Descriptor() ([]byte, []int) {
+	return file_api_featureatlas_v1_featureatlas_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SearchFeaturesRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchFeaturesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchFeaturesRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type SearchFeaturesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Items []*Feature             `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	// Pass as cursor for the next page; empty on the last page.
+	NextCursor    string `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchFeaturesResponse) Reset() {
+	*x = SearchFeaturesResponse{}
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchFeaturesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchFeaturesResponse) ProtoMessage() {}
+
+func (x *SearchFeaturesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchFeaturesResponse.ProtoReflect.Descriptor instead.
+func (*SearchFeaturesResponse) Descriptor() ([]byte, []int) {
+	return file_api_featureatlas_v1_featureatlas_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SearchFeaturesResponse) GetItems() []*Feature {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *SearchFeaturesResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type SuggestFeaturesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Query string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Maximum number of suggestions; 0 means 10, and at most 100.
+	Limit         int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuggestFeaturesRequest) Reset() {
+	*x = SuggestFeaturesRequest{}
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuggestFeaturesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuggestFeaturesRequest) ProtoMessage() {}
+
+func (x *SuggestFeaturesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuggestFeaturesRequest.ProtoReflect.Descriptor instead.
+func (*SuggestFeaturesRequest) Descriptor() ([]byte, []int) {
+	return file_api_featureatlas_v1_featureatlas_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SuggestFeaturesRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SuggestFeaturesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// Highlight is a span of a suggestion that matched the query, in runes.
+type Highlight struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// "id" or "name".
+	Field         string `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Start         int32  `protobuf:"varint,2,opt,name=start,proto3" json:"start,omitempty"`
+	End           int32  `protobuf:"varint,3,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Highlight) Reset() {
+	*x = Highlight{}
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Highlight) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Highlight) ProtoMessage() {}
+
+func (x *Highlight) ProtoReflect() protoreflect.Message {
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Highlight.ProtoReflect.Descriptor instead.
+func (*Highlight) Descriptor() ([]byte, []int) {
+	return file_api_featureatlas_v1_featureatlas_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Highlight) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *Highlight) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *Highlight) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+// Suggestion is an autocomplete result.
+type Suggestion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Summary       string                 `protobuf:"bytes,3,opt,name=summary,proto3" json:"summary,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Highlights    []*Highlight           `protobuf:"bytes,5,rep,name=highlights,proto3" json:"highlights,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Suggestion) Reset() {
+	*x = Suggestion{}
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Suggestion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Suggestion) ProtoMessage() {}
+
+func (x *Suggestion) ProtoReflect() protoreflect.Message {
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Suggestion.ProtoReflect.Descriptor instead.
+func (*Suggestion) Descriptor() ([]byte, []int) {
+	return file_api_featureatlas_v1_featureatlas_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Suggestion) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Suggestion) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Suggestion) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *Suggestion) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Suggestion) GetHighlights() []*Highlight {
+	if x != nil {
+		return x.Highlights
+	}
+	return nil
+}
+
+type SuggestFeaturesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Best first.
+	Items         []*Suggestion `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuggestFeaturesResponse) Reset() {
+	*x = SuggestFeaturesResponse{}
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuggestFeaturesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuggestFeaturesResponse) ProtoMessage() {}
+
+func (x *SuggestFeaturesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuggestFeaturesResponse.ProtoReflect.Descriptor instead.
+func (*SuggestFeaturesResponse) Descriptor() ([]byte, []int) {
+	return file_api_featureatlas_v1_featureatlas_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SuggestFeaturesResponse) GetItems() []*Suggestion {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type GetFeatureRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Return the revision in effect at this time instead of the current one.
+	At            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=at,proto3" json:"at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFeatureRequest) Reset() {
+	*x = GetFeatureRequest{}
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFeatureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFeatureRequest) ProtoMessage() {}
+
+func (x *GetFeatureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFeatureRequest.ProtoReflect.Descriptor instead.
+func (*GetFeatureRequest) Descriptor() ([]byte, []int) {
+	return file_api_featureatlas_v1_featureatlas_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetFeatureRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetFeatureRequest) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+type CreateFeatureRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Name    string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Summary string                 `protobuf:"bytes,2,opt,name=summary,proto3" json:"summary,omitempty"`
+	Owner   string                 `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`
+	Tags    []string               `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+	// proposed or active; empty means active.
+	Status        string `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateFeatureRequest) Reset() {
+	*x = CreateFeatureRequest{}
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateFeatureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateFeatureRequest) ProtoMessage() {}
+
+func (x *CreateFeatureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateFeatureRequest.ProtoReflect.Descriptor instead.
+func (*CreateFeatureRequest) Descriptor() ([]byte, []int) {
+	return file_api_featureatlas_v1_featureatlas_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CreateFeatureRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateFeatureRequest) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *CreateFeatureRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *CreateFeatureRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *CreateFeatureRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ListClientsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListClientsRequest) Reset() {
+	*x = ListClientsRequest{}
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListClientsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListClientsRequest) ProtoMessage() {}
+
+func (x *ListClientsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListClientsRequest.ProtoReflect.Descriptor instead.
+func (*ListClientsRequest) Descriptor() ([]byte, []int) {
+	return file_api_featureatlas_v1_featureatlas_proto_rawDescGZIP(), []int{12}
+}
+
+type ListClientsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// By name.
+	Items         []*Client `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListClientsResponse) Reset() {
+	*x = ListClientsResponse{}
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListClientsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListClientsResponse) ProtoMessage() {}
+
+func (x *ListClientsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListClientsResponse.ProtoReflect.Descriptor instead.
+func (*ListClientsResponse) Descriptor() ([]byte, []int) {
+	return file_api_featureatlas_v1_featureatlas_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListClientsResponse) GetItems() []*Client {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type RegisterClientRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Empty means user.
+	Role  string   `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	Teams []string `protobuf:"bytes,3,rep,name=teams,proto3" json:"teams,omitempty"`
+	// PEM certificate to register.
+	CertPem       string `protobuf:"bytes,4,opt,name=cert_pem,json=certPem,proto3" json:"cert_pem,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterClientRequest) Reset() {
+	*x = RegisterClientRequest{}
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterClientRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterClientRequest) ProtoMessage() {}
+
+func (x *RegisterClientRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterClientRequest.ProtoReflect.Descriptor instead.
+func (*RegisterClientRequest) Descriptor() ([]byte, []int) {
+	return file_api_featureatlas_v1_featureatlas_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RegisterClientRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RegisterClientRequest) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *RegisterClientRequest) GetTeams() []string {
+	if x != nil {
+		return x.Teams
+	}
+	return nil
+}
+
+func (x *RegisterClientRequest) GetCertPem() string {
+	if x != nil {
+		return x.CertPem
+	}
+	return ""
+}
+
+type RegisterClientResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Client *Client                `protobuf:"bytes,1,opt,name=client,proto3" json:"client,omitempty"`
+	// Subject of the registered certificate.
+	Subject       string `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterClientResponse) Reset() {
+	*x = RegisterClientResponse{}
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterClientResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterClientResponse) ProtoMessage() {}
+
+func (x *RegisterClientResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterClientResponse.ProtoReflect.Descriptor instead.
+func (*RegisterClientResponse) Descriptor() ([]byte, []int) {
+	return file_api_featureatlas_v1_featureatlas_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RegisterClientResponse) GetClient() *Client {
+	if x != nil {
+		return x.Client
+	}
+	return nil
+}
+
+func (x *RegisterClientResponse) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+type RevokeClientRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Hex SHA-256 of the certificate; colons and case are ignored.
+	Fingerprint   string `protobuf:"bytes,1,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeClientRequest) Reset() {
+	*x = RevokeClientRequest{}
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeClientRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeClientRequest) ProtoMessage() {}
+
+func (x *RevokeClientRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_featureatlas_v1_featureatlas_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeClientRequest.ProtoReflect.Descriptor instead.
+func (*RevokeClientRequest) Descriptor() ([]byte, []int) {
+	return file_api_featureatlas_v1_featureatlas_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RevokeClientRequest) GetFingerprint() string {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return ""
+}
+
+var File_api_featureatlas_v1_featureatlas_proto protoreflect.FileDescriptor
+
+const file_api_featureatlas_v1_featureatlas_proto_rawDesc = "" +
+	"\n" +
+	"&api/featureatlas/v1/featureatlas.proto\x12\x0ffeatureatlas.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xba\x02\n" +
+	"\aFeature\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\asummary\x18\x03 \x01(\tR\asummary\x12\x14\n" +
+	"\x05owner\x18\x04 \x01(\tR\x05owner\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12\x1f\n" +
+	"\vreplaced_by\x18\a \x01(\tR\n" +
+	"replacedBy\x12\x18\n" +
+	"\aversion\x18\b \x01(\x03R\aversion\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xc3\x02\n" +
+	"\x06Client\x12 \n" +
+	"\vfingerprint\x18\x01 \x01(\tR\vfingerprint\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04role\x18\x03 \x01(\tR\x04role\x12\x14\n" +
+	"\x05teams\x18\x04 \x03(\tR\x05teams\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x18\n" +
+	"\arevoked\x18\x06 \x01(\bR\arevoked\x129\n" +
+	"\n" +
+	"revoked_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\trevokedAt\x12\x16\n" +
+	"\x06source\x18\b \x01(\tR\x06source\x121\n" +
+	"\x14previous_fingerprint\x18\t \x01(\tR\x13previousFingerprint\"\x0e\n" +
+	"\fGetMeRequest\"\xea\x01\n" +
+	"\x02Me\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\x12 \n" +
+	"\vpermissions\x18\x03 \x03(\tR\vpermissions\x12\x14\n" +
+	"\x05teams\x18\x04 \x03(\tR\x05teams\x12 \n" +
+	"\vfingerprint\x18\x05 \x01(\tR\vfingerprint\x12)\n" +
+	"\x10cert_fingerprint\x18\x06 \x01(\tR\x0fcertFingerprint\x12\x18\n" +
+	"\asubject\x18\a \x01(\tR\asubject\x12\x1d\n" +
+	"\n" +
+	"matched_by\x18\b \x01(\tR\tmatchedBy\"[\n" +
+	"\x15SearchFeaturesRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\x03 \x01(\tR\x06cursor\"i\n" +
+	"\x16SearchFeaturesResponse\x12.\n" +
+	"\x05items\x18\x01 \x03(\v2\x18.featureatlas.v1.FeatureR\x05items\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"D\n" +
+	"\x16SuggestFeaturesRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"I\n" +
+	"\tHighlight\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x14\n" +
+	"\x05start\x18\x02 \x01(\x05R\x05start\x12\x10\n" +
+	"\x03end\x18\x03 \x01(\x05R\x03end\"\x9e\x01\n" +
+	"\n" +
+	"Suggestion\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\asummary\x18\x03 \x01(\tR\asummary\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12:\n" +
+	"\n" +
+	"highlights\x18\x05 \x03(\v2\x1a.featureatlas.v1.HighlightR\n" +
+	"highlights\"L\n" +
+	"\x17SuggestFeaturesResponse\x121\n" +
+	"\x05items\x18\x01 \x03(\v2\x1b.featureatlas.v1.SuggestionR\x05items\"O\n" +
+	"\x11GetFeatureRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12*\n" +
+	"\x02at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02at\"\x86\x01\n" +
+	"\x14CreateFeatureRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\asummary\x18\x02 \x01(\tR\asummary\x12\x14\n" +
+	"\x05owner\x18\x03 \x01(\tR\x05owner\x12\x12\n" +
+	"\x04tags\x18\x04 \x03(\tR\x04tags\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\"\x14\n" +
+	"\x12ListClientsRequest\"D\n" +
+	"\x13ListClientsResponse\x12-\n" +
+	"\x05items\x18\x01 \x03(\v2\x17.featureatlas.v1.ClientR\x05items\"p\n" +
+	"\x15RegisterClientRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\x12\x14\n" +
+	"\x05teams\x18\x03 \x03(\tR\x05teams\x12\x19\n" +
+	"\bcert_pem\x18\x04 \x01(\tR\acertPem\"c\n" +
+	"\x16RegisterClientResponse\x12/\n" +
+	"\x06client\x18\x01 \x01(\v2\x17.featureatlas.v1.ClientR\x06client\x12\x18\n" +
+	"\asubject\x18\x02 \x01(\tR\asubject\"7\n" +
+	"\x13RevokeClientRequest\x12 \n" +
+	"\vfingerprint\x18\x01 \x01(\tR\vfingerprint2\xbe\x05\n" +
+	"\fFeatureAtlas\x12;\n" +
+	"\x05GetMe\x12\x1d.featureatlas.v1.GetMeRequest\x1a\x13.featureatlas.v1.Me\x12a\n" +
+	"\x0eSearchFeatures\x12&.featureatlas.v1.SearchFeaturesRequest\x1a'.featureatlas.v1.SearchFeaturesResponse\x12d\n" +
+	"\x0fSuggestFeatures\x12'.featureatlas.v1.SuggestFeaturesRequest\x1a(.featureatlas.v1.SuggestFeaturesResponse\x12J\n" +
+	"\n" +
+	"GetFeature\x12\".featureatlas.v1.GetFeatureRequest\x1a\x18.featureatlas.v1.Feature\x12P\n" +
+	"\rCreateFeature\x12%.featureatlas.v1.CreateFeatureRequest\x1a\x18.featureatlas.v1.Feature\x12X\n" +
+	"\vListClients\x12#.featureatlas.v1.ListClientsRequest\x1a$.featureatlas.v1.ListClientsResponse\x12a\n" +
+	"\x0eRegisterClient\x12&.featureatlas.v1.RegisterClientRequest\x1a'.featureatlas.v1.RegisterClientResponse\x12M\n" +
+	"\fRevokeClient\x12$.featureatlas.v1.RevokeClientRequest\x1a\x17.featureatlas.v1.ClientBMZKgithub.com/JoobyPM/feature-atlas-service/api/featureatlas/v1;featureatlasv1b\x06proto3"
+
+var (
+	file_api_featureatlas_v1_featureatlas_proto_rawDescOnce sync.Once
+	file_api_featureatlas_v1_featureatlas_proto_rawDescData []byte
+)
+
+func file_api_featureatlas_v1_featureatlas_proto_rawDescGZIP() []byte {
+	file_api_featureatlas_v1_featureatlas_proto_rawDescOnce.Do(func() {
+		file_api_featureatlas_v1_featureatlas_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_featureatlas_v1_featureatlas_proto_rawDesc), len(file_api_featureatlas_v1_featureatlas_proto_rawDesc)))
+	})
+	return file_api_featureatlas_v1_featureatlas_proto_rawDescData
+}
+
+var file_api_featureatlas_v1_featureatlas_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_api_featureatlas_v1_featureatlas_proto_goTypes = []any{
+	(*Feature)(nil),                 // 0: featureatlas.v1.Feature
+	(*Client)(nil),                  // 1: featureatlas.v1.Client
+	(*GetMeRequest)(nil),            // 2: featureatlas.v1.GetMeRequest
+	(*Me)(nil),                      // 3: featureatlas.v1.Me
+	(*SearchFeaturesRequest)(nil),   // 4: featureatlas.v1.SearchFeaturesRequest
+	(*SearchFeaturesResponse)(nil),  // 5: featureatlas.v1.SearchFeaturesResponse
+	(*SuggestFeaturesRequest)(nil),  // 6: featureatlas.v1.SuggestFeaturesRequest
+	(*Highlight)(nil),               // 7: featureatlas.v1.Highlight
+	(*Suggestion)(nil),              // 8: featureatlas.v1.Suggestion
+	(*SuggestFeaturesResponse)(nil), // 9: featureatlas.v1.SuggestFeaturesResponse
+	(*GetFeatureRequest)(nil),       // 10: featureatlas.v1.GetFeatureRequest
+	(*CreateFeatureRequest)(nil),    // 11: featureatlas.v1.CreateFeatureRequest
+	(*ListClientsRequest)(nil),      // 12: featureatlas.v1.ListClientsRequest
+	(*ListClientsResponse)(nil),     // 13: featureatlas.v1.ListClientsResponse
+	(*RegisterClientRequest)(nil),   // 14: featureatlas.v1.RegisterClientRequest
+	(*RegisterClientResponse)(nil),  // 15: featureatlas.v1.RegisterClientResponse
+	(*RevokeClientRequest)(nil),     // 16: featureatlas.v1.RevokeClientRequest
+	(*timestamppb.Timestamp)(nil),   // 17: google.protobuf.Timestamp
+}
+var file_api_featureatlas_v1_featureatlas_proto_depIdxs = []int32{
+	17, // 0: featureatlas.v1.Feature.created_at:type_name -> google.protobuf.Timestamp
+	17, // 1: featureatlas.v1.Feature.updated_at:type_name -> google.protobuf.Timestamp
+	17, // 2: featureatlas.v1.Client.created_at:type_name -> google.protobuf.Timestamp
+	17, // 3: featureatlas.v1.Client.revoked_at:type_name -> google.protobuf.Timestamp
+	0,  // 4: featureatlas.v1.SearchFeaturesResponse.items:type_name -> featureatlas.v1.Feature
+	7,  // 5: featureatlas.v1.Suggestion.highlights:type_name -> featureatlas.v1.Highlight
+	8,  // 6: featureatlas.v1.SuggestFeaturesResponse.items:type_name -> featureatlas.v1.Suggestion
+	17, // 7: featureatlas.v1.GetFeatureRequest.at:type_name -> google.protobuf.Timestamp
+	1,  // 8: featureatlas.v1.ListClientsResponse.items:type_name -> featureatlas.v1.Client
+	1,  // 9: featureatlas.v1.RegisterClientResponse.client:type_name -> featureatlas.v1.Client
+	2,  // 10: featureatlas.v1.FeatureAtlas.GetMe:input_type -> featureatlas.v1.GetMeRequest
+	4,  // 11: featureatlas.v1.FeatureAtlas.SearchFeatures:input_type -> featureatlas.v1.SearchFeaturesRequest
+	6,  // 12: featureatlas.v1.FeatureAtlas.SuggestFeatures:input_type -> featureatlas.v1.SuggestFeaturesRequest
+	10, // 13: featureatlas.v1.FeatureAtlas.GetFeature:input_type -> featureatlas.v1.GetFeatureRequest
+	11, // 14: featureatlas.v1.FeatureAtlas.CreateFeature:input_type -> featureatlas.v1.CreateFeatureRequest
+	12, // 15: featureatlas.v1.FeatureAtlas.ListClients:input_type -> featureatlas.v1.ListClientsRequest
+	14, // 16: featureatlas.v1.FeatureAtlas.RegisterClient:input_type -> featureatlas.v1.RegisterClientRequest
+	16, // 17: featureatlas.v1.FeatureAtlas.RevokeClient:input_type -> featureatlas.v1.RevokeClientRequest
+	3,  // 18: featureatlas.v1.FeatureAtlas.GetMe:output_type -> featureatlas.v1.Me
+	5,  // 19: featureatlas.v1.FeatureAtlas.SearchFeatures:output_type -> featureatlas.v1.SearchFeaturesResponse
+	9,  // 20: featureatlas.v1.FeatureAtlas.SuggestFeatures:output_type -> featureatlas.v1.SuggestFeaturesResponse
+	0,  // 21: featureatlas.v1.FeatureAtlas.GetFeature:output_type -> featureatlas.v1.Feature
+	0,  // 22: featureatlas.v1.FeatureAtlas.CreateFeature:output_type -> featureatlas.v1.Feature
+	13, // 23: featureatlas.v1.FeatureAtlas.ListClients:output_type -> featureatlas.v1.ListClientsResponse
+	15, // 24: featureatlas.v1.FeatureAtlas.RegisterClient:output_type -> featureatlas.v1.RegisterClientResponse
+	1,  // 25: featureatlas.v1.FeatureAtlas.RevokeClient:output_type -> featureatlas.v1.Client
+	18, // [18:26] is the sub-list for method output_type
+	10, // [10:18] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_api_featureatlas_v1_featureatlas_proto_init() }
+func file_api_featureatlas_v1_featureatlas_proto_init() {
+	if File_api_featureatlas_v1_featureatlas_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_featureatlas_v1_featureatlas_proto_rawDesc), len(file_api_featureatlas_v1_featureatlas_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_featureatlas_v1_featureatlas_proto_goTypes,
+		DependencyIndexes: file_api_featureatlas_v1_featureatlas_proto_depIdxs,
+		MessageInfos:      file_api_featureatlas_v1_featureatlas_proto_msgTypes,
+	}.Build()
+	File_api_featureatlas_v1_featureatlas_proto = out.File
+	file_api_featureatlas_v1_featureatlas_proto_goTypes = nil
+	file_api_featureatlas_v1_featureatlas_proto_depIdxs = nil
+}
@@ -0,0 +1,437 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: api/featureatlas/v1/featureatlas.proto
+
+package featureatlasv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	FeatureAtlas_GetMe_FullMethodName           = "/featureatlas.v1.FeatureAtlas/GetMe"
+	FeatureAtlas_SearchFeatures_FullMethodName  = "/featureatlas.v1.FeatureAtlas/SearchFeatures"
+	FeatureAtlas_SuggestFeatures_FullMethodName = "/featureatlas.v1.FeatureAtlas/SuggestFeatures"
+	FeatureAtlas_GetFeature_FullMethodName      = "/featureatlas.v1.FeatureAtlas/GetFeature"
+	FeatureAtlas_CreateFeature_FullMethodName   = "/featureatlas.v1.FeatureAtlas/CreateFeature"
+	FeatureAtlas_ListClients_FullMethodName     = "/featureatlas.v1.FeatureAtlas/ListClients"
+	FeatureAtlas_RegisterClient_FullMethodName  = "/featureatlas.v1.FeatureAtlas/RegisterClient"
+	FeatureAtlas_RevokeClient_FullMethodName    = "/featureatlas.v1.FeatureAtlas/RevokeClient"
+)
+
+// FeatureAtlasClient is the client API for FeatureAtlas service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// FeatureAtlas is the gRPC counterpart of the REST API. Callers authenticate
+// with a client certificate exactly as over HTTPS, and each method requires
+// the same permission as the endpoint it mirrors. Errors carry a
+// google.rpc.ErrorInfo detail (domain "feature-atlas") whose reason is the
+// REST API's error code, such as "validation_failed"; invalid requests add a
+// google.rpc.BadRequest detail listing the fields, and rate-limited calls a
+// google.rpc.RetryInfo.
+type FeatureAtlasClient interface {
+	// GetMe returns the client the certificate resolved to (GET /api/v1/me).
+	GetMe(ctx context.Context, in *GetMeRequest, opts ...grpc.CallOption) (*Me, error)
+	// SearchFeatures returns one page of features matching a query
+	// (GET /api/v1/features). Requires features:read.
+	SearchFeatures(ctx context.Context, in *SearchFeaturesRequest, opts ...grpc.CallOption) (*SearchFeaturesResponse, error)
+	// SuggestFeatures returns autocomplete suggestions (GET /api/v1/suggest).
+	// Requires features:read.
+	SuggestFeatures(ctx context.Context, in *SuggestFeaturesRequest, opts ...grpc.CallOption) (*SuggestFeaturesResponse, error)
+	// GetFeature returns a feature by ID (GET /api/v1/features/{id}).
+	// Requires features:read.
+	GetFeature(ctx context.Context, in *GetFeatureRequest, opts ...grpc.CallOption) (*Feature, error)
+	// CreateFeature adds a feature (POST /admin/v1/features). Requires
+	// features:write, or features:write:own for an owner among the caller's
+	// teams.
+	CreateFeature(ctx context.Context, in *CreateFeatureRequest, opts ...grpc.CallOption) (*Feature, error)
+	// ListClients lists the registered clients (GET /admin/v1/clients).
+	// Requires clients:manage.
+	ListClients(ctx context.Context, in *ListClientsRequest, opts ...grpc.CallOption) (*ListClientsResponse, error)
+	// RegisterClient registers a client certificate, or replaces the name,
+	// role and teams of a registered one (POST /admin/v1/clients). Requires
+	// clients:manage.
+	RegisterClient(ctx context.Context, in *RegisterClientRequest, opts ...grpc.CallOption) (*RegisterClientResponse, error)
+	// RevokeClient revokes a client certificate for good
+	// (POST /admin/v1/clients/{fingerprint}/revoke). Requires clients:manage.
+	RevokeClient(ctx context.Context, in *RevokeClientRequest, opts ...grpc.CallOption) (*Client, error)
+}
+
+type featureAtlasClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFeatureAtlasClient(cc grpc.ClientConnInterface) FeatureAtlasClient {
+	return &featureAtlasClient{cc}
+}
+
+func (c *featureAtlasClient) GetMe(ctx context.Context, in *GetMeRequest, opts ...grpc.CallOption) (*Me, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Me)
+	err := c.cc.Invoke(ctx, FeatureAtlas_GetMe_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureAtlasClient) SearchFeatures(ctx context.Context, in *SearchFeaturesRequest, opts ...grpc.CallOption) (*SearchFeaturesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchFeaturesResponse)
+	err := c.cc.Invoke(ctx, FeatureAtlas_SearchFeatures_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureAtlasClient) SuggestFeatures(ctx context.Context, in *SuggestFeaturesRequest, opts ...grpc.CallOption) (*SuggestFeaturesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SuggestFeaturesResponse)
+	err := c.cc.Invoke(ctx, FeatureAtlas_SuggestFeatures_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureAtlasClient) GetFeature(ctx context.Context, in *GetFeatureRequest, opts ...grpc.CallOption) (*Feature, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Feature)
+	err := c.cc.Invoke(ctx, FeatureAtlas_GetFeature_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureAtlasClient) CreateFeature(ctx context.Context, in *CreateFeatureRequest, opts ...grpc.CallOption) (*Feature, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Feature)
+	err := c.cc.Invoke(ctx, FeatureAtlas_CreateFeature_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureAtlasClient) ListClients(ctx context.Context, in *ListClientsRequest, opts ...grpc.CallOption) (*ListClientsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListClientsResponse)
+	err := c.cc.Invoke(ctx, FeatureAtlas_ListClients_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureAtlasClient) RegisterClient(ctx context.Context, in *RegisterClientRequest, opts ...grpc.CallOption) (*RegisterClientResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterClientResponse)
+	err := c.cc.Invoke(ctx, FeatureAtlas_RegisterClient_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureAtlasClient) RevokeClient(ctx context.Context, in *RevokeClientRequest, opts ...grpc.CallOption) (*Client, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Client)
+	err := c.cc.Invoke(ctx, FeatureAtlas_RevokeClient_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FeatureAtlasServer is the server API for FeatureAtlas service.
+// All implementations must embed UnimplementedFeatureAtlasServer
+// for forward compatibility.
+//
+// FeatureAtlas is the gRPC counterpart of the REST API. Callers authenticate
+// with a client certificate exactly as over HTTPS, and each method requires
+// the same permission as the endpoint it mirrors. Errors carry a
+// google.rpc.ErrorInfo detail (domain "feature-atlas") whose reason is the
+// REST API's error code, such as "validation_failed"; invalid requests add a
+// google.rpc.BadRequest detail listing the fields, and rate-limited calls a
+// google.rpc.RetryInfo.
+type FeatureAtlasServer interface {
+	// GetMe returns the client the certificate resolved to (GET /api/v1/me).
+	GetMe(context.Context, *GetMeRequest) (*Me, error)
+	// SearchFeatures returns one page of features matching a query
+	// (GET /api/v1/features). Requires features:read.
+	SearchFeatures(context.Context, *SearchFeaturesRequest) (*SearchFeaturesResponse, error)
+	// SuggestFeatures returns autocomplete suggestions (GET /api/v1/suggest).
+	// Requires features:read.
+	SuggestFeatures(context.Context, *SuggestFeaturesRequest) (*SuggestFeaturesResponse, error)
+	// GetFeature returns a feature by ID (GET /api/v1/features/{id}).
+	// Requires features:read.
+	GetFeature(context.Context, *GetFeatureRequest) (*Feature, error)
+	// CreateFeature adds a feature (POST /admin/v1/features). Requires
+	// features:write, or features:write:own for an owner among the caller's
+	// teams.
+	CreateFeature(context.Context, *CreateFeatureRequest) (*Feature, error)
+	// ListClients lists the registered clients (GET /admin/v1/clients).
+	// Requires clients:manage.
+	ListClients(context.Context, *ListClientsRequest) (*ListClientsResponse, error)
+	// RegisterClient registers a client certificate, or replaces the name,
+	// role and teams of a registered one (POST /admin/v1/clients). Requires
+	// clients:manage.
+	RegisterClient(context.Context, *RegisterClientRequest) (*RegisterClientResponse, error)
+	// RevokeClient revokes a client certificate for good
+	// (POST /admin/v1/clients/{fingerprint}/revoke). Requires clients:manage.
+	RevokeClient(context.Context, *RevokeClientRequest) (*Client, error)
+	mustEmbedUnimplementedFeatureAtlasServer()
+}
+
+// UnimplementedFeatureAtlasServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedFeatureAtlasServer struct{}
+
+func (UnimplementedFeatureAtlasServer) GetMe(context.Context, *GetMeRequest) (*Me, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMe not implemented")
+}
+func (UnimplementedFeatureAtlasServer) SearchFeatures(context.Context, *SearchFeaturesRequest) (*SearchFeaturesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchFeatures not implemented")
+}
+func (UnimplementedFeatureAtlasServer) SuggestFeatures(context.Context, *SuggestFeaturesRequest) (*SuggestFeaturesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SuggestFeatures not implemented")
+}
+func (UnimplementedFeatureAtlasServer) GetFeature(context.Context, *GetFeatureRequest) (*Feature, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFeature not implemented")
+}
+func (UnimplementedFeatureAtlasServer) CreateFeature(context.Context, *CreateFeatureRequest) (*Feature, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateFeature not implemented")
+}
+func (UnimplementedFeatureAtlasServer) ListClients(context.Context, *ListClientsRequest) (*ListClientsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListClients not implemented")
+}
+func (UnimplementedFeatureAtlasServer) RegisterClient(context.Context, *RegisterClientRequest) (*RegisterClientResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterClient not implemented")
+}
+func (UnimplementedFeatureAtlasServer) RevokeClient(context.Context, *RevokeClientRequest) (*Client, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeClient not implemented")
+}
+func (UnimplementedFeatureAtlasServer) mustEmbedUnimplementedFeatureAtlasServer() {}
+func (UnimplementedFeatureAtlasServer) testEmbeddedByValue()                      {}
+
+// UnsafeFeatureAtlasServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FeatureAtlasServer will
+// result in compilation errors.
+type UnsafeFeatureAtlasServer interface {
+	mustEmbedUnimplementedFeatureAtlasServer()
+}
+
+func RegisterFeatureAtlasServer(s grpc.ServiceRegistrar, srv FeatureAtlasServer) {
+	// If the following call pancis, it indicates UnimplementedFeatureAtlasServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&FeatureAtlas_ServiceDesc, srv)
+}
+
+func _FeatureAtlas_GetMe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureAtlasServer).GetMe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureAtlas_GetMe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureAtlasServer).GetMe(ctx, req.(*GetMeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FeatureAtlas_SearchFeatures_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchFeaturesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureAtlasServer).SearchFeatures(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureAtlas_SearchFeatures_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureAtlasServer).SearchFeatures(ctx, req.(*SearchFeaturesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FeatureAtlas_SuggestFeatures_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SuggestFeaturesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureAtlasServer).SuggestFeatures(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureAtlas_SuggestFeatures_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureAtlasServer).SuggestFeatures(ctx, req.(*SuggestFeaturesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FeatureAtlas_GetFeature_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFeatureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureAtlasServer).GetFeature(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureAtlas_GetFeature_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureAtlasServer).GetFeature(ctx, req.(*GetFeatureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FeatureAtlas_CreateFeature_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateFeatureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureAtlasServer).CreateFeature(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureAtlas_CreateFeature_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureAtlasServer).CreateFeature(ctx, req.(*CreateFeatureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FeatureAtlas_ListClients_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListClientsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureAtlasServer).ListClients(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureAtlas_ListClients_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureAtlasServer).ListClients(ctx, req.(*ListClientsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FeatureAtlas_RegisterClient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterClientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureAtlasServer).RegisterClient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureAtlas_RegisterClient_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureAtlasServer).RegisterClient(ctx, req.(*RegisterClientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FeatureAtlas_RevokeClient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeClientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureAtlasServer).RevokeClient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureAtlas_RevokeClient_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureAtlasServer).RevokeClient(ctx, req.(*RevokeClientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// FeatureAtlas_ServiceDesc is the grpc.ServiceDesc for FeatureAtlas service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FeatureAtlas_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "featureatlas.v1.FeatureAtlas",
+	HandlerType: (*FeatureAtlasServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetMe",
+			Handler:    _FeatureAtlas_GetMe_Handler,
+		},
+		{
+			MethodName: "SearchFeatures",
+			Handler:    _FeatureAtlas_SearchFeatures_Handler,
+		},
+		{
+			MethodName: "SuggestFeatures",
+			Handler:    _FeatureAtlas_SuggestFeatures_Handler,
+		},
+		{
+			MethodName: "GetFeature",
+			Handler:    _FeatureAtlas_GetFeature_Handler,
+		},
+		{
+			MethodName: "CreateFeature",
+			Handler:    _FeatureAtlas_CreateFeature_Handler,
+		},
+		{
+			MethodName: "ListClients",
+			Handler:    _FeatureAtlas_ListClients_Handler,
+		},
+		{
+			MethodName: "RegisterClient",
+			Handler:    _FeatureAtlas_RegisterClient_Handler,
+		},
+		{
+			MethodName: "RevokeClient",
+			Handler:    _FeatureAtlas_RevokeClient_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/featureatlas/v1/featureatlas.proto",
+}
@@ -3,21 +3,32 @@ package main
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
 	"gopkg.in/yaml.v3"
 
 	"github.com/JoobyPM/feature-atlas-service/internal/apiclient"
 	"github.com/JoobyPM/feature-atlas-service/internal/cache"
 	"github.com/JoobyPM/feature-atlas-service/internal/manifest"
 	"github.com/JoobyPM/feature-atlas-service/internal/stringutil"
+	"github.com/JoobyPM/feature-atlas-service/internal/tracing"
 	"github.com/JoobyPM/feature-atlas-service/internal/tui"
 )
 
@@ -34,13 +45,17 @@ var (
 	caFile    string
 	certFile  string
 	keyFile   string
+	traceDest string
 
 	// Search flags
 	searchLimit  int
 	searchOutput string
+	searchAll    bool
 
 	// Get flags
-	getOutput string
+	getOutput  string
+	getHistory bool
+	getAt      string
 
 	// Lint flags
 	minDescLength int
@@ -65,14 +80,44 @@ var (
 	featureOwner   string
 	featureTags    string
 
+	// Feature update/delete flags
+	featureVersion    int64
+	featureStatus     string
+	featureReplacedBy string
+
+	// Admin audit flags
+	auditActor  string
+	auditAction string
+	auditTarget string
+	auditSince  string
+	auditLimit  int
+	auditFollow bool
+	auditOutput string
+
+	// Admin clients flags
+	clientsOutput string
+
+	// Cert renew flags
+	renewTTL string
+
+	// Admin issue flags
+	issueRole    string
+	issueTeams   string
+	issueTTL     string
+	issueCertOut string
+	issueKeyOut  string
+	issueForce   bool
+
 	// Client instance (lazy initialized)
 	client *apiclient.Client
 )
 
 // Exit codes per PRD specification.
 // Commands use these semantically:
-//   - exitValidation: invalid input, missing required fields, ID format error
-//   - exitConflict: ID already exists, or external error (server/network)
+//   - exitValidation: invalid input, missing required fields, ID format error,
+//     or a request the server rejected as invalid (see apiErr)
+//   - exitConflict: ID already exists, or external error (server/network,
+//     permission denied, rate limited)
 //   - exitWrite: file system write failure, lock timeout
 const (
 	exitValidation = 1
@@ -96,12 +141,14 @@ func exitErr(code int, msg string) error {
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	endTrace(err)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
 		var exitError *ExitError
 		if errors.As(err, &exitError) {
 			os.Exit(exitError.Code)
 		}
-		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
@@ -115,6 +162,44 @@ browsing, and validating features.
 
 Local manifest commands (manifest, feature) work offline.
 Server commands (me, search, get, tui, lint) require mTLS connection.`,
+	// main prints errors, once
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Arguments and flags are valid from here on, so failures are
+		// not usage errors
+		cmd.SilenceUsage = true
+		return startTrace(cmd, args)
+	},
+}
+
+// endTrace ends the command's span and flushes it; startTrace replaces it
+// when tracing is on.
+var endTrace = func(error) {}
+
+// startTrace sets up tracing for --trace and starts a span covering the whole
+// command, so every request it makes shares one trace.
+func startTrace(cmd *cobra.Command, _ []string) error {
+	if traceDest == "" {
+		return nil
+	}
+	shutdown, err := tracing.Setup(cmd.Context(), "featctl", traceDest)
+	if err != nil {
+		return fmt.Errorf("--trace: %w", err)
+	}
+	ctx, span := otel.Tracer("github.com/JoobyPM/feature-atlas-service/cmd/featctl").Start(cmd.Context(), cmd.CommandPath())
+	cmd.SetContext(ctx)
+	endTrace = func(err error) {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to export traces: %v\n", err)
+		}
+	}
+	return nil
 }
 
 // initClient creates the API client. Called only for server commands.
@@ -127,28 +212,55 @@ func initClient() error {
 	if err != nil {
 		return fmt.Errorf("failed to create client: %w", err)
 	}
+	warnCertExpiry(client.Cert, time.Now())
 	return nil
 }
 
+// warnCertExpiry tells the user on stderr when their client certificate has
+// expired or is due for renewal, before a TLS handshake fails opaquely.
+func warnCertExpiry(cert *x509.Certificate, now time.Time) {
+	if cert == nil || !apiclient.RenewalDue(cert, now) {
+		return
+	}
+	if now.After(cert.NotAfter) {
+		fmt.Fprintf(os.Stderr, "warning: client certificate %s expired at %s; ask an admin to issue a new one\n",
+			certFile, cert.NotAfter.Local().Format(time.RFC3339))
+		return
+	}
+	fmt.Fprintf(os.Stderr, "warning: client certificate %s expires in %s (%s); run 'featctl cert renew'\n",
+		certFile, cert.NotAfter.Sub(now).Round(time.Minute), cert.NotAfter.Local().Format(time.RFC3339))
+}
+
 var meCmd = &cobra.Command{
 	Use:   "me",
 	Short: "Show authenticated client information",
 	PreRunE: func(_ *cobra.Command, _ []string) error {
 		return initClient()
 	},
-	RunE: func(_ *cobra.Command, _ []string) error {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
 		defer cancel()
 
 		info, err := client.Me(ctx)
 		if err != nil {
-			return err
+			return apiErr(err)
 		}
 
 		fmt.Printf("Name:        %s\n", info.Name)
 		fmt.Printf("Role:        %s\n", info.Role)
+		fmt.Printf("Permissions: %s\n", strings.Join(info.Permissions, ", "))
+		if len(info.Teams) > 0 {
+			fmt.Printf("Teams:       %s\n", strings.Join(info.Teams, ", "))
+		}
 		fmt.Printf("Fingerprint: %s\n", info.Fingerprint)
 		fmt.Printf("Subject:     %s\n", info.Subject)
+		if client.Cert != nil {
+			fmt.Printf("Expires:     %s\n", client.Cert.NotAfter.Local().Format(time.RFC3339))
+		}
+		if info.MatchedBy != "" && info.MatchedBy != "fingerprint" {
+			fmt.Printf("Certificate: %s\n", info.CertFingerprint)
+			fmt.Printf("Matched by:  %s\n", info.MatchedBy)
+		}
 		return nil
 	},
 }
@@ -156,22 +268,50 @@ var meCmd = &cobra.Command{
 var searchCmd = &cobra.Command{
 	Use:   "search [query]",
 	Short: "Search features in the catalog",
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Search the feature catalog. Results are ranked by relevance.
+
+Query syntax:
+  dark mode               features matching both words (prefixes match too)
+  "dark mode"             the exact phrase
+  owner:payments          a word in one field: id, name, summary, owner,
+  tag:"one click"         tag, status (values may be quoted phrases)
+  -status:deprecated      exclude matches (NOT works too)
+  a OR b, (a OR b) AND c  boolean operators (upper case) and grouping
+
+Examples:
+  featctl search 'owner:payments tag:checkout -status:deprecated "dark mode"'
+  featctl search 'status:proposed OR status:deprecated' --all`,
+	Args: cobra.MaximumNArgs(1),
 	PreRunE: func(_ *cobra.Command, _ []string) error {
 		return initClient()
 	},
-	RunE: func(_ *cobra.Command, args []string) error {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		query := ""
 		if len(args) > 0 {
 			query = args[0]
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		timeout := 10 * time.Second
+		if searchAll {
+			timeout = time.Minute // Large catalogs take several round trips
+		}
+		ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
 		defer cancel()
 
-		features, err := client.Search(ctx, query, searchLimit)
-		if err != nil {
-			return err
+		var features []apiclient.Feature
+		if searchAll {
+			for f, err := range client.SearchAll(ctx, query, searchLimit) {
+				if err != nil {
+					return apiErr(err)
+				}
+				features = append(features, f)
+			}
+		} else {
+			var err error
+			features, err = client.Search(ctx, query, searchLimit)
+			if err != nil {
+				return apiErr(err)
+			}
 		}
 
 		switch searchOutput {
@@ -215,7 +355,7 @@ Press 'n' to open a form for creating new features directly on the server.`,
 	PreRunE: func(_ *cobra.Command, _ []string) error {
 		return initClient()
 	},
-	RunE: func(_ *cobra.Command, _ []string) error {
+	RunE: func(cmd *cobra.Command, _ []string) error {
 		// Build TUI options with manifest state
 		opts, manifestLoaded, mPath, err := buildTUIOptions()
 		if err != nil {
@@ -240,7 +380,7 @@ Press 'n' to open a form for creating new features directly on the server.`,
 
 		// Sync if requested
 		if result.SyncRequested {
-			return syncAfterTUI(mPath)
+			return syncAfterTUI(cmd.Context(), mPath)
 		}
 
 		return nil
@@ -336,6 +476,7 @@ func addSelectedToManifest(selected []apiclient.SuggestItem, manifestLoaded bool
 		m.Features[item.ID] = manifest.Entry{
 			Name:     item.Name,
 			Summary:  item.Summary,
+			Status:   item.Status,
 			Synced:   true,
 			SyncedAt: time.Now().Format(time.RFC3339),
 		}
@@ -358,7 +499,7 @@ func addSelectedToManifest(selected []apiclient.SuggestItem, manifestLoaded bool
 }
 
 // syncAfterTUI syncs unsynced features after TUI selection.
-func syncAfterTUI(mPath string) error {
+func syncAfterTUI(ctx context.Context, mPath string) error {
 	// Determine manifest path
 	path := mPath
 	if path == "" {
@@ -391,22 +532,29 @@ func syncAfterTUI(mPath string) error {
 
 	fmt.Printf("\nSyncing %d feature(s) to server...\n", len(ids))
 
+	// Rejected features fail with exitValidation; refusals and outages,
+	// which retrying may fix, with exitConflict
 	var synced, failed int
+	failCode := exitValidation
 	for _, localID := range ids {
 		entry := unsynced[localID]
 
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 		feature, createErr := client.CreateFeature(ctx, apiclient.CreateFeatureRequest{
 			Name:    entry.Name,
 			Summary: entry.Summary,
 			Owner:   entry.Owner,
 			Tags:    entry.Tags,
+			Status:  entry.Status,
 		})
 		cancel()
 
 		if createErr != nil {
 			fmt.Fprintf(os.Stderr, "  ✗ %s: %v\n", localID, createErr)
 			failed++
+			if apiExitCode(createErr) != exitValidation {
+				failCode = exitConflict
+			}
 			continue
 		}
 
@@ -417,6 +565,7 @@ func syncAfterTUI(mPath string) error {
 			Summary:  feature.Summary,
 			Owner:    feature.Owner,
 			Tags:     feature.Tags,
+			Status:   feature.Status,
 			Synced:   true,
 			SyncedAt: time.Now().Format(time.RFC3339),
 			Alias:    localID,
@@ -438,7 +587,7 @@ func syncAfterTUI(mPath string) error {
 	fmt.Printf("\nSynced: %d, Failed: %d\n", synced, failed)
 
 	if failed > 0 {
-		return exitErr(exitValidation, "partial sync failure")
+		return exitErr(failCode, "partial sync failure")
 	}
 	return nil
 }
@@ -446,20 +595,49 @@ func syncAfterTUI(mPath string) error {
 var getCmd = &cobra.Command{
 	Use:   "get <feature-id>",
 	Short: "Get a feature by ID",
-	Args:  cobra.ExactArgs(1),
+	Long: `Get a feature by ID.
+
+Use --at to see the feature as it was at a point in time (e.g. when a release
+was cut), or --history to list every revision with what changed in each.
+
+Examples:
+  featctl get FT-000123
+  featctl get FT-000123 --at 2024-05-01T00:00:00Z
+  featctl get FT-000123 --history`,
+	Args: cobra.ExactArgs(1),
 	PreRunE: func(_ *cobra.Command, _ []string) error {
 		return initClient()
 	},
-	RunE: func(_ *cobra.Command, args []string) error {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if getHistory && getAt != "" {
+			fmt.Fprintln(os.Stderr, "Error: --history and --at cannot be combined")
+			return exitErr(exitValidation, "conflicting flags")
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
 		defer cancel()
 
-		feature, err := client.GetFeature(ctx, args[0])
+		if getHistory {
+			return printHistory(ctx, args[0])
+		}
+
+		var feature *apiclient.Feature
+		var err error
+		if getAt != "" {
+			at, parseErr := time.Parse(time.RFC3339, getAt)
+			if parseErr != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid --at %q (expected RFC 3339, e.g. 2024-05-01T00:00:00Z)\n", getAt)
+				return exitErr(exitValidation, "invalid --at")
+			}
+			feature, err = client.GetFeatureAt(ctx, args[0], at)
+		} else {
+			feature, err = client.GetFeature(ctx, args[0])
+		}
 		if err != nil {
 			if errors.Is(err, apiclient.ErrFeatureNotFound) {
-				return fmt.Errorf("feature not found: %s", args[0])
+				return exitErr(exitValidation, "feature not found: "+args[0])
 			}
-			return err
+			return apiErr(err)
 		}
 
 		switch getOutput {
@@ -475,11 +653,83 @@ var getCmd = &cobra.Command{
 			fmt.Printf("Summary: %s\n", feature.Summary)
 			fmt.Printf("Owner:   %s\n", feature.Owner)
 			fmt.Printf("Tags:    %s\n", strings.Join(feature.Tags, ", "))
+			fmt.Printf("Status:  %s\n", feature.Status)
+			if feature.ReplacedBy != "" {
+				fmt.Printf("Replaced by: %s\n", feature.ReplacedBy)
+			}
+			fmt.Printf("Version: %d\n", feature.Version)
 		}
 		return nil
 	},
 }
 
+// printHistory prints every revision of a feature in the --output format.
+// Text output shows, per revision, what changed from the one before.
+func printHistory(ctx context.Context, id string) error {
+	revs, err := client.FeatureHistory(ctx, id)
+	if err != nil {
+		if errors.Is(err, apiclient.ErrFeatureNotFound) {
+			return exitErr(exitValidation, "feature not found: "+id)
+		}
+		return apiErr(err)
+	}
+
+	switch getOutput {
+	case outputJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(revs)
+	case outputYAML:
+		return yaml.NewEncoder(os.Stdout).Encode(revs)
+	}
+
+	for i, rev := range revs {
+		when := rev.UpdatedAt.Local().Format(time.DateTime)
+		switch {
+		case rev.Deleted:
+			fmt.Printf("v%d  %s  deleted\n", rev.Version, when)
+			continue
+		case i == 0 || revs[i-1].Deleted:
+			fmt.Printf("v%d  %s  created\n", rev.Version, when)
+			for _, line := range featureChanges(apiclient.Feature{}, rev.Feature) {
+				fmt.Printf("    %s\n", line)
+			}
+			continue
+		}
+		changes := featureChanges(revs[i-1].Feature, rev.Feature)
+		if len(changes) == 0 {
+			changes = []string{"(no field changes)"}
+		}
+		fmt.Printf("v%d  %s\n", rev.Version, when)
+		for _, line := range changes {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+	return nil
+}
+
+// featureChanges describes the fields that differ between two revisions,
+// one "field: old → new" line each. Empty old values show only the new one.
+func featureChanges(prev, cur apiclient.Feature) []string {
+	var out []string
+	diff := func(field, old, cur string) {
+		switch {
+		case old == cur:
+		case old == "":
+			out = append(out, fmt.Sprintf("%s: %q", field, cur))
+		default:
+			out = append(out, fmt.Sprintf("%s: %q → %q", field, old, cur))
+		}
+	}
+	diff("name", prev.Name, cur.Name)
+	diff("summary", prev.Summary, cur.Summary)
+	diff("owner", prev.Owner, cur.Owner)
+	diff("tags", strings.Join(prev.Tags, ", "), strings.Join(cur.Tags, ", "))
+	diff("status", prev.Status, cur.Status)
+	diff("replaced_by", prev.ReplacedBy, cur.ReplacedBy)
+	return out
+}
+
 var lintCmd = &cobra.Command{
 	Use:   "lint <file>",
 	Short: "Validate a YAML file against the feature catalog",
@@ -487,9 +737,14 @@ var lintCmd = &cobra.Command{
 the catalog. The YAML file should have a 'feature_id' field.
 
 By default, lint checks the local manifest first, then falls back to the server.
-Use --offline to only check the local manifest (no server connection).`,
+The status of features synced to the server is read from the server, since
+the manifest's copy goes stale when someone deprecates or removes them.
+Use --offline to only check the local manifest (no server connection).
+
+References to deprecated features produce a warning; references to removed
+features fail validation.`,
 	Args: cobra.ExactArgs(1),
-	RunE: func(_ *cobra.Command, args []string) error {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		data, err := os.ReadFile(args[0])
 		if err != nil {
 			return fmt.Errorf("read file: %w", err)
@@ -503,18 +758,23 @@ Use --offline to only check the local manifest (no server connection).`,
 			return fmt.Errorf("parse YAML: %w", err)
 		}
 
-		var errs []string
+		var errs, warnings []string
 
 		// Validate feature_id
 		if doc.FeatureID == "" {
 			errs = append(errs, "missing required field: feature_id")
 		} else {
-			found, checkErr := checkFeatureExists(doc.FeatureID)
+			ref, found, checkErr := lookupFeature(cmd.Context(), doc.FeatureID)
 			if checkErr != nil {
 				return checkErr
 			}
-			if !found {
+			switch {
+			case !found:
 				errs = append(errs, fmt.Sprintf("feature_id '%s' not found in catalog", doc.FeatureID))
+			case ref.status == apiclient.StatusRemoved:
+				errs = append(errs, fmt.Sprintf("feature_id '%s' has been removed%s", doc.FeatureID, replacementHint(ref)))
+			case ref.status == apiclient.StatusDeprecated:
+				warnings = append(warnings, fmt.Sprintf("feature_id '%s' is deprecated%s", doc.FeatureID, replacementHint(ref)))
 			}
 		}
 
@@ -523,6 +783,10 @@ Use --offline to only check the local manifest (no server connection).`,
 			errs = append(errs, fmt.Sprintf("description must be at least %d characters (got %d)", minDescLength, len(doc.Description)))
 		}
 
+		for _, w := range warnings {
+			fmt.Fprintf(os.Stderr, "  ⚠ %s\n", w)
+		}
+
 		if len(errs) > 0 {
 			fmt.Fprintf(os.Stderr, "Validation failed for %s:\n", args[0])
 			for _, e := range errs {
@@ -536,50 +800,74 @@ Use --offline to only check the local manifest (no server connection).`,
 	},
 }
 
-// checkFeatureExists checks if a feature exists in manifest or server.
-// Resolution order: manifest first, then server (unless --offline).
-func checkFeatureExists(fid string) (bool, error) {
+// featureRef is the lifecycle information lint needs about a referenced feature.
+type featureRef struct {
+	status     string
+	replacedBy string
+}
+
+// replacementHint formats the successor of a retired feature for lint output.
+func replacementHint(ref featureRef) string {
+	if ref.replacedBy == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (use %s instead)", ref.replacedBy)
+}
+
+// lookupFeature finds a feature in the manifest or on the server.
+// Resolution order: manifest first, then server (unless --offline). Synced
+// manifest entries only say the feature exists; its status comes from the
+// server unless --offline. Manifest entries without a status are treated as
+// active.
+func lookupFeature(ctx context.Context, fid string) (featureRef, bool, error) {
 	// Try manifest first
 	manifestLoaded := false
 	mPath, discoverErr := manifest.Discover(lintManifest)
 	if discoverErr == nil {
 		m, loadErr := manifest.Load(mPath)
 		if loadErr == nil {
-			if m.HasFeature(fid) {
-				return true, nil
+			if entry, ok := m.Features[fid]; ok && (!entry.Synced || lintOffline) {
+				status := entry.Status
+				if status == "" {
+					status = apiclient.StatusActive
+				}
+				return featureRef{status: status, replacedBy: entry.ReplacedBy}, true, nil
 			}
 			manifestLoaded = true
 		} else if !errors.Is(loadErr, manifest.ErrInvalidYAML) {
 			// Real I/O error (permissions, etc.) - surface it
-			return false, fmt.Errorf("load manifest: %w", loadErr)
+			return featureRef{}, false, fmt.Errorf("load manifest: %w", loadErr)
 		}
 		// ErrInvalidYAML: manifest is corrupted, fall through to server check
 	} else if !errors.Is(discoverErr, manifest.ErrManifestNotFound) {
 		// Real discovery error (not just "not found") - surface it
-		return false, fmt.Errorf("discover manifest: %w", discoverErr)
+		return featureRef{}, false, fmt.Errorf("discover manifest: %w", discoverErr)
 	}
 
 	// If --offline, don't check server
 	if lintOffline {
 		if !manifestLoaded && errors.Is(discoverErr, manifest.ErrManifestNotFound) {
-			return false, exitErr(exitValidation, "manifest not found (required for --offline)")
+			return featureRef{}, false, exitErr(exitValidation, "manifest not found (required for --offline)")
 		}
-		return false, nil
+		return featureRef{}, false, nil
 	}
 
 	// Fall back to server
 	if initErr := initClient(); initErr != nil {
-		return false, fmt.Errorf("init client: %w", initErr)
+		return featureRef{}, false, fmt.Errorf("init client: %w", initErr)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	exists, serverErr := client.FeatureExists(ctx, fid)
+	feature, serverErr := client.GetFeature(ctx, fid)
+	if errors.Is(serverErr, apiclient.ErrFeatureNotFound) {
+		return featureRef{}, false, nil
+	}
 	if serverErr != nil {
-		return false, fmt.Errorf("check feature: %w", serverErr)
+		return featureRef{}, false, fmt.Errorf("check feature: %w", serverErr)
 	}
-	return exists, nil
+	return featureRef{status: feature.Status, replacedBy: feature.ReplacedBy}, true, nil
 }
 
 // manifestCmd is the parent command for manifest operations.
@@ -705,7 +993,7 @@ Requires mTLS connection to the server.`,
 	PreRunE: func(_ *cobra.Command, _ []string) error {
 		return initClient()
 	},
-	RunE: func(_ *cobra.Command, args []string) error {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		targetID := args[0]
 
 		// Validate server ID format
@@ -743,27 +1031,27 @@ Requires mTLS connection to the server.`,
 		}
 
 		// Fetch from server
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
 		defer cancel()
 
 		feature, err := client.GetFeature(ctx, targetID)
 		if err != nil {
 			if errors.Is(err, apiclient.ErrFeatureNotFound) {
-				fmt.Fprintf(os.Stderr, "Error: feature not found on server: %s\n", targetID)
-				return exitErr(exitValidation, "feature not found")
+				return exitErr(exitValidation, "feature not found on server: "+targetID)
 			}
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			return exitErr(exitConflict, "server error")
+			return apiErr(err)
 		}
 
 		// Add to manifest with synced status
 		m.Features[feature.ID] = manifest.Entry{
-			Name:     feature.Name,
-			Summary:  feature.Summary,
-			Owner:    feature.Owner,
-			Tags:     feature.Tags,
-			Synced:   true,
-			SyncedAt: time.Now().Format(time.RFC3339),
+			Name:       feature.Name,
+			Summary:    feature.Summary,
+			Owner:      feature.Owner,
+			Tags:       feature.Tags,
+			Status:     feature.Status,
+			ReplacedBy: feature.ReplacedBy,
+			Synced:     true,
+			SyncedAt:   time.Now().Format(time.RFC3339),
 		}
 
 		// Save
@@ -782,12 +1070,14 @@ var manifestSyncCmd = &cobra.Command{
 	Short: "Sync unsynced local features to the server",
 	Long: `Push all unsynced local features (FT-LOCAL-*) to the server.
 The server assigns canonical IDs (FT-NNNNNN) and the manifest is updated.
+The status and replacement of features synced earlier are refreshed from the
+server, so lint --offline sees deprecations and removals.
 
-Requires admin mTLS certificate.`,
+Requires a certificate whose role grants features:write (editor or above).`,
 	PreRunE: func(_ *cobra.Command, _ []string) error {
 		return initClient()
 	},
-	RunE: func(_ *cobra.Command, _ []string) error {
+	RunE: func(cmd *cobra.Command, _ []string) error {
 		// Find manifest
 		path, err := manifest.Discover(manifestPath)
 		if err != nil {
@@ -813,7 +1103,17 @@ Requires admin mTLS certificate.`,
 		unsynced := m.ListFeatures(true)
 		if len(unsynced) == 0 {
 			fmt.Println("No unsynced features to sync")
-			return nil
+			if manifestDryRun {
+				return nil
+			}
+			refreshed, refreshErr := refreshSyncedStatus(cmd.Context(), m)
+			if refreshed > 0 {
+				if err := m.SaveWithLock(path); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: failed to save manifest: %v\n", err)
+					return exitErr(exitWrite, "failed to save manifest")
+				}
+			}
+			return refreshErr
 		}
 
 		// Sort for deterministic order
@@ -832,24 +1132,32 @@ Requires admin mTLS certificate.`,
 			return nil
 		}
 
+		// Refresh the features synced earlier before adding new ones
+		_, refreshErr := refreshSyncedStatus(cmd.Context(), m)
+
 		fmt.Printf("Syncing %d feature(s) to server...\n", len(ids))
 
 		var synced, failed int
+		failCode := exitValidation
 		for _, localID := range ids {
 			entry := unsynced[localID]
 
-			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
 			feature, createErr := client.CreateFeature(ctx, apiclient.CreateFeatureRequest{
 				Name:    entry.Name,
 				Summary: entry.Summary,
 				Owner:   entry.Owner,
 				Tags:    entry.Tags,
+				Status:  entry.Status,
 			})
 			cancel()
 
 			if createErr != nil {
 				fmt.Fprintf(os.Stderr, "  ✗ %s: %v\n", localID, createErr)
 				failed++
+				if apiExitCode(createErr) != exitValidation {
+					failCode = exitConflict
+				}
 				continue
 			}
 
@@ -860,6 +1168,7 @@ Requires admin mTLS certificate.`,
 				Summary:  feature.Summary,
 				Owner:    feature.Owner,
 				Tags:     feature.Tags,
+				Status:   feature.Status,
 				Synced:   true,
 				SyncedAt: time.Now().Format(time.RFC3339),
 				Alias:    localID,
@@ -882,17 +1191,63 @@ Requires admin mTLS certificate.`,
 		fmt.Printf("\nSynced: %d, Failed: %d\n", synced, failed)
 
 		if failed > 0 {
-			return exitErr(exitValidation, "partial sync failure")
+			return exitErr(failCode, "partial sync failure")
 		}
-		return nil
+		return refreshErr
 	},
 }
 
+// refreshSyncedStatus updates the status and replacement of m's synced
+// features from the server, printing each change, and returns how many
+// entries changed. Features the server no longer has are reported and kept.
+func refreshSyncedStatus(ctx context.Context, m *manifest.Manifest) (int, error) {
+	ids := make([]string, 0, len(m.Features))
+	for id, entry := range m.Features {
+		if entry.Synced {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	var changed int
+	var failErr error
+	for _, id := range ids {
+		reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		feature, err := client.GetFeature(reqCtx, id)
+		cancel()
+		if errors.Is(err, apiclient.ErrFeatureNotFound) {
+			fmt.Fprintf(os.Stderr, "  ⚠ %s: no longer on the server\n", id)
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  ✗ %s: refresh status: %v\n", id, err)
+			failErr = apiErr(err)
+			continue
+		}
+
+		entry := m.Features[id]
+		if entry.Status == feature.Status && entry.ReplacedBy == feature.ReplacedBy {
+			continue
+		}
+		fmt.Printf("  ↻ %s: %s%s\n", id, feature.Status, replacementHint(featureRef{replacedBy: feature.ReplacedBy}))
+		entry.Status = feature.Status
+		entry.ReplacedBy = feature.ReplacedBy
+		entry.SyncedAt = time.Now().Format(time.RFC3339)
+		m.Features[id] = entry
+		changed++
+	}
+	return changed, failErr
+}
+
 // featureCmd is the parent command for feature operations.
 var featureCmd = &cobra.Command{
 	Use:   "feature",
 	Short: "Manage features",
-	Long:  `The feature command group manages individual features in the local manifest.`,
+	Long: `The feature command group manages individual features.
+
+'create' works on the local manifest offline. 'update' and 'delete' change
+features on the server (the certificate's role needs features:write or
+features:delete) and keep the local manifest entry in step when one exists.`,
 }
 
 var featureCreateCmd = &cobra.Command{
@@ -949,15 +1304,7 @@ Examples:
 		}
 
 		// Parse tags
-		var tags []string
-		if featureTags != "" {
-			for _, tag := range strings.Split(featureTags, ",") {
-				tag = strings.TrimSpace(tag)
-				if tag != "" {
-					tags = append(tags, tag)
-				}
-			}
-		}
+		tags := parseTags(featureTags)
 
 		// Add feature
 		if err := m.AddFeature(featureID, featureName, featureSummary, featureOwner, tags); err != nil {
@@ -984,19 +1331,685 @@ Examples:
 	},
 }
 
+var featureUpdateCmd = &cobra.Command{
+	Use:   "update <feature-id>",
+	Short: "Update a feature on the server",
+	Long: `Update fields of a server feature. Only flags that are passed are changed.
+
+The update is conditional on the feature's version: pass --version to require
+a specific version, otherwise the current version is fetched first. If the
+feature was changed by someone else in the meantime, the update is rejected.
+
+Lifecycle status moves proposed → active → deprecated → removed (a deprecated
+feature may also be reactivated). Use --replaced-by to point users of a
+deprecated or removed feature at its successor.
+
+Examples:
+  featctl feature update FT-000123 --summary "Fixed typo in summary"
+  featctl feature update FT-000123 --owner "Payments" --tags "billing,payments" --version 3
+  featctl feature update FT-000123 --status deprecated --replaced-by FT-000456`,
+	Args: cobra.ExactArgs(1),
+	PreRunE: func(_ *cobra.Command, _ []string) error {
+		return initClient()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := args[0]
+
+		var req apiclient.UpdateFeatureRequest
+		if cmd.Flags().Changed("name") {
+			req.Name = &featureName
+		}
+		if cmd.Flags().Changed("summary") {
+			req.Summary = &featureSummary
+		}
+		if cmd.Flags().Changed("owner") {
+			req.Owner = &featureOwner
+		}
+		if cmd.Flags().Changed("tags") {
+			tags := parseTags(featureTags)
+			req.Tags = &tags
+		}
+		if cmd.Flags().Changed("status") {
+			req.Status = &featureStatus
+		}
+		if cmd.Flags().Changed("replaced-by") {
+			req.ReplacedBy = &featureReplacedBy
+		}
+		if req.Name == nil && req.Summary == nil && req.Owner == nil && req.Tags == nil &&
+			req.Status == nil && req.ReplacedBy == nil {
+			fmt.Fprintln(os.Stderr, "Error: nothing to update (pass --name, --summary, --owner, --tags, --status or --replaced-by)")
+			return exitErr(exitValidation, "nothing to update")
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+		defer cancel()
+
+		version, err := resolveFeatureVersion(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		feature, err := client.UpdateFeature(ctx, id, req, version)
+		if err != nil {
+			return featureWriteErr(id, err)
+		}
+
+		refreshManifestEntry(id, feature)
+		fmt.Printf("✓ Updated %s (%s), now at version %d\n", feature.ID, feature.Name, feature.Version)
+		return nil
+	},
+}
+
+var featureDeleteCmd = &cobra.Command{
+	Use:   "delete <feature-id>",
+	Short: "Delete a feature from the server",
+	Long: `Delete a feature from the server catalog.
+
+Like update, the delete is conditional on the feature's version (--version,
+or the current version fetched just before deleting).`,
+	Args: cobra.ExactArgs(1),
+	PreRunE: func(_ *cobra.Command, _ []string) error {
+		return initClient()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := args[0]
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+		defer cancel()
+
+		version, err := resolveFeatureVersion(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := client.DeleteFeature(ctx, id, version); err != nil {
+			return featureWriteErr(id, err)
+		}
+
+		refreshManifestEntry(id, nil)
+		fmt.Printf("✓ Deleted %s\n", id)
+		return nil
+	},
+}
+
+// adminCmd is the parent command for server administration.
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Server administration (requires clients:manage or audit:read)",
+}
+
+// adminClientsCmd is the parent command for client certificate management.
+var adminClientsCmd = &cobra.Command{
+	Use:   "clients",
+	Short: "Manage registered client certificates",
+}
+
+var adminClientsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List registered clients",
+	Args:  cobra.NoArgs,
+	PreRunE: func(_ *cobra.Command, _ []string) error {
+		return initClient()
+	},
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+		defer cancel()
+
+		clients, err := client.ListClients(ctx)
+		if err != nil {
+			return apiErr(err)
+		}
+
+		switch clientsOutput {
+		case outputJSON:
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(clients)
+		case outputYAML:
+			return yaml.NewEncoder(os.Stdout).Encode(clients)
+		default:
+			for _, c := range clients {
+				var state []string
+				if c.Source != "" {
+					state = append(state, "managed")
+				}
+				if c.Revoked {
+					state = append(state, "revoked "+c.RevokedAt.Local().Format(time.DateTime))
+				}
+				fmt.Printf("%s  %-16s  %-10s  %-24s  %s\n", c.Fingerprint, c.Name, c.Role, strings.Join(c.Teams, ","), strings.Join(state, ", "))
+			}
+		}
+		return nil
+	},
+}
+
+var adminClientsSetRoleCmd = &cobra.Command{
+	Use:   "set-role <fingerprint> <role>",
+	Short: "Change a client's role",
+	Long: `Change the role of a registered client. Roles and what they grant:
+
+  user        features:read, features:write:own
+  editor      the above, plus features:write
+  maintainer  the above, plus features:delete and catalog:seed
+  admin       the above, plus clients:manage and audit:read
+
+features:write:own covers features whose owner is one of the client's teams
+(see set-teams). The change takes effect on the client's next request.`,
+	Args: cobra.ExactArgs(2),
+	PreRunE: func(_ *cobra.Command, _ []string) error {
+		return initClient()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+		defer cancel()
+
+		c, err := client.UpdateClient(ctx, args[0], apiclient.ClientUpdate{Role: &args[1]})
+		if err != nil {
+			return clientWriteErr(args[0], err)
+		}
+		fmt.Printf("✓ %s (%s) is now %s\n", c.Fingerprint, c.Name, c.Role)
+		return nil
+	},
+}
+
+var adminClientsSetTeamsCmd = &cobra.Command{
+	Use:   "set-teams <fingerprint> [team,...]",
+	Short: "Bind a client to the teams whose features it may edit",
+	Long: `Set the teams of a registered client, replacing any previous ones.
+
+With features:write:own (every role has it) a client may create features
+owned by one of its teams and update them; owners match case-insensitively.
+Omit the team list to clear the binding.
+
+Examples:
+  featctl admin clients set-teams <fingerprint> "Payments,Billing"
+  featctl admin clients set-teams <fingerprint>`,
+	Args: cobra.RangeArgs(1, 2),
+	PreRunE: func(_ *cobra.Command, _ []string) error {
+		return initClient()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+		defer cancel()
+
+		teams := []string{}
+		if len(args) == 2 {
+			teams = parseTags(args[1])
+		}
+		c, err := client.UpdateClient(ctx, args[0], apiclient.ClientUpdate{Teams: &teams})
+		if err != nil {
+			return clientWriteErr(args[0], err)
+		}
+		if len(c.Teams) == 0 {
+			fmt.Printf("✓ %s (%s) has no teams\n", c.Fingerprint, c.Name)
+		} else {
+			fmt.Printf("✓ %s (%s) teams: %s\n", c.Fingerprint, c.Name, strings.Join(c.Teams, ", "))
+		}
+		return nil
+	},
+}
+
+var adminClientsRevokeCmd = &cobra.Command{
+	Use:   "revoke <fingerprint>",
+	Short: "Revoke a client certificate",
+	Long: `Revoke a client certificate by its SHA-256 fingerprint (as shown by
+'featctl me' or 'featctl admin clients list'; colons are ignored).
+
+The revocation takes effect on the next request. The client stays listed as
+revoked and the certificate cannot be registered again; issue a new one.`,
+	Args: cobra.ExactArgs(1),
+	PreRunE: func(_ *cobra.Command, _ []string) error {
+		return initClient()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+		defer cancel()
+
+		c, err := client.RevokeClient(ctx, args[0])
+		if err != nil {
+			return clientWriteErr(args[0], err)
+		}
+		fmt.Printf("✓ Revoked %s (%s)\n", c.Fingerprint, c.Name)
+		return nil
+	},
+}
+
+var adminClientsDeleteCmd = &cobra.Command{
+	Use:   "delete <fingerprint>",
+	Short: "Delete a registered client",
+	Long: `Delete a registered client by its SHA-256 fingerprint.
+
+Unlike revoke, delete forgets the client entirely, so its certificate can be
+registered again later.`,
+	Args: cobra.ExactArgs(1),
+	PreRunE: func(_ *cobra.Command, _ []string) error {
+		return initClient()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+		defer cancel()
+
+		if err := client.DeleteClient(ctx, args[0]); err != nil {
+			return clientWriteErr(args[0], err)
+		}
+		fmt.Printf("✓ Deleted client %s\n", args[0])
+		return nil
+	},
+}
+
+// clientWriteErr reports a failed client change and maps it to an exit code.
+func clientWriteErr(fp string, err error) error {
+	if errors.Is(err, apiclient.ErrClientNotFound) {
+		return exitErr(exitValidation, "client not found: "+fp)
+	}
+	return apiErr(err)
+}
+
+var adminIssueCmd = &cobra.Command{
+	Use:   "issue <name>",
+	Short: "Issue and register a client certificate",
+	Long: `Generate a private key locally, have the server sign a certificate for it
+and register the certificate with the given role and teams. The key never
+leaves this machine. The server must be started with -ca-key.
+
+The certificate's CN is <name>. It is written to certs/<name>.crt and the key
+to certs/<name>.key (mode 0600) unless --cert-out/--key-out say otherwise;
+existing files are kept unless --force is given.
+
+Examples:
+  featctl admin issue bob
+  featctl admin issue ci-bot --role editor --teams Payments --ttl 24h`,
+	Args: cobra.ExactArgs(1),
+	PreRunE: func(_ *cobra.Command, _ []string) error {
+		return initClient()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.TrimSpace(args[0])
+		certOut, keyOut := issueCertOut, issueKeyOut
+		if certOut == "" || keyOut == "" {
+			if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+				return exitErr(exitValidation, "name is not a valid file name; use --cert-out and --key-out")
+			}
+		}
+		if certOut == "" {
+			certOut = filepath.Join("certs", name+".crt")
+		}
+		if keyOut == "" {
+			keyOut = filepath.Join("certs", name+".key")
+		}
+		if !issueForce {
+			for _, p := range []string{certOut, keyOut} {
+				if _, err := os.Stat(p); err == nil {
+					return exitErr(exitValidation, p+" already exists (use --force to overwrite)")
+				}
+			}
+		}
+
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return fmt.Errorf("generate key: %w", err)
+		}
+		csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+			Subject: pkix.Name{CommonName: name},
+		}, key)
+		if err != nil {
+			return fmt.Errorf("create CSR: %w", err)
+		}
+		keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return fmt.Errorf("encode key: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+		defer cancel()
+
+		issued, err := client.IssueCertificate(ctx, apiclient.IssueRequest{
+			Name:   name,
+			Role:   issueRole,
+			Teams:  parseTags(issueTeams),
+			TTL:    issueTTL,
+			CSRPEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})),
+		})
+		if err != nil {
+			return apiErr(err)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(keyOut), 0o750); err != nil {
+			return exitErr(exitWrite, "failed to create "+filepath.Dir(keyOut))
+		}
+		if err := os.WriteFile(keyOut, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+			return exitErr(exitWrite, "failed to write "+keyOut)
+		}
+		if err := os.MkdirAll(filepath.Dir(certOut), 0o750); err != nil {
+			return exitErr(exitWrite, "failed to create "+filepath.Dir(certOut))
+		}
+		//nolint:gosec // certificates are public
+		if err := os.WriteFile(certOut, []byte(issued.CertPEM), 0o644); err != nil {
+			return exitErr(exitWrite, "failed to write "+certOut)
+		}
+
+		fmt.Printf("✓ Issued %s (%s) for %s, valid until %s\n",
+			issued.Fingerprint, issued.Role, issued.Subject, issued.NotAfter.Local().Format(time.RFC3339))
+		fmt.Printf("  certificate: %s\n  key:         %s\n", certOut, keyOut)
+		return nil
+	},
+}
+
+// certCmd is the parent command for the client's own certificate.
+var certCmd = &cobra.Command{
+	Use:   "cert",
+	Short: "Manage your client certificate",
+}
+
+var certRenewCmd = &cobra.Command{
+	Use:   "renew",
+	Short: "Replace your client certificate with a fresh one",
+	Long: `Generate a new private key, have the server sign a certificate for it with
+the same identity as the current one, and replace the --cert and --key files.
+
+The server moves your registration to the new certificate in one step. The
+old certificate keeps working until the new one is first used, which renew
+does right after saving it, so a failed renewal can simply be retried. The
+server must be started
+with -ca-key, and the current certificate must still be valid and registered
+by fingerprint (not through an identity rule).
+
+Examples:
+  featctl cert renew
+  featctl cert renew --ttl 24h`,
+	Args: cobra.NoArgs,
+	PreRunE: func(_ *cobra.Command, _ []string) error {
+		return initClient()
+	},
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return fmt.Errorf("generate key: %w", err)
+		}
+		// The server takes the identity from the current certificate
+		csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, key)
+		if err != nil {
+			return fmt.Errorf("create CSR: %w", err)
+		}
+		keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return fmt.Errorf("encode key: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+		defer cancel()
+
+		issued, err := client.RenewCertificate(ctx,
+			string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})), renewTTL)
+		if err != nil {
+			return apiErr(err)
+		}
+
+		// Stage both files before replacing either; until the new pair is
+		// used the old one still works, so a failure here can be retried
+		keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+		keyTmp, err := writeTemp(keyFile, keyPEM, 0o600)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitErr(exitWrite, "failed to write "+keyFile)
+		}
+		certTmp, err := writeTemp(certFile, []byte(issued.CertPEM), 0o644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\nThe new key is in %s\n", err, keyTmp)
+			return exitErr(exitWrite, "failed to write "+certFile)
+		}
+		if err := os.Rename(keyTmp, keyFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\nThe new key and certificate are in %s and %s\n", err, keyTmp, certTmp)
+			return exitErr(exitWrite, "failed to replace "+keyFile)
+		}
+		if err := os.Rename(certTmp, certFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\nThe new certificate is in %s\n", err, certTmp)
+			return exitErr(exitWrite, "failed to replace "+certFile)
+		}
+
+		fmt.Printf("✓ Renewed %s: %s, valid until %s\n",
+			issued.Subject, issued.Fingerprint, issued.NotAfter.Local().Format(time.RFC3339))
+
+		// The first request with the new certificate retires the old one
+		renewed, err := apiclient.New(serverURL, caFile, certFile, keyFile)
+		if err == nil {
+			_, err = renewed.Me(ctx)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not use the new certificate yet (%v); the old one works until it is used\n", err)
+		}
+		return nil
+	},
+}
+
+// writeTemp writes data to a new temp file next to path, ready to be
+// renamed over it, and returns the temp file's name.
+func writeTemp(path string, data []byte, perm os.FileMode) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".featctl-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		_ = os.Remove(tmpPath) //nolint:errcheck // Best effort cleanup
+		return "", fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath) //nolint:errcheck // Best effort cleanup
+		return "", fmt.Errorf("close temp file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		_ = os.Remove(tmpPath) //nolint:errcheck // Best effort cleanup
+		return "", fmt.Errorf("set permissions: %w", err)
+	}
+	return tmpPath, nil
+}
+
+// auditPollInterval is how often 'admin audit --follow' polls for new entries.
+const auditPollInterval = 2 * time.Second
+
+var adminAuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show or tail the server audit log",
+	Long: `Show the most recent entries of the server audit log, oldest first.
+
+Every mutating API call is recorded with its actor, action, target and the
+target's state before and after the call.
+
+Actions: client.upsert, client.update, client.revoke, client.delete,
+certificate.issue, certificate.renew, feature.create, feature.update, feature.delete,
+catalog.seed. A prefix ending in "." (e.g. "feature.") matches a group.
+
+Examples:
+  featctl admin audit --action feature. --limit 50
+  featctl admin audit --actor alice --since 2024-05-01T00:00:00Z
+  featctl admin audit --target FT-000123 -o json
+  featctl admin audit --follow`,
+	Args: cobra.NoArgs,
+	PreRunE: func(_ *cobra.Command, _ []string) error {
+		return initClient()
+	},
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		q := apiclient.AuditQuery{
+			Actor:  auditActor,
+			Action: auditAction,
+			Target: auditTarget,
+			Limit:  auditLimit,
+		}
+		if auditSince != "" {
+			since, err := time.Parse(time.RFC3339, auditSince)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid --since %q (expected RFC 3339, e.g. 2024-05-01T00:00:00Z)\n", auditSince)
+				return exitErr(exitValidation, "invalid --since")
+			}
+			q.Since = since
+		}
+
+		ctx := cmd.Context()
+		for {
+			reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			entries, err := client.Audit(reqCtx, q)
+			cancel()
+			if err != nil {
+				return apiErr(err)
+			}
+			if err := printAudit(entries); err != nil {
+				return err
+			}
+			if !auditFollow {
+				return nil
+			}
+			if n := len(entries); n > 0 {
+				q.AfterID = entries[n-1].ID
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(auditPollInterval):
+			}
+		}
+	},
+}
+
+// printAudit writes audit entries in the --output format.
+// JSON and YAML print one document per batch so --follow can stream.
+func printAudit(entries []apiclient.AuditEntry) error {
+	switch auditOutput {
+	case outputJSON:
+		enc := json.NewEncoder(os.Stdout)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+	case outputYAML:
+		if len(entries) > 0 {
+			return yaml.NewEncoder(os.Stdout).Encode(entries)
+		}
+	default:
+		for _, e := range entries {
+			actor := e.ActorName
+			if actor == "" {
+				actor = stringutil.Truncate(e.ActorFingerprint, 16)
+			}
+			fmt.Printf("%6d  %s  %-12s  %-15s  %s\n",
+				e.ID, e.Time.Local().Format(time.DateTime), actor, e.Action, e.Target)
+		}
+	}
+	return nil
+}
+
+// resolveFeatureVersion returns --version if set, otherwise the feature's current version.
+func resolveFeatureVersion(ctx context.Context, id string) (int64, error) {
+	if featureVersion > 0 {
+		return featureVersion, nil
+	}
+	current, err := client.GetFeature(ctx, id)
+	if err != nil {
+		return 0, featureWriteErr(id, err)
+	}
+	return current.Version, nil
+}
+
+// featureWriteErr reports a failed server write and maps it to an exit code.
+func featureWriteErr(id string, err error) error {
+	switch {
+	case errors.Is(err, apiclient.ErrFeatureNotFound):
+		return exitErr(exitValidation, "feature not found on server: "+id)
+	case errors.Is(err, apiclient.ErrVersionConflict):
+		return exitErr(exitConflict, id+" was modified by someone else; re-run to apply on top of the latest version")
+	default:
+		return apiErr(err)
+	}
+}
+
+// apiErr reports a failed server request with the server's explanation.
+// Requests the server rejected as invalid (malformed or invalid input, an
+// unknown ID, a forbidden status change) exit with exitValidation; refusals
+// and failures outside the user's input (permissions, conflicts, rate
+// limits, server and network errors) with exitConflict.
+func apiErr(err error) error {
+	return exitErr(apiExitCode(err), err.Error())
+}
+
+// apiExitCode picks the exit code for a failed server request; see apiErr.
+func apiExitCode(err error) int {
+	var apiError *apiclient.APIError
+	if !errors.As(err, &apiError) {
+		return exitConflict
+	}
+	switch {
+	case apiError.StatusCode == http.StatusBadRequest, apiError.StatusCode == http.StatusNotFound,
+		errors.Is(err, apiclient.ErrInvalidTransition):
+		return exitValidation
+	default:
+		return exitConflict
+	}
+}
+
+// refreshManifestEntry mirrors a server update (or deletion, if f is nil)
+// into the local manifest, if one exists and tracks the feature.
+// Failures are reported as warnings: the server change already succeeded.
+func refreshManifestEntry(id string, f *apiclient.Feature) {
+	path, err := manifest.Discover(manifestPath)
+	if err != nil {
+		return
+	}
+	m, err := manifest.Load(path)
+	if err != nil || !m.HasFeature(id) {
+		return
+	}
+
+	if f == nil {
+		delete(m.Features, id)
+	} else {
+		entry := m.Features[id]
+		entry.Name = f.Name
+		entry.Summary = f.Summary
+		entry.Owner = f.Owner
+		entry.Tags = f.Tags
+		entry.Status = f.Status
+		entry.ReplacedBy = f.ReplacedBy
+		entry.SyncedAt = time.Now().Format(time.RFC3339)
+		m.Features[id] = entry
+	}
+
+	if err := m.SaveWithLock(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: server updated but manifest was not: %v\n", err)
+	}
+}
+
+// parseTags splits a comma-separated tag list, dropping empty entries.
+func parseTags(raw string) []string {
+	var tags []string
+	for _, tag := range strings.Split(raw, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
 func init() {
 	// Global flags for server commands
 	rootCmd.PersistentFlags().StringVar(&serverURL, "server", "https://localhost:8443", "Feature Atlas server URL")
 	rootCmd.PersistentFlags().StringVar(&caFile, "ca", "certs/ca.crt", "CA certificate file")
 	rootCmd.PersistentFlags().StringVar(&certFile, "cert", "certs/alice.crt", "Client certificate file")
 	rootCmd.PersistentFlags().StringVar(&keyFile, "key", "certs/alice.key", "Client private key file")
+	rootCmd.PersistentFlags().StringVar(&traceDest, "trace", "", "Export trace spans: otlp, otlp:<url> or file:<path>")
 
 	// Search flags
 	searchCmd.Flags().IntVarP(&searchLimit, "limit", "l", 20, "Maximum number of results")
 	searchCmd.Flags().StringVarP(&searchOutput, "output", "o", "text", "Output format (text, json, yaml)")
+	searchCmd.Flags().BoolVar(&searchAll, "all", false, "Fetch every matching feature (--limit becomes the page size)")
 
 	// Get flags
 	getCmd.Flags().StringVarP(&getOutput, "output", "o", "text", "Output format (text, json, yaml)")
+	getCmd.Flags().BoolVar(&getHistory, "history", false, "Show every revision and what changed in each")
+	getCmd.Flags().StringVar(&getAt, "at", "", "Show the feature as it was at this RFC 3339 time")
 
 	// TUI flags
 	tuiCmd.Flags().BoolVar(&tuiSync, "sync", false, "Sync added features to server immediately")
@@ -1031,12 +2044,60 @@ func init() {
 	featureCreateCmd.Flags().StringVar(&featureOwner, "owner", "", "Feature owner")
 	featureCreateCmd.Flags().StringVar(&featureTags, "tags", "", "Comma-separated tags")
 
+	// Feature update flags
+	featureUpdateCmd.Flags().StringVar(&manifestPath, "manifest", "", "Custom manifest path")
+	featureUpdateCmd.Flags().StringVar(&featureName, "name", "", "New feature name")
+	featureUpdateCmd.Flags().StringVar(&featureSummary, "summary", "", "New feature summary")
+	featureUpdateCmd.Flags().StringVar(&featureOwner, "owner", "", "New feature owner")
+	featureUpdateCmd.Flags().StringVar(&featureTags, "tags", "", "New comma-separated tags (replaces existing)")
+	featureUpdateCmd.Flags().Int64Var(&featureVersion, "version", 0, "Expected current version (default: fetch latest)")
+	featureUpdateCmd.Flags().StringVar(&featureStatus, "status", "", "New lifecycle status (proposed, active, deprecated, removed)")
+	featureUpdateCmd.Flags().StringVar(&featureReplacedBy, "replaced-by", "", "Successor feature ID for a deprecated/removed feature")
+
+	// Feature delete flags
+	featureDeleteCmd.Flags().StringVar(&manifestPath, "manifest", "", "Custom manifest path")
+	featureDeleteCmd.Flags().Int64Var(&featureVersion, "version", 0, "Expected current version (default: fetch latest)")
+
+	// Admin audit flags
+	adminAuditCmd.Flags().StringVar(&auditActor, "actor", "", "Filter by actor name or certificate fingerprint")
+	adminAuditCmd.Flags().StringVar(&auditAction, "action", "", "Filter by action (e.g. feature.update, or feature. for all feature actions)")
+	adminAuditCmd.Flags().StringVar(&auditTarget, "target", "", "Filter by target (feature ID, client fingerprint, or catalog)")
+	adminAuditCmd.Flags().StringVar(&auditSince, "since", "", "Only entries at or after this RFC 3339 time")
+	adminAuditCmd.Flags().IntVarP(&auditLimit, "limit", "l", 50, "Maximum number of entries")
+	adminAuditCmd.Flags().BoolVarP(&auditFollow, "follow", "f", false, "Keep polling for new entries")
+	adminAuditCmd.Flags().StringVarP(&auditOutput, "output", "o", "text", "Output format (text, json, yaml)")
+
+	// Admin clients flags
+	adminClientsListCmd.Flags().StringVarP(&clientsOutput, "output", "o", "text", "Output format (text, json, yaml)")
+
+	// Cert renew flags
+	certRenewCmd.Flags().StringVar(&renewTTL, "ttl", "", "Certificate lifetime, e.g. 24h (default: server maximum)")
+
+	// Admin issue flags
+	adminIssueCmd.Flags().StringVar(&issueRole, "role", "user", "Role of the new client (user, editor, maintainer, admin)")
+	adminIssueCmd.Flags().StringVar(&issueTeams, "teams", "", "Comma-separated teams of the new client")
+	adminIssueCmd.Flags().StringVar(&issueTTL, "ttl", "", "Certificate lifetime, e.g. 24h (default: server maximum)")
+	adminIssueCmd.Flags().StringVar(&issueCertOut, "cert-out", "", "Certificate output path (default: certs/<name>.crt)")
+	adminIssueCmd.Flags().StringVar(&issueKeyOut, "key-out", "", "Private key output path (default: certs/<name>.key)")
+	adminIssueCmd.Flags().BoolVar(&issueForce, "force", false, "Overwrite existing files")
+
 	// Build command tree
 	manifestCmd.AddCommand(manifestInitCmd)
 	manifestCmd.AddCommand(manifestListCmd)
 	manifestCmd.AddCommand(manifestAddCmd)
 	manifestCmd.AddCommand(manifestSyncCmd)
 	featureCmd.AddCommand(featureCreateCmd)
+	featureCmd.AddCommand(featureUpdateCmd)
+	featureCmd.AddCommand(featureDeleteCmd)
+	adminClientsCmd.AddCommand(adminClientsListCmd)
+	adminClientsCmd.AddCommand(adminClientsSetRoleCmd)
+	adminClientsCmd.AddCommand(adminClientsSetTeamsCmd)
+	adminClientsCmd.AddCommand(adminClientsRevokeCmd)
+	adminClientsCmd.AddCommand(adminClientsDeleteCmd)
+	adminCmd.AddCommand(adminAuditCmd)
+	adminCmd.AddCommand(adminClientsCmd)
+	adminCmd.AddCommand(adminIssueCmd)
+	certCmd.AddCommand(certRenewCmd)
 
 	// Add commands to root
 	rootCmd.AddCommand(meCmd)
@@ -1046,4 +2107,6 @@ func init() {
 	rootCmd.AddCommand(lintCmd)
 	rootCmd.AddCommand(manifestCmd)
 	rootCmd.AddCommand(featureCmd)
+	rootCmd.AddCommand(certCmd)
+	rootCmd.AddCommand(adminCmd)
 }
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+)
+
+// declaredClient is one entry of the clients file. The certificate is given
+// either as a path (relative to the clients file) or inline as PEM.
+type declaredClient struct {
+	Name    string   `yaml:"name"`
+	Role    string   `yaml:"role"`
+	Teams   []string `yaml:"teams"`
+	Cert    string   `yaml:"cert"`
+	CertPEM string   `yaml:"cert_pem"`
+}
+
+// loadClientsFile reads the YAML (or JSON) clients file at path:
+//
+//	clients:
+//	  - name: ops
+//	    role: admin
+//	    cert: certs/ops.crt
+//	  - name: ci-bot
+//	    role: editor
+//	    teams: [Payments]
+//	    cert_pem: |
+//	      -----BEGIN CERTIFICATE-----
+//	      ...
+func loadClientsFile(path string) ([]store.Client, error) {
+	//nolint:gosec // path is from trusted command-line flag
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read clients file: %w", err)
+	}
+	var file struct {
+		Clients []declaredClient `yaml:"clients"`
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse clients file: %w", err)
+	}
+
+	out := make([]store.Client, 0, len(file.Clients))
+	seen := make(map[string]string, len(file.Clients))
+	for i, d := range file.Clients {
+		name := strings.TrimSpace(d.Name)
+		if name == "" {
+			return nil, fmt.Errorf("client %d: name is required", i+1)
+		}
+		role := store.RoleUser
+		if d.Role != "" {
+			var ok bool
+			if role, ok = store.ParseRole(d.Role); !ok {
+				return nil, fmt.Errorf("client %q: unknown role %q", name, d.Role)
+			}
+		}
+		fp, err := declaredFingerprint(d, filepath.Dir(path))
+		if err != nil {
+			return nil, fmt.Errorf("client %q: %w", name, err)
+		}
+		if other, dup := seen[fp]; dup {
+			return nil, fmt.Errorf("client %q: same certificate as %q", name, other)
+		}
+		seen[fp] = name
+		out = append(out, store.Client{Fingerprint: fp, Name: name, Role: role, Teams: d.Teams})
+	}
+	return out, nil
+}
+
+// declaredFingerprint returns the fingerprint of d's certificate, resolving
+// a relative cert path against dir.
+func declaredFingerprint(d declaredClient, dir string) (string, error) {
+	switch {
+	case d.Cert != "" && d.CertPEM != "":
+		return "", errors.New("set cert or cert_pem, not both")
+	case d.Cert != "":
+		p := d.Cert
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		return fingerprintFromCertFile(p)
+	case d.CertPEM != "":
+		block, _ := pem.Decode([]byte(d.CertPEM))
+		if block == nil || block.Type != "CERTIFICATE" {
+			return "", errors.New("cert_pem is not a certificate PEM")
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return "", err
+		}
+		return store.FingerprintSHA256(cert), nil
+	default:
+		return "", errors.New("cert or cert_pem is required")
+	}
+}
+
+// syncClientsFile applies the clients file at path to st, logging every
+// change; the store audits them. On error nothing from this file load is applied
+// beyond the changes already committed, which a later sync completes.
+func syncClientsFile(st *store.Store, path string) error {
+	clients, err := loadClientsFile(path)
+	if err != nil {
+		return err
+	}
+	res, err := st.SyncClients(store.SourceClientsFile, clients)
+	for _, c := range res.Revoked {
+		log.Printf("warning: clients file lists revoked client %s (%s); leaving it revoked", c.Fingerprint, c.Name)
+	}
+	for _, ch := range res.Changes {
+		if ch.After != nil {
+			log.Printf("clients file: %s %s (%s)", ch.After.Fingerprint, ch.After.Name, ch.After.Role)
+		} else {
+			log.Printf("clients file: removed %s (%s)", ch.Before.Fingerprint, ch.Before.Name)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("apply clients file: %w", err)
+	}
+	return nil
+}
@@ -3,85 +3,171 @@ package main
 
 import (
 	"context"
-	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"google.golang.org/grpc"
+
+	"github.com/JoobyPM/feature-atlas-service/internal/ca"
+	"github.com/JoobyPM/feature-atlas-service/internal/grpcapi"
 	"github.com/JoobyPM/feature-atlas-service/internal/httpapi"
+	"github.com/JoobyPM/feature-atlas-service/internal/metrics"
 	"github.com/JoobyPM/feature-atlas-service/internal/store"
+	"github.com/JoobyPM/feature-atlas-service/internal/tracing"
 )
 
 func main() {
 	var (
 		listen     = flag.String("listen", ":8443", "HTTPS listen address (mTLS)")
+		grpcListen = flag.String("grpc-listen", "", "gRPC listen address, served with the same mTLS and authorization (empty = off)")
 		healthPort = flag.String("health-port", ":8080", "HTTP health check port (no auth)")
-		tlsCert    = flag.String("tls-cert", "certs/server.crt", "server cert")
-		tlsKey     = flag.String("tls-key", "certs/server.key", "server key")
-		clientCA   = flag.String("client-ca", "certs/ca.crt", "client CA (root)")
-		adminCert  = flag.String("admin-cert", "certs/admin.crt", "admin client cert (used to bootstrap admin role)")
-		seedCount  = flag.Int("seed", 200, "seed feature count")
+		tlsCert    = flag.String("tls-cert", "certs/server.crt", "server cert (reloaded on SIGHUP)")
+		tlsKey     = flag.String("tls-key", "certs/server.key", "server key (reloaded on SIGHUP)")
+		clientCA   = flag.String("client-ca", "certs/ca.crt", "client CA bundle (reloaded on SIGHUP)")
+		adminCert  = flag.String("admin-cert", "certs/admin.crt", "admin client cert (used to bootstrap admin role; empty = none)")
+		clientsArg = flag.String("clients", "", "YAML or JSON file declaring clients by certificate, name and role (reloaded on SIGHUP)")
+		seedCount  = flag.Int("seed", 200, "seed feature count (only when the catalog is empty)")
+		dataDir    = flag.String("data-dir", "", "directory for persistent storage (empty = in-memory only)")
+		crlFile    = flag.String("crl", "", "client certificate revocation list, PEM or DER (reloaded on SIGHUP)")
+		idRules    = flag.String("identity-rules", "", "YAML rules mapping certificates to clients by subject, SAN or issuing CA (reloaded on SIGHUP)")
+		caKey      = flag.String("ca-key", "", "CA private key for issuing client certificates (empty = issuance disabled)")
+		caCert     = flag.String("ca-cert", "", "CA certificate matching -ca-key (default: -client-ca)")
+		watch      = flag.Duration("watch", 0, "poll TLS, CRL, identity rule and clients files at this interval and reload on change (0 = SIGHUP only)")
+		certMaxTTL = flag.Duration("cert-max-ttl", 7*24*time.Hour, "maximum (and default) lifetime of issued client certificates")
+		traceDest  = flag.String("trace", "", "export OpenTelemetry spans: otlp, otlp:<url> or file:<path> (empty = off)")
+		accessLog  = flag.Bool("access-log", true, "log each API request as a JSON line on stderr")
+		rateLimit  = flag.String("rate-limit", "", "per-client request limits by role, e.g. user=10/s,editor=600/m:100 (empty = unlimited)")
 	)
 	flag.Parse()
 
-	st := store.New()
-	st.SeedFeatures(*seedCount)
+	shutdownTracing, err := tracing.Setup(context.Background(), "feature-atlasd", *traceDest)
+	if err != nil {
+		log.Fatalf("tracing: %v", err)
+	}
+	if *traceDest != "" {
+		log.Printf("tracing: exporting spans to %s", *traceDest)
+	}
 
-	// Bootstrap admin client from certificate file
-	adminFP, err := fingerprintFromCertFile(*adminCert)
+	// Metrics are served on the health port; storage timings are observed
+	// from the first load on
+	reg := metrics.NewRegistry()
+	st, err := openStore(*dataDir, storeObserver(reg))
 	if err != nil {
-		log.Fatalf("read admin cert: %v", err)
+		log.Fatalf("open store: %v", err)
+	}
+	if st.FeatureCount() == 0 {
+		if seedErr := st.SeedFeatures(*seedCount); seedErr != nil {
+			log.Fatalf("seed features: %v", seedErr)
+		}
+	}
+
+	// Bootstrap admin client from certificate file
+	if *adminCert != "" {
+		bootstrapAdmin(st, *adminCert)
+	}
+
+	// Declared clients; a broken file at startup is fatal, on reload it is not
+	if *clientsArg != "" {
+		if syncErr := syncClientsFile(st, *clientsArg); syncErr != nil {
+			log.Fatalf("clients file: %v", syncErr)
+		}
+		log.Printf("applied clients file %s", *clientsArg)
+	}
+	if *adminCert == "" && *clientsArg == "" {
+		log.Printf("warning: no -admin-cert or -clients given; only existing registrations can administer the service")
 	}
-	st.UpsertClient(store.Client{
-		Fingerprint: adminFP,
-		Name:        "admin",
-		Role:        store.RoleAdmin,
-		CreatedAt:   time.Now(),
-	})
-	log.Printf("bootstrapped admin client with fingerprint: %s", adminFP)
 
-	// Build TLS config for mTLS
-	caPEM, err := os.ReadFile(*clientCA)
+	// Server key pair and client CA for mTLS, replaceable at runtime
+	tlsFiles, err := httpapi.LoadTLS(*tlsCert, *tlsKey, *clientCA)
 	if err != nil {
-		log.Fatalf("read client-ca: %v", err)
+		log.Fatalf("load tls: %v", err)
 	}
-	caPool := x509.NewCertPool()
-	if !caPool.AppendCertsFromPEM(caPEM) {
-		log.Fatalf("failed to parse client-ca PEM")
+
+	var crl *httpapi.CRL
+	if *crlFile != "" {
+		crl, err = httpapi.LoadCRL(*crlFile, tlsFiles.ClientCACerts())
+		if err != nil {
+			log.Fatalf("load crl: %v", err)
+		}
+		log.Printf("loaded CRL %s: %d revoked certificate(s)", *crlFile, crl.Len())
 	}
 
-	tlsConfig := &tls.Config{
-		// ClientAuth determines server policy for TLS client auth.
-		// RequireAndVerifyClientCert requires a valid client cert signed by ClientCAs.
-		ClientAuth: tls.RequireAndVerifyClientCert,
-		// ClientCAs are roots used to verify client certs per ClientAuth policy.
-		ClientCAs:  caPool,
-		MinVersion: tls.VersionTLS12,
+	var rules *httpapi.IdentityRules
+	if *idRules != "" {
+		rules, err = httpapi.LoadIdentityRules(*idRules)
+		if err != nil {
+			log.Fatalf("load identity rules: %v", err)
+		}
+		log.Printf("loaded identity rules %s: %d rule(s)", *idRules, rules.Len())
 	}
 
-	s := &httpapi.Server{Store: st}
+	s := &httpapi.Server{Store: st, Metrics: reg}
+	if *caKey != "" {
+		signerCert := *caCert
+		if signerCert == "" {
+			signerCert = *clientCA
+		}
+		s.CA, err = ca.Load(signerCert, *caKey, *certMaxTTL)
+		if err != nil {
+			log.Fatalf("load issuing CA: %v", err)
+		}
+		log.Printf("certificate issuance enabled: %s, max ttl %s", s.CA.Certificate().Subject, *certMaxTTL)
+		caPool := x509.NewCertPool()
+		for _, c := range tlsFiles.ClientCACerts() {
+			caPool.AddCert(c)
+		}
+		if _, verifyErr := s.CA.Certificate().Verify(x509.VerifyOptions{
+			Roots:     caPool,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		}); verifyErr != nil {
+			log.Printf("warning: issuing CA does not chain to -client-ca; issued certificates will be rejected: %v", verifyErr)
+		}
+	}
+
+	// Per-client rate limits, keyed by the client MTLS resolves
+	var limiter *httpapi.RateLimiter
+	if *rateLimit != "" {
+		limits, parseErr := httpapi.ParseRateLimits(*rateLimit)
+		if parseErr != nil {
+			log.Fatalf("rate limit: %v", parseErr)
+		}
+		limiter = httpapi.NewRateLimiter(limits)
+		log.Printf("rate limiting %d role(s) per client", len(limits))
+	}
 
 	// Main API server (mTLS required)
-	apiHandler := s.Routes()
-	adminCheckedHandler := httpapi.AdminOnly(apiHandler)
-	finalHandler := httpapi.MTLS(st, adminCheckedHandler)
+	// Routes check per-route permissions against the client MTLS resolves
+	// Trace and AccessLog wrap MTLS so rejected requests get a span, an ID and a log line
+	var accessLogger *slog.Logger
+	if *accessLog {
+		accessLogger = slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	}
+	apiMetrics := httpapi.NewMetrics(reg, st)
+	finalHandler := httpapi.Trace(httpapi.AccessLog(accessLogger, httpapi.Instrument(apiMetrics,
+		httpapi.MTLS(st, crl, rules, httpapi.Throttle(limiter, s.Routes())))))
 
 	apiServer := &http.Server{
 		Addr:         *listen,
 		Handler:      finalHandler,
-		TLSConfig:    tlsConfig,
+		TLSConfig:    tlsFiles.Config(),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	// Event streams never finish by themselves; end them so Shutdown can
+	// complete, and let clients resume from another instance
+	apiServer.RegisterOnShutdown(st.CloseSubscriptions)
 
 	// Health check server (no auth, plain HTTP)
 	healthServer := &http.Server{
@@ -92,7 +178,7 @@ func main() {
 	}
 
 	// Channel to receive errors from servers
-	errChan := make(chan error, 2)
+	errChan := make(chan error, 3)
 
 	// Start health server
 	go func() {
@@ -106,37 +192,240 @@ func main() {
 	go func() {
 		log.Printf("starting feature-atlas service on https://localhost%s", *listen)
 		log.Printf("mTLS enabled: client certificates required")
-		if err := apiServer.ListenAndServeTLS(*tlsCert, *tlsKey); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		if err := apiServer.ListenAndServeTLS("", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			errChan <- fmt.Errorf("api server: %w", err)
 		}
 	}()
 
-	// Wait for interrupt signal or server error
+	// gRPC API server on its own port: same TLS material (reloaded with the
+	// REST server's), client resolution, permissions and rate limits
+	var grpcServer *grpc.Server
+	if *grpcListen != "" {
+		grpcLis, listenErr := net.Listen("tcp", *grpcListen)
+		if listenErr != nil {
+			log.Fatalf("grpc listen: %v", listenErr)
+		}
+		gs := &grpcapi.Server{
+			Store:   st,
+			CRL:     crl,
+			Rules:   rules,
+			Limiter: limiter,
+			Metrics: grpcapi.NewMetrics(reg, apiMetrics),
+			Logger:  accessLogger,
+		}
+		grpcServer = gs.GRPCServer(tlsFiles.Config())
+		go func() {
+			log.Printf("gRPC API on %s (mTLS)", grpcLis.Addr())
+			if err := grpcServer.Serve(grpcLis); err != nil {
+				errChan <- fmt.Errorf("grpc server: %w", err)
+			}
+		}()
+	}
+
+	// Wait for interrupt signal or server error; SIGHUP (or a change seen by
+	// -watch) reloads TLS material, the CRL, identity rules and clients file
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	reload := func() {
+		reloadTLS(tlsFiles, crl)
+		reloadCRL(crl)
+		reloadIdentityRules(rules)
+		reloadClients(st, *clientsArg)
+	}
+	changedChan := make(chan struct{}, 1)
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	if *watch > 0 {
+		files := []string{*tlsCert, *tlsKey, *clientCA, *crlFile, *idRules, *clientsArg}
+		go httpapi.WatchFiles(watchCtx, *watch, files, func() {
+			select {
+			case changedChan <- struct{}{}:
+			default: // a reload is already pending
+			}
+		})
+		log.Printf("watching TLS, CRL, identity rule and clients files every %s", *watch)
+	}
 
-	select {
-	case err := <-errChan:
-		log.Fatalf("server error: %v", err)
-	case sig := <-sigChan:
-		log.Printf("received signal %v, shutting down...", sig)
+wait:
+	for {
+		select {
+		case err := <-errChan:
+			log.Fatalf("server error: %v", err)
+		case <-hupChan:
+			log.Printf("received SIGHUP, reloading")
+			reload()
+		case <-changedChan:
+			log.Printf("watched files changed, reloading")
+			reload()
+		case sig := <-sigChan:
+			log.Printf("received signal %v, shutting down...", sig)
+			break wait
+		}
 	}
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// Shutdown both servers
+	// Shut down the servers
 	if err := apiServer.Shutdown(ctx); err != nil {
 		log.Printf("api server shutdown error: %v", err)
 	}
+	if grpcServer != nil {
+		stopGRPC(ctx, grpcServer)
+	}
 	if err := healthServer.Shutdown(ctx); err != nil {
 		log.Printf("health server shutdown error: %v", err)
 	}
+	if err := st.Close(); err != nil {
+		log.Printf("store close error: %v", err)
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		log.Printf("tracing shutdown error: %v", err)
+	}
 
 	log.Println("shutdown complete")
 }
 
+// stopGRPC lets in-flight calls finish, cutting them off once ctx is done.
+func stopGRPC(ctx context.Context, gs *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		gs.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Printf("grpc server shutdown error: %v", ctx.Err())
+		gs.Stop()
+	}
+}
+
+// bootstrapAdmin registers the client certificate in certFile as "admin"
+// with the admin role, unless that certificate is already registered or a
+// renewal has replaced it.
+func bootstrapAdmin(st *store.Store, certFile string) {
+	adminFP, err := fingerprintFromCertFile(certFile)
+	if err != nil {
+		log.Fatalf("read admin cert: %v", err)
+	}
+	c, ok := st.GetClient(adminFP)
+	renewed, wasRenewed := st.RenewedClient(adminFP)
+	switch {
+	case ok && c.Revoked:
+		// Restarting must not undo a revocation; configure a new admin cert
+		log.Printf("warning: admin certificate %s is revoked; not bootstrapping it", adminFP)
+	case ok && c.Source != "":
+		// The clients file owns this registration
+		log.Printf("admin certificate %s is declared in the clients file; not bootstrapping it", adminFP)
+	case ok:
+		// Already registered; keep any name or role an admin has set since
+	case wasRenewed:
+		// Registering it again would give the replaced certificate a
+		// registration of its own next to the renewed one
+		log.Printf("warning: admin certificate %s was renewed as %s (client %q); not bootstrapping it",
+			adminFP, renewed.Fingerprint, renewed.Name)
+	default:
+		if upsertErr := st.UpsertClient(store.Client{
+			Fingerprint: adminFP,
+			Name:        "admin",
+			Role:        store.RoleAdmin,
+			CreatedAt:   time.Now(),
+		}); upsertErr != nil {
+			log.Fatalf("bootstrap admin: %v", upsertErr)
+		}
+		log.Printf("bootstrapped admin client with fingerprint: %s", adminFP)
+	}
+}
+
+// reloadTLS re-reads the server key pair and client CA, keeping the old ones
+// on failure, and makes the CRL trust the reloaded CAs.
+func reloadTLS(tlsFiles *httpapi.TLSReloader, crl *httpapi.CRL) {
+	if err := tlsFiles.Reload(); err != nil {
+		log.Printf("reload tls: %v (keeping previous certificate and CA)", err)
+		return
+	}
+	crl.SetIssuers(tlsFiles.ClientCACerts())
+	log.Printf("reloaded TLS: server certificate %s (expires %s), %d client CA(s)",
+		tlsFiles.ServerCertificate().Subject, tlsFiles.ServerCertificate().NotAfter.Format(time.RFC3339),
+		len(tlsFiles.ClientCACerts()))
+}
+
+// reloadCRL re-reads the revocation list, keeping the old one on failure.
+func reloadCRL(crl *httpapi.CRL) {
+	if crl == nil {
+		return
+	}
+	if err := crl.Reload(); err != nil {
+		log.Printf("reload crl: %v (keeping previous list)", err)
+		return
+	}
+	log.Printf("reloaded CRL: %d revoked certificate(s)", crl.Len())
+}
+
+// reloadIdentityRules re-reads the identity rules, keeping the old ones on failure.
+func reloadIdentityRules(rules *httpapi.IdentityRules) {
+	if rules == nil {
+		return
+	}
+	if err := rules.Reload(); err != nil {
+		log.Printf("reload identity rules: %v (keeping previous rules)", err)
+		return
+	}
+	log.Printf("reloaded identity rules: %d rule(s)", rules.Len())
+}
+
+// reloadClients re-applies the clients file. A file that fails to parse
+// changes nothing; the service keeps its current registrations.
+func reloadClients(st *store.Store, path string) {
+	if path == "" {
+		return
+	}
+	if err := syncClientsFile(st, path); err != nil {
+		log.Printf("reload clients file: %v (keeping current registrations)", err)
+		return
+	}
+	log.Printf("reloaded clients file %s", path)
+}
+
+// openStore opens a file-backed store in dataDir, or an in-memory store if
+// dataDir is empty, reporting backend call timings to observe.
+func openStore(dataDir string, observe func(op string, d time.Duration, err error)) (*store.Store, error) {
+	if dataDir == "" {
+		log.Printf("storage: in-memory (state is lost on restart)")
+		return store.Open(&store.ObservedBackend{Backend: store.NewMemoryBackend(), Observe: observe})
+	}
+	backend, err := store.OpenFileBackend(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	st, err := store.Open(&store.ObservedBackend{Backend: backend, Observe: observe})
+	if err != nil {
+		backend.Close()
+		return nil, err
+	}
+	log.Printf("storage: file-backed in %s", dataDir)
+	return st, nil
+}
+
+// storeObserver registers the storage metrics with reg and returns the
+// function recording them.
+func storeObserver(reg *metrics.Registry) func(op string, d time.Duration, err error) {
+	duration := reg.NewHistogramVec("feature_atlas_store_operation_duration_seconds",
+		"Storage backend call latency by operation (load, compact or the appended record's op).", nil, "op")
+	failures := reg.NewCounterVec("feature_atlas_store_operation_errors_total",
+		"Failed storage backend calls by operation.", "op")
+	return func(op string, d time.Duration, err error) {
+		duration.Observe(d.Seconds(), op)
+		if err != nil {
+			failures.Inc(op)
+		}
+	}
+}
+
 // fingerprintFromCertFile reads a PEM certificate file and returns its SHA-256 fingerprint.
 func fingerprintFromCertFile(path string) (string, error) {
 	//nolint:gosec // path is from trusted command-line flag
@@ -9,10 +9,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
 	"net/http"
 	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -20,35 +22,79 @@ import (
 type Client struct {
 	BaseURL string
 	HTTP    *http.Client
+	Cert    *x509.Certificate // the client certificate, for expiry checks
 }
 
 // Feature represents a feature from the catalog.
 type Feature struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Summary   string    `json:"summary"`
-	Owner     string    `json:"owner"`
-	Tags      []string  `json:"tags"`
-	CreatedAt time.Time `json:"created_at"`
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Summary    string    `json:"summary"`
+	Owner      string    `json:"owner"`
+	Tags       []string  `json:"tags"`
+	Status     string    `json:"status"`
+	ReplacedBy string    `json:"replaced_by,omitempty"`
+	Version    int64     `json:"version"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
 }
 
+// Feature lifecycle statuses.
+const (
+	StatusProposed   = "proposed"
+	StatusActive     = "active"
+	StatusDeprecated = "deprecated"
+	StatusRemoved    = "removed"
+)
+
 // SuggestItem represents a suggestion for autocomplete.
 type SuggestItem struct {
-	ID      string `json:"id"`
-	Name    string `json:"name"`
-	Summary string `json:"summary"`
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	Summary    string      `json:"summary"`
+	Status     string      `json:"status,omitempty"`
+	Highlights []Highlight `json:"highlights,omitempty"`
+}
+
+// Highlight marks the span [Start, End) of a suggestion's ID or name that
+// matched the query. Offsets count runes, not bytes.
+type Highlight struct {
+	Field string `json:"field"` // "id" or "name"
+	Start int    `json:"start"`
+	End   int    `json:"end"`
 }
 
 // ClientInfo represents the authenticated client's information.
 type ClientInfo struct {
-	Name        string `json:"name"`
-	Role        string `json:"role"`
-	Fingerprint string `json:"fingerprint"`
-	Subject     string `json:"subject"`
+	Name        string   `json:"name"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+	Teams       []string `json:"teams"`
+	Fingerprint string   `json:"fingerprint"`
+	Subject     string   `json:"subject"`
+	// MatchedBy is "fingerprint", or the identity rule that mapped the
+	// certificate to the client; CertFingerprint is then the certificate's own.
+	MatchedBy       string `json:"matched_by"`
+	CertFingerprint string `json:"cert_fingerprint"`
 }
 
-// ErrFeatureNotFound is returned when a feature doesn't exist.
-var ErrFeatureNotFound = errors.New("feature not found")
+// RegisteredClient is a client certificate registered with the server.
+type RegisteredClient struct {
+	Fingerprint string    `json:"fingerprint"`
+	Name        string    `json:"name"`
+	Role        string    `json:"role"`
+	Teams       []string  `json:"teams,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	Revoked     bool      `json:"revoked,omitempty"`
+	RevokedAt   time.Time `json:"revoked_at,omitzero"`
+	Source      string    `json:"source,omitempty"` // "clients-file" when declared in the server's clients file
+}
+
+// PhraseQuery builds a search query matching text as an exact phrase in
+// field (e.g. "name"), so user input is never interpreted as query syntax.
+func PhraseQuery(field, text string) string {
+	return field + `:"` + strings.ReplaceAll(text, `"`, " ") + `"`
+}
 
 // New creates a new mTLS-enabled API client.
 func New(baseURL, caFile, certFile, keyFile string) (*Client, error) {
@@ -74,7 +120,7 @@ func New(baseURL, caFile, certFile, keyFile string) (*Client, error) {
 		MinVersion:   tls.VersionTLS12,
 	}
 
-	tr := &http.Transport{TLSClientConfig: tlsCfg}
+	tr := &tracingTransport{next: &requestIDTransport{next: &retryTransport{next: &http.Transport{TLSClientConfig: tlsCfg}}}}
 
 	return &Client{
 		BaseURL: baseURL,
@@ -82,9 +128,21 @@ func New(baseURL, caFile, certFile, keyFile string) (*Client, error) {
 			Transport: tr,
 			Timeout:   10 * time.Second,
 		},
+		Cert: cert.Leaf,
 	}, nil
 }
 
+// maxRenewalWindow caps how long before expiry RenewalDue starts reporting
+// true for long-lived certificates.
+const maxRenewalWindow = 7 * 24 * time.Hour
+
+// RenewalDue reports whether cert should be renewed at now: when less than
+// a fifth of its lifetime, and at most a week, remains, or it has expired.
+func RenewalDue(cert *x509.Certificate, now time.Time) bool {
+	window := min(cert.NotAfter.Sub(cert.NotBefore)/5, maxRenewalWindow)
+	return now.After(cert.NotAfter.Add(-window))
+}
+
 // Me returns the authenticated client's information.
 func (c *Client) Me(ctx context.Context) (*ClientInfo, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/api/v1/me", nil)
@@ -99,7 +157,7 @@ func (c *Client) Me(ctx context.Context) (*ClientInfo, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("me failed: %s", resp.Status)
+		return nil, apiError(resp)
 	}
 
 	var info ClientInfo
@@ -134,7 +192,7 @@ func (c *Client) Suggest(ctx context.Context, query string, limit int) ([]Sugges
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("suggest failed: %s", resp.Status)
+		return nil, apiError(resp)
 	}
 
 	var out struct {
@@ -148,8 +206,27 @@ func (c *Client) Suggest(ctx context.Context, query string, limit int) ([]Sugges
 	return out.Items, nil
 }
 
-// Search returns features matching the query.
+// SearchPage is one page of search results.
+// NextCursor is empty on the last page.
+type SearchPage struct {
+	Items      []Feature `json:"items"`
+	Count      int       `json:"count"`
+	NextCursor string    `json:"next_cursor"`
+}
+
+// Search returns features matching the query (first page only).
 func (c *Client) Search(ctx context.Context, query string, limit int) ([]Feature, error) {
+	page, err := c.SearchPage(ctx, query, limit, "")
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// SearchPage returns one page of features matching the query.
+// Pass an empty cursor for the first page and the previous page's
+// NextCursor for the following ones.
+func (c *Client) SearchPage(ctx context.Context, query string, limit int, cursor string) (*SearchPage, error) {
 	u, err := url.Parse(c.BaseURL + "/api/v1/features")
 	if err != nil {
 		return nil, fmt.Errorf("parse URL: %w", err)
@@ -158,6 +235,9 @@ func (c *Client) Search(ctx context.Context, query string, limit int) ([]Feature
 	q := u.Query()
 	q.Set("query", query)
 	q.Set("limit", strconv.Itoa(limit))
+	if cursor != "" {
+		q.Set("cursor", cursor)
+	}
 	u.RawQuery = q.Encode()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
@@ -172,18 +252,40 @@ func (c *Client) Search(ctx context.Context, query string, limit int) ([]Feature
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("search failed: %s", resp.Status)
+		return nil, apiError(resp)
 	}
 
-	var out struct {
-		Items []Feature `json:"items"`
-		Count int       `json:"count"`
-	}
+	var out SearchPage
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
 		return nil, err
 	}
 
-	return out.Items, nil
+	return &out, nil
+}
+
+// SearchAll iterates over every feature matching the query, fetching
+// pages of pageSize on demand. Iteration stops at the first error,
+// which is yielded with a zero Feature.
+func (c *Client) SearchAll(ctx context.Context, query string, pageSize int) iter.Seq2[Feature, error] {
+	return func(yield func(Feature, error) bool) {
+		cursor := ""
+		for {
+			page, err := c.SearchPage(ctx, query, pageSize, cursor)
+			if err != nil {
+				yield(Feature{}, err)
+				return
+			}
+			for _, f := range page.Items {
+				if !yield(f, nil) {
+					return
+				}
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			cursor = page.NextCursor
+		}
+	}
 }
 
 // GetFeature retrieves a feature by ID.
@@ -200,11 +302,8 @@ func (c *Client) GetFeature(ctx context.Context, id string) (*Feature, error) {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, ErrFeatureNotFound
-	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("get feature failed: %s", resp.Status)
+		return nil, apiError(resp)
 	}
 
 	var f Feature
@@ -227,15 +326,77 @@ func (c *Client) FeatureExists(ctx context.Context, id string) (bool, error) {
 	return true, nil
 }
 
+// Revision is one version of a feature. A deletion is recorded as a final
+// revision with Deleted set, carrying the feature's last state.
+type Revision struct {
+	Feature
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// GetFeatureAt retrieves a feature as it was at the given time.
+// Returns ErrFeatureNotFound if the feature did not exist then.
+func (c *Client) GetFeatureAt(ctx context.Context, id string, at time.Time) (*Feature, error) {
+	u := c.BaseURL + "/api/v1/features/" + url.PathEscape(id) + "?at=" + url.QueryEscape(at.UTC().Format(time.RFC3339))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var f Feature
+	if err := json.NewDecoder(resp.Body).Decode(&f); err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+// FeatureHistory returns every revision of a feature, oldest first.
+// Returns ErrFeatureNotFound if the ID was never used.
+func (c *Client) FeatureHistory(ctx context.Context, id string) ([]Revision, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/api/v1/features/"+url.PathEscape(id)+"/history", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var out struct {
+		Items []Revision `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out.Items, nil
+}
+
 // CreateFeatureRequest is the request body for creating a feature.
 type CreateFeatureRequest struct {
 	Name    string   `json:"name"`
 	Summary string   `json:"summary"`
 	Owner   string   `json:"owner,omitempty"`
 	Tags    []string `json:"tags,omitempty"`
+	Status  string   `json:"status,omitempty"` // proposed or active (default)
 }
 
-// CreateFeature creates a new feature on the server (admin only).
+// CreateFeature creates a new feature on the server (requires features:write).
 // Returns the created feature with the server-assigned ID.
 func (c *Client) CreateFeature(ctx context.Context, req CreateFeatureRequest) (*Feature, error) {
 	body, err := json.Marshal(req)
@@ -263,11 +424,366 @@ func (c *Client) CreateFeature(ctx context.Context, req CreateFeatureRequest) (*
 			return nil, decodeErr
 		}
 		return &f, nil
-	case http.StatusForbidden:
-		return nil, errors.New("admin role required")
-	case http.StatusBadRequest:
-		return nil, errors.New("invalid request: name and summary required")
 	default:
-		return nil, fmt.Errorf("create feature failed: %s", resp.Status)
+		return nil, apiError(resp)
+	}
+}
+
+// UpdateFeatureRequest is the request body for a partial feature update.
+// Nil fields are left unchanged on the server.
+type UpdateFeatureRequest struct {
+	Name       *string   `json:"name,omitempty"`
+	Summary    *string   `json:"summary,omitempty"`
+	Owner      *string   `json:"owner,omitempty"`
+	Tags       *[]string `json:"tags,omitempty"`
+	Status     *string   `json:"status,omitempty"`
+	ReplacedBy *string   `json:"replaced_by,omitempty"`
+}
+
+// UpdateFeature applies a partial update to a feature (requires features:write).
+// The update only succeeds if the feature is still at version; otherwise
+// ErrVersionConflict is returned. Version 0 overwrites any version.
+func (c *Client) UpdateFeature(ctx context.Context, id string, req UpdateFeatureRequest, version int64) (*Feature, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPatch, c.BaseURL+"/admin/v1/features/"+url.PathEscape(id), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	setIfMatch(httpReq, version)
+
+	resp, err := c.HTTP.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var f Feature
+		if decodeErr := json.NewDecoder(resp.Body).Decode(&f); decodeErr != nil {
+			return nil, decodeErr
+		}
+		return &f, nil
+	default:
+		return nil, apiError(resp)
+	}
+}
+
+// DeleteFeature deletes a feature (requires features:delete).
+// The delete only succeeds if the feature is still at version; otherwise
+// ErrVersionConflict is returned. Version 0 deletes any version.
+func (c *Client) DeleteFeature(ctx context.Context, id string, version int64) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.BaseURL+"/admin/v1/features/"+url.PathEscape(id), nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	setIfMatch(httpReq, version)
+
+	resp, err := c.HTTP.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusOK:
+		return nil
+	default:
+		return apiError(resp)
+	}
+}
+
+// AuditEntry is one entry of the server's audit log.
+// Before and After hold the target's JSON state around the call.
+type AuditEntry struct {
+	ID               int64           `json:"id"`
+	Time             time.Time       `json:"time"`
+	ActorFingerprint string          `json:"actor_fingerprint"`
+	ActorName        string          `json:"actor_name"`
+	Action           string          `json:"action"`
+	Target           string          `json:"target"`
+	Before           json.RawMessage `json:"before,omitempty"`
+	After            json.RawMessage `json:"after,omitempty"`
+	RequestID        string          `json:"request_id,omitempty"`
+}
+
+// AuditQuery filters the audit log. Zero fields match everything.
+type AuditQuery struct {
+	Actor   string    // Actor fingerprint or name
+	Action  string    // Exact action, or a prefix ending in "." (e.g. "feature.")
+	Target  string    // Exact target
+	Since   time.Time // Entries at or after this time
+	AfterID int64     // Only entries after this ID, for following the log
+	Limit   int       // Maximum number of entries (server default if zero)
+}
+
+// Audit returns audit log entries matching q, oldest first (requires audit:read).
+// Without AfterID the most recent matches are returned.
+func (c *Client) Audit(ctx context.Context, q AuditQuery) ([]AuditEntry, error) {
+	u, err := url.Parse(c.BaseURL + "/admin/v1/audit")
+	if err != nil {
+		return nil, fmt.Errorf("parse URL: %w", err)
+	}
+
+	v := u.Query()
+	if q.Actor != "" {
+		v.Set("actor", q.Actor)
+	}
+	if q.Action != "" {
+		v.Set("action", q.Action)
+	}
+	if q.Target != "" {
+		v.Set("target", q.Target)
+	}
+	if !q.Since.IsZero() {
+		v.Set("since", q.Since.UTC().Format(time.RFC3339))
+	}
+	if q.AfterID > 0 {
+		v.Set("after", strconv.FormatInt(q.AfterID, 10))
+	}
+	if q.Limit > 0 {
+		v.Set("limit", strconv.Itoa(q.Limit))
+	}
+	u.RawQuery = v.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var out struct {
+		Items []AuditEntry `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out.Items, nil
+}
+
+// ListClients returns all registered clients (requires clients:manage).
+func (c *Client) ListClients(ctx context.Context) ([]RegisteredClient, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/admin/v1/clients", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var out struct {
+		Items []RegisteredClient `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out.Items, nil
+}
+
+// RevokeClient revokes the client certificate with the given fingerprint
+// (requires clients:manage). The client stays listed but can no longer authenticate,
+// and the certificate cannot be registered again.
+func (c *Client) RevokeClient(ctx context.Context, fingerprint string) (*RegisteredClient, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		c.BaseURL+"/admin/v1/clients/"+url.PathEscape(fingerprint)+"/revoke", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var out RegisteredClient
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteClient removes the client with the given fingerprint
+// (requires clients:manage).
+func (c *Client) DeleteClient(ctx context.Context, fingerprint string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete,
+		c.BaseURL+"/admin/v1/clients/"+url.PathEscape(fingerprint), nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusOK:
+		return nil
+	default:
+		return apiError(resp)
+	}
+}
+
+// ClientUpdate is the request body for changing a client. Nil fields are
+// left unchanged. Roles: user, editor, maintainer, admin. Teams are the
+// feature owners the client may edit without features:write.
+type ClientUpdate struct {
+	Role  *string   `json:"role,omitempty"`
+	Teams *[]string `json:"teams,omitempty"`
+}
+
+// UpdateClient changes the role or teams of the client with the given
+// fingerprint (requires clients:manage).
+func (c *Client) UpdateClient(ctx context.Context, fingerprint string, upd ClientUpdate) (*RegisteredClient, error) {
+	body, err := json.Marshal(upd)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch,
+		c.BaseURL+"/admin/v1/clients/"+url.PathEscape(fingerprint), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var out RegisteredClient
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// IssueRequest asks the server to sign a client certificate. CSRPEM is a
+// PEM certificate signing request for the client's key; the certificate's
+// CN is Name. TTL is a Go duration ("24h"); empty means the server maximum.
+type IssueRequest struct {
+	Name   string   `json:"name"`
+	Role   string   `json:"role,omitempty"`
+	Teams  []string `json:"teams,omitempty"`
+	TTL    string   `json:"ttl,omitempty"`
+	CSRPEM string   `json:"csr_pem"`
+}
+
+// IssuedCertificate is a signed and registered client certificate.
+type IssuedCertificate struct {
+	Fingerprint string    `json:"fingerprint"`
+	Name        string    `json:"name"`
+	Role        string    `json:"role"`
+	Teams       []string  `json:"teams"`
+	Subject     string    `json:"subject"`
+	Serial      string    `json:"serial"`
+	NotAfter    time.Time `json:"not_after"`
+	CertPEM     string    `json:"cert_pem"`
+	CAPEM       string    `json:"ca_pem"`
+}
+
+// IssueCertificate has the server sign a CSR and register the resulting
+// certificate (requires clients:manage).
+func (c *Client) IssueCertificate(ctx context.Context, issue IssueRequest) (*IssuedCertificate, error) {
+	body, err := json.Marshal(issue)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		c.BaseURL+"/admin/v1/certificates", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, apiError(resp)
+	}
+
+	var out IssuedCertificate
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RenewCertificate replaces the client's own certificate: the server signs
+// csrPEM (for a new key) with the current certificate's identity and moves
+// the registration to it, so the current certificate stops working. ttl is
+// a Go duration; empty means the server maximum.
+func (c *Client) RenewCertificate(ctx context.Context, csrPEM, ttl string) (*IssuedCertificate, error) {
+	body, err := json.Marshal(map[string]string{"csr_pem": csrPEM, "ttl": ttl})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		c.BaseURL+"/api/v1/me/certificate", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, apiError(resp)
+	}
+
+	var out IssuedCertificate
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// setIfMatch makes a request conditional on the given feature version, or
+// explicitly unconditional for version 0.
+func setIfMatch(req *http.Request, version int64) {
+	if version > 0 {
+		req.Header.Set("If-Match", `"`+strconv.FormatInt(version, 10)+`"`)
+		return
 	}
+	req.Header.Set("If-Match", "*")
 }
@@ -0,0 +1,163 @@
+package apiclient
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Errors an APIError matches with errors.Is, by its code.
+var (
+	// ErrNotFound is returned for a path the server does not serve, which
+	// usually means client and server versions differ.
+	ErrNotFound = errors.New("not found")
+
+	// ErrMethodNotAllowed is returned when the path does not accept the
+	// request's method.
+	ErrMethodNotAllowed = errors.New("method not allowed")
+
+	// ErrFeatureNotFound is returned when a feature doesn't exist.
+	ErrFeatureNotFound = errors.New("feature not found")
+
+	// ErrClientNotFound is returned when no client has the given fingerprint.
+	ErrClientNotFound = errors.New("client not found")
+
+	// ErrValidation is returned when the server rejects the request body or
+	// parameters; the APIError's Fields say which.
+	ErrValidation = errors.New("invalid request")
+
+	// ErrInvalidQuery is returned when the server rejects a search query.
+	ErrInvalidQuery = errors.New("invalid query")
+
+	// ErrVersionConflict is returned when a conditional write targets a stale version.
+	ErrVersionConflict = errors.New("feature was modified concurrently (version conflict)")
+
+	// ErrPreconditionRequired is returned when a feature write names no
+	// version to write against.
+	ErrPreconditionRequired = errors.New("feature version required")
+
+	// ErrInvalidTransition is returned when a status change is not allowed by the lifecycle.
+	ErrInvalidTransition = errors.New("invalid status transition")
+
+	// ErrUnauthorized is returned when the server does not accept the
+	// client certificate: it is unregistered, revoked or on the CRL.
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrPermissionDenied is returned when the client's role lacks the
+	// permission a request needs.
+	ErrPermissionDenied = errors.New("permission denied")
+
+	// ErrManagedClient is returned when changing a client declared in the
+	// server's clients file; only revoking it is allowed.
+	ErrManagedClient = errors.New("client is managed by the clients file")
+
+	// ErrCertificateRevoked is returned when registering a certificate
+	// that was revoked; revocation is permanent, so issue a new one.
+	ErrCertificateRevoked = errors.New("certificate revoked")
+
+	// ErrSelfChange is returned when a client tries to change, revoke or
+	// delete its own registration.
+	ErrSelfChange = errors.New("cannot change your own client")
+
+	// ErrRuleMapped is returned when renewing a certificate that an
+	// identity rule maps to a client rather than a registration.
+	ErrRuleMapped = errors.New("certificate is mapped by an identity rule")
+
+	// ErrClientNotRegistered is returned when the client was deleted or
+	// revoked while the request was in flight.
+	ErrClientNotRegistered = errors.New("client is no longer registered")
+
+	// ErrRateLimited is returned when the server still refuses the request
+	// for exceeding the client's rate limit after the transport's retries.
+	ErrRateLimited = errors.New("rate limit exceeded")
+
+	// ErrIssuanceDisabled is returned when the server holds no CA key.
+	ErrIssuanceDisabled = errors.New("certificate issuance is not enabled on the server")
+
+	// ErrInternal is returned when the server failed to handle the request.
+	ErrInternal = errors.New("internal server error")
+)
+
+// codeErrors maps problem codes to the errors they match.
+var codeErrors = map[string]error{
+	"invalid_body":          ErrValidation,
+	"validation_failed":     ErrValidation,
+	"invalid_query":         ErrInvalidQuery,
+	"unauthorized":          ErrUnauthorized,
+	"forbidden":             ErrPermissionDenied,
+	"not_found":             ErrNotFound,
+	"feature_not_found":     ErrFeatureNotFound,
+	"client_not_found":      ErrClientNotFound,
+	"method_not_allowed":    ErrMethodNotAllowed,
+	"version_conflict":      ErrVersionConflict,
+	"precondition_required": ErrPreconditionRequired,
+	"invalid_transition":    ErrInvalidTransition,
+	"client_managed":        ErrManagedClient,
+	"certificate_revoked":   ErrCertificateRevoked,
+	"self_change":           ErrSelfChange,
+	"rule_mapped":           ErrRuleMapped,
+	"client_not_registered": ErrClientNotRegistered,
+	"rate_limited":          ErrRateLimited,
+	"issuance_disabled":     ErrIssuanceDisabled,
+	"internal":              ErrInternal,
+}
+
+// APIError is an error response from the server. Compare it with the Err
+// variables using errors.Is; use errors.As for the status, code and the
+// fields that failed validation.
+type APIError struct {
+	StatusCode int
+	Code       string       // machine-readable, e.g. "validation_failed"; empty if the body was not a problem
+	Message    string       // human-readable explanation
+	Fields     []FieldError // invalid request fields, for validation_failed
+	RequestID  string       // quote it when asking the server's operators
+}
+
+// FieldError explains why the server rejected one request field, parameter or header.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error returns the server's message followed by the request ID.
+func (e *APIError) Error() string {
+	if e.RequestID == "" {
+		return e.Message
+	}
+	return e.Message + " (request " + e.RequestID + ")"
+}
+
+// Is reports whether target is the Err variable for e's code.
+func (e *APIError) Is(target error) bool {
+	err, ok := codeErrors[e.Code]
+	return ok && err == target
+}
+
+// apiError reads an error response. Bodies other than problem details, such
+// as a proxy's error page, keep their text, or the status, as the message.
+func apiError(resp *http.Response) error {
+	e := &APIError{StatusCode: resp.StatusCode, RequestID: resp.Header.Get(RequestIDHeader)}
+	if e.RequestID == "" && resp.Request != nil {
+		e.RequestID = resp.Request.Header.Get(RequestIDHeader)
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var p struct {
+		Code   string       `json:"code"`
+		Detail string       `json:"detail"`
+		Errors []FieldError `json:"errors"`
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "application/problem+json" && json.Unmarshal(data, &p) == nil && p.Code != "" {
+		e.Code, e.Message, e.Fields = p.Code, p.Detail, p.Errors
+	} else {
+		e.Message = strings.TrimSpace(string(data[:min(len(data), 4096)]))
+	}
+	if e.Message == "" {
+		e.Message = resp.Status
+	}
+	return e
+}
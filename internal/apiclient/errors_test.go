@@ -0,0 +1,70 @@
+package apiclient
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestCodeErrors checks that every problem code the server documents in
+// its OpenAPI document matches an Err variable, and no other code does.
+func TestCodeErrors(t *testing.T) {
+	data, err := os.ReadFile("../httpapi/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Components struct {
+			Schemas struct {
+				Problem struct {
+					Properties struct {
+						Code struct {
+							Enum []string `json:"enum"`
+						} `json:"code"`
+					} `json:"properties"`
+				} `json:"Problem"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("decode openapi.json: %v", err)
+	}
+	codes := doc.Components.Schemas.Problem.Properties.Code.Enum
+	if len(codes) == 0 {
+		t.Fatal("openapi.json lists no problem codes")
+	}
+	for _, code := range codes {
+		if codeErrors[code] == nil {
+			t.Errorf("server code %q matches no Err variable", code)
+		}
+	}
+	for code := range codeErrors {
+		if !slices.Contains(codes, code) {
+			t.Errorf("code %q is not one the server sends", code)
+		}
+	}
+}
+
+func TestAPIErrorIs(t *testing.T) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/problem+json")
+	header.Set(RequestIDHeader, "abc")
+	resp := &http.Response{
+		StatusCode: http.StatusConflict,
+		Status:     "409 Conflict",
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(`{"code":"self_change","detail":"cannot change, revoke or delete your own certificate"}`)),
+	}
+	err := apiError(resp)
+	if !errors.Is(err, ErrSelfChange) || errors.Is(err, ErrManagedClient) {
+		t.Errorf("errors.Is(%v) matches the wrong sentinel", err)
+	}
+	if want := "cannot change, revoke or delete your own certificate (request abc)"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
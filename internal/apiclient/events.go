@@ -0,0 +1,195 @@
+package apiclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event types streamed by Events.
+const (
+	EventFeatureCreated  = "feature.created"
+	EventFeatureUpdated  = "feature.updated"
+	EventFeatureDeleted  = "feature.deleted"
+	EventCatalogReseeded = "catalog.reseeded"
+	// EventResync means events were missed and the server no longer has
+	// them: reload whatever was derived from the catalog.
+	EventResync = "resync"
+)
+
+// Event is a change to the catalog.
+type Event struct {
+	ID      string   // pass to Events to resume after this event
+	Type    string   // one of the Event* constants
+	Feature *Feature // for feature events; the last state of a deleted feature
+	Count   int      // number of features, for EventCatalogReseeded
+}
+
+// Event stream timing.
+const (
+	// defaultEventRetry is the reconnection delay until the server suggests one.
+	defaultEventRetry = 3 * time.Second
+	// maxEventRetry caps the delay after repeated failures to connect.
+	maxEventRetry = time.Minute
+	// eventIdleTimeout is how long a stream may stay silent before it is
+	// considered dead; the server sends a comment at least every 25s.
+	eventIdleTimeout = time.Minute
+)
+
+// Events streams catalog changes after the event with ID lastEventID, or
+// from now on if it is empty. When the stream drops, it reconnects and
+// resumes after the last event received, so none are lost; if the server no
+// longer has them, an EventResync event comes first. Failures to connect,
+// and events that cannot be decoded, are yielded as errors; connecting is
+// retried with a growing delay until the loop stops or ctx ends, except
+// after a client error response such as ErrPermissionDenied, which ends the
+// sequence.
+func (c *Client) Events(ctx context.Context, lastEventID string) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		es := &eventStream{lastID: lastEventID, retry: defaultEventRetry}
+		for failures := 0; ; {
+			connected, err := c.streamEvents(ctx, es, yield)
+			switch {
+			case ctx.Err() != nil || es.stopped:
+				return
+			case err != nil:
+				if !yield(Event{}, err) || permanent(err) {
+					return
+				}
+				failures++
+			case connected:
+				failures = 0
+			}
+
+			timer := time.NewTimer(min(es.retry<<min(failures, 5), maxEventRetry))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// permanent reports whether err is a response that reconnecting will not
+// change: a client error other than rate limiting.
+func permanent(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode < http.StatusInternalServerError &&
+		apiErr.StatusCode != http.StatusTooManyRequests
+}
+
+// eventStream is the state Events carries across connections.
+type eventStream struct {
+	lastID  string        // ID of the last event received
+	retry   time.Duration // reconnection delay the server asked for
+	stopped bool          // yield returned false
+}
+
+// streamEvents connects once and yields events until the stream ends. It
+// returns an error only if it could not connect, and whether it did.
+func (c *Client) streamEvents(ctx context.Context, es *eventStream, yield func(Event, error) bool) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/api/v1/events", nil)
+	if err != nil {
+		return false, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if es.lastID != "" {
+		req.Header.Set("Last-Event-ID", es.lastID)
+	}
+
+	// The stream has no end, so the client's timeout must not apply; the
+	// idle timer below notices a dead connection instead
+	hc := *c.HTTP
+	hc.Timeout = 0
+	resp, err := hc.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, apiError(resp)
+	}
+
+	idle := time.AfterFunc(eventIdleTimeout, cancel)
+	defer idle.Stop()
+
+	r := bufio.NewReader(resp.Body)
+	var (
+		typ, data string
+		hasData   bool
+	)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return true, nil // dropped; the caller reconnects
+		}
+		idle.Reset(eventIdleTimeout)
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			// A blank line dispatches the event; one without data only
+			// moves the last event ID
+			if hasData {
+				ev, decodeErr := decodeEvent(es.lastID, typ, data)
+				if !yield(ev, decodeErr) {
+					es.stopped = true
+					return true, nil
+				}
+			}
+			typ, data, hasData = "", "", false
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			es.lastID = value
+		case "event":
+			typ = value
+		case "data":
+			if hasData {
+				data += "\n"
+			}
+			data, hasData = data+value, true
+		case "retry":
+			if ms, convErr := strconv.Atoi(value); convErr == nil && ms > 0 {
+				es.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+		// Lines starting with a colon are comments, such as heartbeats
+	}
+}
+
+// decodeEvent decodes the data of an event of type typ.
+func decodeEvent(id, typ, data string) (Event, error) {
+	ev := Event{ID: id, Type: typ}
+	var err error
+	switch typ {
+	case EventFeatureCreated, EventFeatureUpdated, EventFeatureDeleted:
+		ev.Feature = &Feature{}
+		err = json.Unmarshal([]byte(data), ev.Feature)
+	case EventCatalogReseeded:
+		var payload struct {
+			Count int `json:"count"`
+		}
+		err = json.Unmarshal([]byte(data), &payload)
+		ev.Count = payload.Count
+	}
+	if err != nil {
+		return Event{}, fmt.Errorf("decode %s event %s: %w", typ, id, err)
+	}
+	return ev, nil
+}
@@ -0,0 +1,28 @@
+package apiclient
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader names the header correlating a request with the server's
+// access log and audit entries.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDTransport gives each request without an X-Request-ID a fresh one,
+// so every attempt of a retried request carries the same ID.
+type requestIDTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get(RequestIDHeader) == "" {
+		var b [12]byte
+		_, _ = rand.Read(b[:]) // never fails; see crypto/rand
+		req = req.Clone(req.Context())
+		req.Header.Set(RequestIDHeader, hex.EncodeToString(b[:]))
+	}
+	return t.next.RoundTrip(req)
+}
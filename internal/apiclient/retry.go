@@ -0,0 +1,107 @@
+package apiclient
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Retry policy for responses asking the client to slow down.
+const (
+	maxRetries     = 3
+	baseRetryDelay = 250 * time.Millisecond
+	// maxRetryWait is the longest single wait; a server asking for more gets
+	// its 429 or 503 passed back to the caller instead
+	maxRetryWait = 5 * time.Second
+)
+
+// retryTransport retries requests answered with 429 Too Many Requests, and
+// GET, HEAD and OPTIONS requests answered with 503 Service Unavailable. It
+// waits as long as the Retry-After header says,
+// or backs off exponentially with jitter without one, and stops early when
+// the request's context ends.
+type retryTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if err != nil || attempt == maxRetries || !retryable(req.Method, resp.StatusCode) {
+			return resp, err
+		}
+		// A body can only be sent again if the request knows how to rewind it
+		if req.Body != nil && req.GetBody == nil {
+			return resp, nil
+		}
+		wait, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !ok {
+			wait = backoff(attempt)
+		}
+		if wait > maxRetryWait {
+			return resp, nil
+		}
+
+		//nolint:errcheck // draining lets the connection be reused; best effort
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+		resp.Body.Close()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryable reports whether a request with method answered with status code
+// may be sent again. A 429 means the request was refused before it was
+// handled; a 503 may come after a write took effect, and repeating it could
+// apply it twice or fail confusingly, such as a delete answering 404.
+func retryable(method string, code int) bool {
+	switch code {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusServiceUnavailable:
+		return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+	default:
+		return false
+	}
+}
+
+// retryAfter parses a Retry-After header, given in seconds or as an HTTP date.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// backoff returns the wait before retry attempt+1: doubling from
+// baseRetryDelay, with up to half of it added at random so clients limited
+// together do not retry together.
+func backoff(attempt int) time.Duration {
+	d := baseRetryDelay << attempt
+	//nolint:gosec // jitter needs no cryptographic randomness
+	return d + rand.N(d/2+1)
+}
@@ -0,0 +1,153 @@
+package apiclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// scripted answers attempts with statuses in turn, then 200, recording the
+// body of each attempt.
+func scripted(retryAfter string, statuses ...int) (http.RoundTripper, *[]string) {
+	var bodies []string
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body []byte
+		if req.Body != nil {
+			body, _ = io.ReadAll(req.Body)
+		}
+		bodies = append(bodies, string(body))
+		code := http.StatusOK
+		if n := len(bodies); n <= len(statuses) {
+			code = statuses[n-1]
+		}
+		header := http.Header{}
+		if retryAfter != "" {
+			header.Set("Retry-After", retryAfter)
+		}
+		return &http.Response{StatusCode: code, Header: header, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+	}), &bodies
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		retryAfter string
+		statuses   []int
+		wantCode   int
+		attempts   int
+	}{
+		{"read after 429", http.MethodGet, "", "0", []int{429, 429}, 200, 3},
+		{"read after 503", http.MethodGet, "", "0", []int{503}, 200, 2},
+		{"write after 429 resends its body", http.MethodPost, `{"name":"x"}`, "0", []int{429}, 200, 2},
+		{"write after 503", http.MethodPost, `{"name":"x"}`, "0", []int{503}, 503, 1},
+		{"patch after 503", http.MethodPatch, `{}`, "0", []int{503}, 503, 1},
+		{"delete after 503", http.MethodDelete, "", "0", []int{503}, 503, 1},
+		{"other errors", http.MethodGet, "", "0", []int{500}, 500, 1},
+		{"gives up after maxRetries", http.MethodGet, "", "0", []int{429, 429, 429, 429, 429}, 429, maxRetries + 1},
+		{"wait too long", http.MethodGet, "", "60", []int{429}, 429, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, bodies := scripted(tt.retryAfter, tt.statuses...)
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req, err := http.NewRequestWithContext(context.Background(), tt.method, "https://atlas.test/", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := (&retryTransport{next: next}).RoundTrip(req)
+			if err != nil {
+				t.Fatalf("RoundTrip: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantCode || len(*bodies) != tt.attempts {
+				t.Errorf("status %d after %d attempts, want %d after %d", resp.StatusCode, len(*bodies), tt.wantCode, tt.attempts)
+			}
+			for i, b := range *bodies {
+				if b != tt.body {
+					t.Errorf("attempt %d sent %q, want %q", i+1, b, tt.body)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransportUnrewindableBody(t *testing.T) {
+	next, bodies := scripted("0", http.StatusTooManyRequests)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "https://atlas.test/",
+		io.NopCloser(strings.NewReader("once")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&retryTransport{next: next}).RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || len(*bodies) != 1 {
+		t.Errorf("status %d after %d attempts, want 429 after 1", resp.StatusCode, len(*bodies))
+	}
+}
+
+func TestRetryTransportContext(t *testing.T) {
+	next, _ := scripted("2", http.StatusTooManyRequests)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://atlas.test/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, err := (&retryTransport{next: next}).RoundTrip(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("RoundTrip = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waited %s past the context's end", elapsed)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"0", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+	}
+	for _, tt := range tests {
+		if got, ok := retryAfter(tt.value, now); got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := range maxRetries {
+		base := baseRetryDelay << attempt
+		for range 20 {
+			if d := backoff(attempt); d < base || d > base+base/2 {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", attempt, d, base, base+base/2)
+			}
+		}
+	}
+}
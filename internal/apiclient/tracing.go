@@ -0,0 +1,99 @@
+package apiclient
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the client's spans; without a configured provider it is a no-op.
+var tracer = otel.Tracer("github.com/JoobyPM/feature-atlas-service/internal/apiclient")
+
+// tracingTransport wraps each request, retries included, in a client span
+// and sends its context to the server in W3C trace-context headers. New
+// connections add child spans for connecting and for the TLS handshake, so
+// a slow request shows whether the network, mTLS or the server took the time.
+type tracingTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := tracer.Start(req.Context(), req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.URLFull(req.URL.String()),
+			semconv.ServerAddress(req.URL.Hostname()),
+		))
+	defer span.End()
+
+	ctx = httptrace.WithClientTrace(ctx, connectionTrace(ctx))
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(
+		semconv.HTTPResponseStatusCode(resp.StatusCode),
+		attribute.String("feature_atlas.request_id", resp.Header.Get(RequestIDHeader)),
+	)
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}
+
+// connectionTrace returns hooks adding connection spans under ctx's span.
+// A request reusing a pooled connection gets none.
+func connectionTrace(ctx context.Context) *httptrace.ClientTrace {
+	var connect, handshake trace.Span
+	return &httptrace.ClientTrace{
+		ConnectStart: func(network, addr string) {
+			_, connect = tracer.Start(ctx, "connect", trace.WithAttributes(
+				attribute.String("network.transport", network),
+				attribute.String("network.peer.address", addr)))
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if connect == nil {
+				return
+			}
+			if err != nil {
+				connect.SetStatus(codes.Error, err.Error())
+			}
+			connect.End()
+		},
+		TLSHandshakeStart: func() {
+			_, handshake = tracer.Start(ctx, "tls.handshake")
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			if handshake == nil {
+				return
+			}
+			if err != nil {
+				handshake.RecordError(err)
+				handshake.SetStatus(codes.Error, err.Error())
+			} else {
+				handshake.SetAttributes(
+					attribute.String("tls.protocol.version", tls.VersionName(state.Version)),
+					attribute.Bool("tls.resumed", state.DidResume))
+			}
+			handshake.End()
+		},
+		GotFirstResponseByte: func() {
+			trace.SpanFromContext(ctx).AddEvent("first response byte")
+		},
+	}
+}
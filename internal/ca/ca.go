@@ -0,0 +1,184 @@
+// Package ca signs short-lived client certificates from certificate signing
+// requests, so the service can onboard clients without an external CA step.
+package ca
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+)
+
+// clockSkew backdates NotBefore so clients with a slightly slow clock can
+// use a certificate right away.
+const clockSkew = 5 * time.Minute
+
+// Errors returned by ParseCSRPEM, Sign and Renew.
+var (
+	ErrInvalidCSR = errors.New("invalid certificate signing request")
+	ErrTTL        = errors.New("ttl exceeds the maximum")
+)
+
+// Signer issues client certificates signed by a CA certificate and key.
+type Signer struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+
+	// MaxTTL caps the lifetime of issued certificates and is the default
+	// when a request does not ask for one.
+	MaxTTL time.Duration
+}
+
+// Load reads a PEM CA certificate and its PEM private key (PKCS #8, EC or
+// PKCS #1) and checks that they belong together and may sign certificates.
+func Load(certFile, keyFile string, maxTTL time.Duration) (*Signer, error) {
+	//nolint:gosec // paths are from trusted command-line flags
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CA certificate: %w", err)
+	}
+	//nolint:gosec // paths are from trusted command-line flags
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CA key: %w", err)
+	}
+
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("CA certificate: no CERTIFICATE PEM block")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse CA certificate: %w", err)
+	}
+	if !cert.IsCA || (cert.KeyUsage != 0 && cert.KeyUsage&x509.KeyUsageCertSign == 0) {
+		return nil, errors.New("CA certificate may not sign certificates")
+	}
+
+	key, err := parseKeyPEM(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("parse CA key: %w", err)
+	}
+	pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(cert.PublicKey) {
+		return nil, errors.New("CA key does not match the CA certificate")
+	}
+	if maxTTL <= 0 {
+		return nil, errors.New("maximum certificate ttl must be positive")
+	}
+	return &Signer{cert: cert, key: key, MaxTTL: maxTTL}, nil
+}
+
+// parseKeyPEM parses the first private key block in data.
+func parseKeyPEM(data []byte) (crypto.Signer, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("no private key PEM block")
+		}
+		var key any
+		var err error
+		switch block.Type {
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			key, err = x509.ParseECPrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		default:
+			continue // e.g. EC PARAMETERS
+		}
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported key type %T", key)
+		}
+		return signer, nil
+	}
+}
+
+// Certificate returns the CA certificate.
+func (s *Signer) Certificate() *x509.Certificate {
+	return s.cert
+}
+
+// ParseCSRPEM parses a PEM certificate signing request and checks its signature.
+func ParseCSRPEM(data []byte) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, fmt.Errorf("%w: no CERTIFICATE REQUEST PEM block", ErrInvalidCSR)
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCSR, err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCSR, err)
+	}
+	return csr, nil
+}
+
+// Sign issues a client certificate for the CSR's public key with the given
+// common name, valid for ttl (MaxTTL if zero) but never past the CA's own
+// expiry. Only the public key is taken from the CSR: its subject and SANs
+// are ignored, since identity rules and other services trusting this CA
+// match on SANs, and whoever sends the CSR does not get to choose them.
+func (s *Signer) Sign(csr *x509.CertificateRequest, commonName string, ttl time.Duration) (*x509.Certificate, error) {
+	return s.sign(csr, &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}, ttl)
+}
+
+// Renew issues a successor to cur for the CSR's public key: it keeps cur's
+// subject and SANs and ignores the CSR's, so a client cannot widen its own
+// identity. Validity is as for Sign.
+func (s *Signer) Renew(csr *x509.CertificateRequest, cur *x509.Certificate, ttl time.Duration) (*x509.Certificate, error) {
+	return s.sign(csr, &x509.Certificate{
+		Subject:        cur.Subject,
+		URIs:           cur.URIs,
+		DNSNames:       cur.DNSNames,
+		EmailAddresses: cur.EmailAddresses,
+	}, ttl)
+}
+
+// sign completes tmpl, which carries the subject and SANs, and signs it.
+func (s *Signer) sign(csr *x509.CertificateRequest, tmpl *x509.Certificate, ttl time.Duration) (*x509.Certificate, error) {
+	if ttl == 0 {
+		ttl = s.MaxTTL
+	}
+	if ttl < 0 || ttl > s.MaxTTL {
+		return nil, fmt.Errorf("%w of %s", ErrTTL, s.MaxTTL)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("generate serial: %w", err)
+	}
+	now := time.Now()
+	notAfter := now.Add(ttl)
+	if notAfter.After(s.cert.NotAfter) {
+		notAfter = s.cert.NotAfter
+	}
+	tmpl.SerialNumber = serial
+	tmpl.NotBefore = now.Add(-clockSkew)
+	tmpl.NotAfter = notAfter
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, s.cert, csr.PublicKey, s.key)
+	if err != nil {
+		return nil, fmt.Errorf("sign certificate: %w", err)
+	}
+	return x509.ParseCertificate(der)
+}
+
+// EncodePEM returns cert as a PEM CERTIFICATE block.
+func EncodePEM(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
@@ -0,0 +1,192 @@
+package ca
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCA writes a self-signed certificate and its key to dir and returns
+// their paths. isCA controls the basic constraints.
+func writeCA(t *testing.T, dir string, isCA bool) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey: %v", err)
+	}
+	certFile = filepath.Join(dir, "ca.crt")
+	keyFile = filepath.Join(dir, "ca.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return certFile, keyFile
+}
+
+// newCSR returns a PEM CSR for a fresh key with the given SPIFFE ID.
+func newCSR(t *testing.T, spiffeID string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	u, err := url.Parse(spiffeID)
+	if err != nil {
+		t.Fatalf("url.Parse: %v", err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "ignored"},
+		URIs:    []*url.URL{u},
+	}, key)
+	if err != nil {
+		t.Fatalf("CreateCertificateRequest: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+}
+
+func TestSign(t *testing.T) {
+	certFile, keyFile := writeCA(t, t.TempDir(), true)
+	s, err := Load(certFile, keyFile, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	csr, err := ParseCSRPEM(newCSR(t, "spiffe://example.org/ns/payments/alice"))
+	if err != nil {
+		t.Fatalf("ParseCSRPEM: %v", err)
+	}
+	cert, err := s.Sign(csr, "alice", time.Hour)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	if cert.Subject.CommonName != "alice" {
+		t.Errorf("CommonName = %q, want alice (not the CSR's)", cert.Subject.CommonName)
+	}
+	// The requester does not choose the SANs rules and peers match on
+	if len(cert.URIs) != 0 || len(cert.DNSNames) != 0 || len(cert.EmailAddresses) != 0 {
+		t.Errorf("SANs copied from the CSR: %v %v %v", cert.URIs, cert.DNSNames, cert.EmailAddresses)
+	}
+	if d := time.Until(cert.NotAfter); d > time.Hour || d < 59*time.Minute {
+		t.Errorf("NotAfter in %s, want about 1h", d)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(s.Certificate())
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		t.Errorf("issued certificate does not verify as a client certificate: %v", err)
+	}
+
+	// The default lifetime is the maximum, clamped to the CA's expiry
+	cert, err = s.Sign(csr, "alice", 0)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if !cert.NotAfter.Equal(s.Certificate().NotAfter) {
+		t.Errorf("NotAfter = %v, want the CA's %v", cert.NotAfter, s.Certificate().NotAfter)
+	}
+
+	if _, err := s.Sign(csr, "alice", 48*time.Hour); !errors.Is(err, ErrTTL) {
+		t.Errorf("Sign past MaxTTL: err = %v, want ErrTTL", err)
+	}
+}
+
+func TestRenew(t *testing.T) {
+	certFile, keyFile := writeCA(t, t.TempDir(), true)
+	s, err := Load(certFile, keyFile, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	csr, err := ParseCSRPEM(newCSR(t, "spiffe://example.org/ns/payments/alice"))
+	if err != nil {
+		t.Fatalf("ParseCSRPEM: %v", err)
+	}
+	// A current certificate with a SAN, as another issuer may have set it
+	cur, err := s.sign(csr, &x509.Certificate{Subject: pkix.Name{CommonName: "alice"}, URIs: csr.URIs}, time.Hour)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	// The CSR asks for a different SAN; the successor keeps the current ones
+	next, err := ParseCSRPEM(newCSR(t, "spiffe://example.org/ns/admin/root"))
+	if err != nil {
+		t.Fatalf("ParseCSRPEM: %v", err)
+	}
+	renewed, err := s.Renew(next, cur, 2*time.Hour)
+	if err != nil {
+		t.Fatalf("Renew: %v", err)
+	}
+	if renewed.Subject.CommonName != "alice" || len(renewed.URIs) != 1 || renewed.URIs[0].String() != cur.URIs[0].String() {
+		t.Errorf("renewed identity = %s %v, want %s %v", renewed.Subject, renewed.URIs, cur.Subject, cur.URIs)
+	}
+	if renewed.SerialNumber.Cmp(cur.SerialNumber) == 0 || !renewed.NotAfter.After(cur.NotAfter) {
+		t.Errorf("renewed certificate should have a new serial and a later expiry")
+	}
+}
+
+func TestParseCSRPEM_Invalid(t *testing.T) {
+	csr := newCSR(t, "spiffe://example.org/a")
+	block, _ := pem.Decode(csr)
+	block.Bytes[len(block.Bytes)-1] ^= 0xff // break the signature
+
+	for name, data := range map[string][]byte{
+		"not PEM":       []byte("hello"),
+		"wrong type":    pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: block.Bytes}),
+		"bad signature": pem.EncodeToMemory(block),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseCSRPEM(data); !errors.Is(err, ErrInvalidCSR) {
+				t.Errorf("err = %v, want ErrInvalidCSR", err)
+			}
+		})
+	}
+}
+
+func TestLoad_Rejects(t *testing.T) {
+	t.Run("not a CA", func(t *testing.T) {
+		certFile, keyFile := writeCA(t, t.TempDir(), false)
+		if _, err := Load(certFile, keyFile, time.Hour); err == nil {
+			t.Error("Load accepted a certificate that is not a CA")
+		}
+	})
+
+	t.Run("mismatched key", func(t *testing.T) {
+		certFile, _ := writeCA(t, t.TempDir(), true)
+		_, otherKey := writeCA(t, t.TempDir(), true)
+		if _, err := Load(certFile, otherKey, time.Hour); err == nil {
+			t.Error("Load accepted a key that does not match the certificate")
+		}
+	})
+}
@@ -29,6 +29,7 @@ type CachedFeature struct {
 	ID      string `json:"id"`
 	Name    string `json:"name"`
 	Summary string `json:"summary"`
+	Status  string `json:"status,omitempty"`
 }
 
 // CachedFeatures is the features.json structure.
@@ -0,0 +1,226 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	featureatlasv1 "github.com/JoobyPM/feature-atlas-service/api/featureatlas/v1"
+	"github.com/JoobyPM/feature-atlas-service/internal/httpapi"
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+	"github.com/JoobyPM/feature-atlas-service/internal/tracing"
+)
+
+// GetMe returns the authenticated client.
+func (s *Server) GetMe(ctx context.Context, _ *featureatlasv1.GetMeRequest) (*featureatlasv1.Me, error) {
+	c := callerFrom(ctx)
+	perms := c.client.Role.Permissions()
+	me := &featureatlasv1.Me{
+		Name:            c.client.Name,
+		Role:            string(c.client.Role),
+		Permissions:     make([]string, len(perms)),
+		Teams:           c.client.Teams,
+		Fingerprint:     c.client.Fingerprint,
+		CertFingerprint: store.FingerprintSHA256(c.cert),
+		Subject:         c.cert.Subject.String(),
+		MatchedBy:       c.matchedBy,
+	}
+	for i, p := range perms {
+		me.Permissions[i] = string(p)
+	}
+	return me, nil
+}
+
+// SearchFeatures returns one page of features matching the query.
+func (s *Server) SearchFeatures(ctx context.Context, req *featureatlasv1.SearchFeaturesRequest) (*featureatlasv1.SearchFeaturesResponse, error) {
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = store.DefaultPageSize
+	}
+	end := tracing.StoreCall(ctx, "SearchPage")
+	page, err := s.Store.SearchPage(req.GetQuery(), min(limit, store.MaxPageSize), req.GetCursor())
+	end(err)
+	if err != nil {
+		// ErrInvalidQuery carries the parse error; show it to the user
+		if errors.Is(err, store.ErrInvalidQuery) {
+			return nil, apiError(codes.InvalidArgument, reasonInvalidQuery, err.Error())
+		}
+		return nil, invalidField("cursor", "invalid cursor; restart from the first page")
+	}
+	resp := &featureatlasv1.SearchFeaturesResponse{NextCursor: page.NextCursor}
+	for _, f := range page.Items {
+		resp.Items = append(resp.Items, featureProto(f))
+	}
+	return resp, nil
+}
+
+// SuggestFeatures returns autocomplete suggestions for the query.
+func (s *Server) SuggestFeatures(ctx context.Context, req *featureatlasv1.SuggestFeaturesRequest) (*featureatlasv1.SuggestFeaturesResponse, error) {
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = store.DefaultSuggestLimit
+	}
+	end := tracing.StoreCall(ctx, "Suggest")
+	items := s.Store.Suggest(req.GetQuery(), min(limit, store.MaxSuggestLimit))
+	end(nil)
+	resp := &featureatlasv1.SuggestFeaturesResponse{}
+	for _, it := range items {
+		sugg := &featureatlasv1.Suggestion{Id: it.ID, Name: it.Name, Summary: it.Summary, Status: string(it.Status)}
+		for _, h := range it.Highlights {
+			sugg.Highlights = append(sugg.Highlights, &featureatlasv1.Highlight{
+				Field: h.Field,
+				Start: int32(h.Start), //nolint:gosec // rune offsets within a feature name
+				End:   int32(h.End),   //nolint:gosec // rune offsets within a feature name
+			})
+		}
+		resp.Items = append(resp.Items, sugg)
+	}
+	return resp, nil
+}
+
+// GetFeature returns a feature, or its revision at req.At if set.
+func (s *Server) GetFeature(ctx context.Context, req *featureatlasv1.GetFeatureRequest) (*featureatlasv1.Feature, error) {
+	id := strings.TrimSpace(req.GetId())
+	if id == "" {
+		return nil, invalidField("id", "required")
+	}
+	var (
+		f  store.Feature
+		ok bool
+	)
+	if req.GetAt() != nil {
+		end := tracing.StoreCall(ctx, "FeatureAt")
+		f, ok = s.Store.FeatureAt(id, req.GetAt().AsTime())
+		end(nil)
+	} else {
+		end := tracing.StoreCall(ctx, "GetFeature")
+		f, ok = s.Store.GetFeature(id)
+		end(nil)
+	}
+	if !ok {
+		return nil, apiError(codes.NotFound, reasonFeatureNotFound, "feature not found")
+	}
+	return featureProto(f), nil
+}
+
+// CreateFeature adds a feature. Clients with only features:write:own may
+// create features owned by one of their teams.
+func (s *Server) CreateFeature(ctx context.Context, req *featureatlasv1.CreateFeatureRequest) (*featureatlasv1.Feature, error) {
+	nf, errs := store.ParseNewFeature(req.GetName(), req.GetSummary(), req.GetOwner(), req.GetTags(), req.GetStatus())
+	if len(errs) > 0 {
+		return nil, invalidFields(errs...)
+	}
+	if !callerFrom(ctx).client.CanWriteFeature(nf.Owner) {
+		return nil, apiError(codes.PermissionDenied, reasonForbidden,
+			"permission denied: features:write:own only covers features owned by your teams")
+	}
+
+	end := tracing.StoreCall(ctx, "CreateFeatureWithStatus")
+	f, err := s.Store.As(actor(ctx)).CreateFeature(nf.Name, nf.Summary, nf.Owner, nf.Tags, nf.Status)
+	end(err)
+	switch {
+	case errors.Is(err, store.ErrInvalidStatus):
+		return nil, invalidField("status", "must be proposed or active")
+	case errors.Is(err, store.ErrIDSpaceExhausted):
+		return nil, internalError("feature ID space exhausted")
+	case err != nil:
+		return nil, internalError("failed to store feature")
+	}
+	return featureProto(f), nil
+}
+
+// ListClients returns the registered clients by name.
+func (s *Server) ListClients(ctx context.Context, _ *featureatlasv1.ListClientsRequest) (*featureatlasv1.ListClientsResponse, error) {
+	end := tracing.StoreCall(ctx, "ListClients")
+	clients := s.Store.ListClients()
+	end(nil)
+	resp := &featureatlasv1.ListClientsResponse{}
+	for _, c := range clients {
+		resp.Items = append(resp.Items, clientProto(c))
+	}
+	return resp, nil
+}
+
+// RegisterClient registers a client certificate, or replaces the name, role
+// and teams of a registered one.
+func (s *Server) RegisterClient(ctx context.Context, req *featureatlasv1.RegisterClientRequest) (*featureatlasv1.RegisterClientResponse, error) {
+	client, cert, errs := httpapi.ParseRegistration(req.GetName(), req.GetRole(), req.GetTeams(), req.GetCertPem())
+	if len(errs) > 0 {
+		return nil, invalidFields(errs...)
+	}
+	end := tracing.StoreCall(ctx, "RegisterClient")
+	_, _, err := s.Store.As(actor(ctx)).RegisterClient(client)
+	end(err)
+	switch {
+	case errors.Is(err, store.ErrClientRevoked):
+		// Revocation is permanent for a certificate; issue a new one
+		return nil, apiError(codes.FailedPrecondition, reasonCertificateRevoked, "certificate revoked; issue a new one")
+	case errors.Is(err, store.ErrClientManaged):
+		return nil, apiError(codes.FailedPrecondition, reasonClientManaged, store.ErrClientManaged.Error())
+	case err != nil:
+		return nil, internalError("failed to store client")
+	}
+
+	return &featureatlasv1.RegisterClientResponse{Client: clientProto(client), Subject: cert.Subject.String()}, nil
+}
+
+// RevokeClient revokes a client certificate. Clients declared in the clients
+// file can be revoked too, so a leaked certificate can be shut out at once.
+func (s *Server) RevokeClient(ctx context.Context, req *featureatlasv1.RevokeClientRequest) (*featureatlasv1.Client, error) {
+	fp := store.NormalizeFingerprint(req.GetFingerprint())
+	if fp == "" {
+		return nil, invalidField("fingerprint", "required")
+	}
+	// Locking yourself out is never what was meant
+	if fp == callerFrom(ctx).client.Fingerprint {
+		return nil, apiError(codes.FailedPrecondition, reasonSelfChange, "cannot change, revoke or delete your own certificate")
+	}
+
+	end := tracing.StoreCall(ctx, "RevokeClient")
+	client, err := s.Store.As(actor(ctx)).RevokeClient(fp)
+	end(err)
+	switch {
+	case errors.Is(err, store.ErrClientNotFound):
+		return nil, apiError(codes.NotFound, reasonClientNotFound, "client not found")
+	case err != nil:
+		return nil, internalError("failed to store client")
+	}
+	return clientProto(client), nil
+}
+
+// featureProto converts a feature to its message.
+func featureProto(f store.Feature) *featureatlasv1.Feature {
+	return &featureatlasv1.Feature{
+		Id:         f.ID,
+		Name:       f.Name,
+		Summary:    f.Summary,
+		Owner:      f.Owner,
+		Tags:       f.Tags,
+		Status:     string(f.Status),
+		ReplacedBy: f.ReplacedBy,
+		Version:    f.Version,
+		CreatedAt:  timestamppb.New(f.CreatedAt),
+		UpdatedAt:  timestamppb.New(f.UpdatedAt),
+	}
+}
+
+// clientProto converts a client to its message.
+func clientProto(c store.Client) *featureatlasv1.Client {
+	msg := &featureatlasv1.Client{
+		Fingerprint:         c.Fingerprint,
+		Name:                c.Name,
+		Role:                string(c.Role),
+		Teams:               c.Teams,
+		CreatedAt:           timestamppb.New(c.CreatedAt),
+		Revoked:             c.Revoked,
+		Source:              c.Source,
+		PreviousFingerprint: c.PreviousFingerprint,
+	}
+	if !c.RevokedAt.IsZero() {
+		msg.RevokedAt = timestamppb.New(c.RevokedAt)
+	}
+	return msg
+}
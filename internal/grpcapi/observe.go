@@ -0,0 +1,224 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/JoobyPM/feature-atlas-service/internal/httpapi"
+	"github.com/JoobyPM/feature-atlas-service/internal/metrics"
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+)
+
+// requestIDKey is the metadata key of the request ID, the gRPC form of
+// httpapi.RequestIDHeader: a client may send it, and every response
+// carries it in its header metadata.
+const requestIDKey = "x-request-id"
+
+// tracer creates the service's spans; without a configured provider it is a no-op.
+var tracer = otel.Tracer("github.com/JoobyPM/feature-atlas-service/internal/grpcapi")
+
+// Metrics counts gRPC calls by method and status code. Authentication
+// failures are counted in the REST API's metrics, so one series covers both.
+type Metrics struct {
+	calls    *metrics.CounterVec
+	duration *metrics.HistogramVec
+	api      *httpapi.Metrics
+}
+
+// NewMetrics registers the gRPC call metrics with reg; authentication
+// failures go to api, which may be nil.
+func NewMetrics(reg *metrics.Registry, api *httpapi.Metrics) *Metrics {
+	return &Metrics{
+		calls: reg.NewCounterVec("feature_atlas_grpc_calls_total",
+			"gRPC calls by method and status code.", "method", "code"),
+		duration: reg.NewHistogramVec("feature_atlas_grpc_call_duration_seconds",
+			"gRPC call latency by method and status code.", nil, "method", "code"),
+		api: api,
+	}
+}
+
+// callRecord collects what authorize learns about a call for the access
+// log, metrics and span, written once the call completes.
+type callRecord struct {
+	certFingerprint string
+	client          store.Client
+	authFailure     string // why the call was refused, if it was
+}
+
+type recordKey struct{}
+
+// recordOf returns the call's record, or a throwaway one outside observe.
+func recordOf(ctx context.Context) *callRecord {
+	if rec, ok := ctx.Value(recordKey{}).(*callRecord); ok {
+		return rec
+	}
+	return &callRecord{}
+}
+
+// trace is a unary interceptor that wraps each call in a server span,
+// joining the caller's trace when its metadata carries W3C trace context.
+// It is outermost, so the span covers authentication and logging.
+func (s *Server) trace(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	service, method := splitMethod(info.FullMethod)
+	ctx, span := tracer.Start(ctx, strings.TrimPrefix(info.FullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(semconv.RPCSystemGRPC, semconv.RPCService(service), semconv.RPCMethod(method)))
+	defer span.End()
+
+	rec := &callRecord{}
+	resp, err := handler(context.WithValue(ctx, recordKey{}, rec), req)
+
+	code := status.Code(err)
+	span.SetAttributes(
+		semconv.RPCGRPCStatusCodeKey.Int(int(code)),
+		attribute.String("feature_atlas.request_id", httpapi.RequestIDFromContext(ctx)),
+	)
+	if rec.certFingerprint != "" {
+		span.SetAttributes(
+			attribute.String("feature_atlas.client.name", rec.client.Name),
+			attribute.String("feature_atlas.client.fingerprint", rec.certFingerprint),
+		)
+	}
+	if rec.authFailure != "" {
+		span.SetAttributes(attribute.String("feature_atlas.auth_failure", rec.authFailure))
+	}
+	if serverFault(code) {
+		span.SetStatus(otelcodes.Error, code.String())
+	}
+	return resp, err
+}
+
+// observe is a unary interceptor that gives every call a request ID and
+// records it in the metrics and, if s.Logger is set, the access log. The
+// ID is taken from valid x-request-id metadata or generated, sent back in
+// the response header and available via httpapi.RequestIDFromContext.
+func (s *Server) observe(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	var supplied string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(requestIDKey); len(v) > 0 {
+			supplied = v[0]
+		}
+	}
+	id := httpapi.RequestID(supplied)
+	ctx = httpapi.WithRequestID(ctx, id)
+	// Only fails if the handler already sent its header, which it has not
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id)) //nolint:errcheck // see above
+	// trace sets the record up; this one serves calls made without it
+	if _, ok := ctx.Value(recordKey{}).(*callRecord); !ok {
+		ctx = context.WithValue(ctx, recordKey{}, &callRecord{})
+	}
+
+	resp, err := handler(ctx, req)
+
+	rec := recordOf(ctx)
+	code := status.Code(err)
+	if m := s.Metrics; m != nil {
+		m.calls.Inc(info.FullMethod, code.String())
+		m.duration.Observe(time.Since(start).Seconds(), info.FullMethod, code.String())
+		if rec.authFailure != "" {
+			m.api.AuthFailure(rec.authFailure)
+		}
+	}
+	if s.Logger == nil {
+		return resp, err
+	}
+	level := slog.LevelInfo
+	if serverFault(code) {
+		level = slog.LevelError
+	}
+	attrs := []slog.Attr{
+		slog.String("request_id", id),
+		slog.String("method", info.FullMethod),
+		slog.String("code", code.String()),
+		slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		slog.String("client", rec.client.Name),
+		slog.String("fingerprint", rec.certFingerprint),
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		attrs = append(attrs, slog.String("remote_addr", p.Addr.String()))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		attrs = append(attrs, slog.String("trace_id", sc.TraceID().String()))
+	}
+	s.Logger.LogAttrs(ctx, level, "call", attrs...)
+	return resp, err
+}
+
+// recoverPanic is a unary interceptor that turns a panic in a handler into
+// an Internal error, logging it with its stack, so one bad call cannot take
+// the server down.
+func (s *Server) recoverPanic(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			logger := s.Logger
+			if logger == nil {
+				logger = slog.Default()
+			}
+			logger.LogAttrs(ctx, slog.LevelError, "panic",
+				slog.String("request_id", httpapi.RequestIDFromContext(ctx)),
+				slog.String("method", info.FullMethod),
+				slog.String("panic", fmt.Sprint(v)),
+				slog.String("stack", string(debug.Stack())))
+			resp, err = nil, internalError("internal error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// serverFault reports whether code means the server, not the caller, failed.
+func serverFault(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal,
+		codes.Unavailable, codes.DataLoss:
+		return true
+	default:
+		return false
+	}
+}
+
+// splitMethod splits a full method name "/pkg.Service/Method" into the
+// service and method.
+func splitMethod(fullMethod string) (service, method string) {
+	service, method, _ = strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return service, method
+}
+
+// metadataCarrier adapts incoming metadata to a propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
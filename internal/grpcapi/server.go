@@ -0,0 +1,207 @@
+// Package grpcapi serves the FeatureAtlas gRPC service, the gRPC counterpart
+// of the REST API in httpapi. Callers present the same client certificates,
+// which are resolved and authorized exactly as httpapi.MTLS does, and the
+// methods work on the same store with the same validation.
+package grpcapi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	featureatlasv1 "github.com/JoobyPM/feature-atlas-service/api/featureatlas/v1"
+	"github.com/JoobyPM/feature-atlas-service/internal/httpapi"
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+)
+
+// Server implements the FeatureAtlas service.
+type Server struct {
+	featureatlasv1.UnimplementedFeatureAtlasServer
+
+	Store   *store.Store
+	CRL     *httpapi.CRL           // Certificates to refuse; may be nil
+	Rules   *httpapi.IdentityRules // Fallback for unregistered certificates; may be nil
+	Limiter *httpapi.RateLimiter   // Shared with the REST API so limits span both; nil admits everything
+	Metrics *Metrics               // Call and authentication failure counts; may be nil
+	Logger  *slog.Logger           // Access log, one line per call; nil logs nothing
+}
+
+// GRPCServer returns a gRPC server for s that terminates TLS with config,
+// which must require client certificates like the REST server's. Every call
+// gets a span, a request ID, metrics and an access log line, as REST
+// requests do, including calls refused by authorize.
+func (s *Server) GRPCServer(config *tls.Config, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{
+		grpc.Creds(credentials.NewTLS(config)),
+		grpc.ChainUnaryInterceptor(s.trace, s.observe, s.recoverPanic, s.authorize),
+	}, opts...)
+	gs := grpc.NewServer(opts...)
+	featureatlasv1.RegisterFeatureAtlasServer(gs, s)
+	return gs
+}
+
+// methodPerms maps each method to the permission its REST route requires.
+// An empty permission admits any authenticated client; methods missing here
+// are refused.
+var methodPerms = map[string]store.Permission{
+	featureatlasv1.FeatureAtlas_GetMe_FullMethodName:           "",
+	featureatlasv1.FeatureAtlas_SearchFeatures_FullMethodName:  store.PermFeaturesRead,
+	featureatlasv1.FeatureAtlas_SuggestFeatures_FullMethodName: store.PermFeaturesRead,
+	featureatlasv1.FeatureAtlas_GetFeature_FullMethodName:      store.PermFeaturesRead,
+	// The handler checks the owner when the client lacks features:write
+	featureatlasv1.FeatureAtlas_CreateFeature_FullMethodName:  store.PermFeaturesWriteOwn,
+	featureatlasv1.FeatureAtlas_ListClients_FullMethodName:    store.PermClientsManage,
+	featureatlasv1.FeatureAtlas_RegisterClient_FullMethodName: store.PermClientsManage,
+	featureatlasv1.FeatureAtlas_RevokeClient_FullMethodName:   store.PermClientsManage,
+}
+
+type ctxKey struct{}
+
+// caller is the authenticated client of a call.
+type caller struct {
+	client    store.Client
+	cert      *x509.Certificate
+	matchedBy string
+}
+
+// callerFrom returns the caller authorize stored in ctx.
+func callerFrom(ctx context.Context) caller {
+	c, _ := ctx.Value(ctxKey{}).(caller)
+	return c
+}
+
+// authorize is a unary interceptor that resolves the peer certificate to a
+// client as httpapi.MTLS does, checks the method's permission and the
+// client's rate limit, and makes the client available to the handler.
+func (s *Server) authorize(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	rec := recordOf(ctx)
+	cert, chains := peerCertificate(ctx)
+	if cert == nil {
+		rec.authFailure = httpapi.AuthNoCertificate
+		return nil, apiError(codes.Unauthenticated, reasonUnauthorized, "unauthorized")
+	}
+	// Same generic message for every failure, so registration status does not leak
+	client, matchedBy, failure := httpapi.Authenticate(s.Store, s.CRL, s.Rules, cert, chains)
+	rec.certFingerprint, rec.client, rec.authFailure = store.FingerprintSHA256(cert), client, failure
+	if failure != "" {
+		return nil, apiError(codes.Unauthenticated, reasonUnauthorized, "unauthorized")
+	}
+
+	perm, ok := methodPerms[info.FullMethod]
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "method %s not implemented", info.FullMethod)
+	}
+	if perm != "" && !client.Role.Can(perm) {
+		rec.authFailure = httpapi.AuthForbidden
+		return nil, apiError(codes.PermissionDenied, reasonForbidden, "permission denied: requires "+string(perm))
+	}
+
+	if s.Limiter != nil {
+		if ok, wait := s.Limiter.Allow(client.Fingerprint, client.Role, time.Now()); !ok {
+			wait = time.Duration(math.Ceil(wait.Seconds())) * time.Second
+			return nil, withDetails(status.New(codes.ResourceExhausted, "rate limit exceeded"),
+				errorInfo(reasonRateLimited), &errdetails.RetryInfo{RetryDelay: durationpb.New(wait)})
+		}
+	}
+
+	return handler(context.WithValue(ctx, ctxKey{}, caller{client: client, cert: cert, matchedBy: matchedBy}), req)
+}
+
+// peerCertificate returns the client certificate of the call's connection
+// and the chains the handshake verified it with.
+func peerCertificate(ctx context.Context) (*x509.Certificate, [][]*x509.Certificate) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.PeerCertificates) == 0 {
+		return nil, nil
+	}
+	return info.State.PeerCertificates[0], info.State.VerifiedChains
+}
+
+// Error reasons, carried in a google.rpc.ErrorInfo detail. They are the
+// error codes of the REST API's problem responses.
+const (
+	reasonValidation         = "validation_failed"
+	reasonInvalidQuery       = "invalid_query"
+	reasonUnauthorized       = "unauthorized"
+	reasonForbidden          = "forbidden"
+	reasonFeatureNotFound    = "feature_not_found"
+	reasonClientNotFound     = "client_not_found"
+	reasonClientManaged      = "client_managed"
+	reasonCertificateRevoked = "certificate_revoked"
+	reasonSelfChange         = "self_change"
+	reasonRateLimited        = "rate_limited"
+	reasonInternal           = "internal"
+)
+
+// errorDomain is the ErrorInfo domain of the service's errors.
+const errorDomain = "feature-atlas"
+
+// errorInfo returns the ErrorInfo detail for reason.
+func errorInfo(reason string) *errdetails.ErrorInfo {
+	return &errdetails.ErrorInfo{Reason: reason, Domain: errorDomain}
+}
+
+// apiError returns a status error with code and message, tagged with reason.
+func apiError(code codes.Code, reason, msg string) error {
+	return withDetails(status.New(code, msg), errorInfo(reason))
+}
+
+// invalidFields returns an InvalidArgument error listing errs, which must not
+// be empty, in a google.rpc.BadRequest detail. The message joins them as the
+// REST API's problem detail does.
+func invalidFields(errs ...store.FieldError) error {
+	br := &errdetails.BadRequest{}
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Field + ": " + e.Message
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: e.Field, Description: e.Message})
+	}
+	return withDetails(status.New(codes.InvalidArgument, strings.Join(msgs, "; ")), errorInfo(reasonValidation), br)
+}
+
+// invalidField returns an InvalidArgument error for a single field.
+func invalidField(field, msg string) error {
+	return invalidFields(store.FieldError{Field: field, Message: msg})
+}
+
+// internalError returns an Internal error with msg, which must not leak internals.
+func internalError(msg string) error {
+	return apiError(codes.Internal, reasonInternal, msg)
+}
+
+// withDetails attaches details to st, returning st's error unchanged if they
+// cannot be marshaled.
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	if detailed, err := st.WithDetails(details...); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// actor returns the caller of ctx as the actor of the changes it makes,
+// for their audit entries.
+func actor(ctx context.Context) store.Actor {
+	client := callerFrom(ctx).client
+	return store.Actor{
+		Fingerprint: client.Fingerprint,
+		Name:        client.Name,
+		RequestID:   httpapi.RequestIDFromContext(ctx),
+	}
+}
@@ -0,0 +1,411 @@
+package grpcapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	featureatlasv1 "github.com/JoobyPM/feature-atlas-service/api/featureatlas/v1"
+	"github.com/JoobyPM/feature-atlas-service/internal/ca"
+	"github.com/JoobyPM/feature-atlas-service/internal/httpapi"
+	"github.com/JoobyPM/feature-atlas-service/internal/metrics"
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+	"github.com/JoobyPM/feature-atlas-service/internal/testpki"
+)
+
+// testCA signs the certificates of the server, serverCert, and of clients.
+var (
+	testCA     = testpki.NewCA("Test CA")
+	serverCert = testCA.Server("bufnet")
+)
+
+// register adds cert to st as a client with role.
+func register(t *testing.T, st *store.Store, cert tls.Certificate, role store.Role, teams ...string) {
+	t.Helper()
+	if err := st.UpsertClient(store.Client{
+		Fingerprint: store.FingerprintSHA256(cert.Leaf),
+		Name:        cert.Leaf.Subject.CommonName,
+		Role:        role,
+		Teams:       teams,
+		CreatedAt:   time.Now(),
+	}); err != nil {
+		t.Fatalf("UpsertClient: %v", err)
+	}
+}
+
+// serve starts s over an in-memory listener with TLS from serverCert and
+// returns a function dialing it with a client certificate.
+func serve(t *testing.T, s *Server) func(cert tls.Certificate) featureatlasv1.FeatureAtlasClient {
+	t.Helper()
+	pool := testCA.Pool()
+	gs := s.GRPCServer(&tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+		MinVersion:   tls.VersionTLS12,
+	})
+	lis := bufconn.Listen(1 << 20)
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	return func(cert tls.Certificate) featureatlasv1.FeatureAtlasClient {
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+			grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+				Certificates: []tls.Certificate{cert},
+				RootCAs:      pool,
+				ServerName:   "bufnet",
+				MinVersion:   tls.VersionTLS12,
+			})))
+		if err != nil {
+			t.Fatalf("NewClient: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return featureatlasv1.NewFeatureAtlasClient(conn)
+	}
+}
+
+// reason returns the status code of err and the reason of its ErrorInfo.
+func reason(err error) (codes.Code, string) {
+	st := status.Convert(err)
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return st.Code(), info.GetReason()
+		}
+	}
+	return st.Code(), ""
+}
+
+func TestAuthorization(t *testing.T) {
+	st := store.New()
+	admin, user, stranger := testCA.Client("admin"), testCA.Client("user"), testCA.Client("stranger")
+	register(t, st, admin, store.RoleAdmin)
+	register(t, st, user, store.RoleUser, "payments")
+	dial := serve(t, &Server{Store: st})
+	ctx := context.Background()
+
+	me, err := dial(user).GetMe(ctx, &featureatlasv1.GetMeRequest{})
+	if err != nil {
+		t.Fatalf("GetMe: %v", err)
+	}
+	if me.GetName() != "user" || me.GetRole() != "user" || me.GetMatchedBy() != "fingerprint" ||
+		me.GetSubject() != "CN=user" || me.GetCertFingerprint() != store.FingerprintSHA256(user.Leaf) ||
+		len(me.GetTeams()) != 1 || len(me.GetPermissions()) != 2 {
+		t.Errorf("GetMe = %v", me)
+	}
+
+	_, err = dial(stranger).GetMe(ctx, &featureatlasv1.GetMeRequest{})
+	if code, r := reason(err); code != codes.Unauthenticated || r != reasonUnauthorized {
+		t.Errorf("unregistered certificate: %v, want Unauthenticated", err)
+	}
+
+	_, err = dial(user).ListClients(ctx, &featureatlasv1.ListClientsRequest{})
+	if code, r := reason(err); code != codes.PermissionDenied || r != reasonForbidden ||
+		!strings.Contains(err.Error(), "clients:manage") {
+		t.Errorf("user ListClients: %v, want PermissionDenied naming clients:manage", err)
+	}
+
+	// Revoked clients are refused like unknown ones
+	if _, err := st.RevokeClient(store.FingerprintSHA256(user.Leaf)); err != nil {
+		t.Fatal(err)
+	}
+	_, err = dial(user).GetMe(ctx, &featureatlasv1.GetMeRequest{})
+	if code, _ := reason(err); code != codes.Unauthenticated {
+		t.Errorf("revoked client: %v, want Unauthenticated", err)
+	}
+	if _, err := dial(admin).GetMe(ctx, &featureatlasv1.GetMeRequest{}); err != nil {
+		t.Errorf("admin GetMe: %v", err)
+	}
+}
+
+func TestSuggestLimit(t *testing.T) {
+	st := store.New()
+	if err := st.SeedFeatures(store.MaxSuggestLimit + 10); err != nil {
+		t.Fatal(err)
+	}
+	user := testCA.Client("user")
+	register(t, st, user, store.RoleUser)
+	client := serve(t, &Server{Store: st})(user)
+
+	for _, tt := range []struct {
+		limit int32
+		want  int
+	}{
+		{0, store.DefaultSuggestLimit},
+		{5, 5},
+		{math.MaxInt32, store.MaxSuggestLimit},
+	} {
+		resp, err := client.SuggestFeatures(context.Background(), &featureatlasv1.SuggestFeaturesRequest{Limit: tt.limit})
+		if err != nil || len(resp.GetItems()) != tt.want {
+			t.Errorf("SuggestFeatures with limit %d = %d items, %v; want %d", tt.limit, len(resp.GetItems()), err, tt.want)
+		}
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	st := store.New()
+	user := testCA.Client("user")
+	register(t, st, user, store.RoleUser)
+	limiter := httpapi.NewRateLimiter(map[store.Role]httpapi.RateLimit{store.RoleUser: {Rate: 0.01, Burst: 1}})
+	client := serve(t, &Server{Store: st, Limiter: limiter})(user)
+
+	if _, err := client.GetMe(context.Background(), &featureatlasv1.GetMeRequest{}); err != nil {
+		t.Fatalf("first GetMe: %v", err)
+	}
+	_, err := client.GetMe(context.Background(), &featureatlasv1.GetMeRequest{})
+	if code, r := reason(err); code != codes.ResourceExhausted || r != reasonRateLimited {
+		t.Fatalf("second GetMe: %v, want ResourceExhausted", err)
+	}
+	var retry *errdetails.RetryInfo
+	for _, d := range status.Convert(err).Details() {
+		if ri, ok := d.(*errdetails.RetryInfo); ok {
+			retry = ri
+		}
+	}
+	if retry == nil || retry.GetRetryDelay().AsDuration() < time.Second {
+		t.Errorf("RetryInfo = %v, want a delay of at least a second", retry)
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	st := store.New()
+	if err := st.SeedFeatures(30); err != nil {
+		t.Fatal(err)
+	}
+	editor, user := testCA.Client("editor"), testCA.Client("user")
+	register(t, st, editor, store.RoleEditor)
+	register(t, st, user, store.RoleUser, "payments")
+	dial := serve(t, &Server{Store: st})
+	ctx := context.Background()
+
+	page, err := dial(user).SearchFeatures(ctx, &featureatlasv1.SearchFeaturesRequest{Limit: 20})
+	if err != nil {
+		t.Fatalf("SearchFeatures: %v", err)
+	}
+	if len(page.GetItems()) != 20 || page.GetNextCursor() == "" {
+		t.Fatalf("first page: %d items, cursor %q; want 20 and a cursor", len(page.GetItems()), page.GetNextCursor())
+	}
+	page, err = dial(user).SearchFeatures(ctx, &featureatlasv1.SearchFeaturesRequest{Limit: 20, Cursor: page.GetNextCursor()})
+	if err != nil || len(page.GetItems()) != 10 || page.GetNextCursor() != "" {
+		t.Fatalf("second page: %v, %v; want the last 10", page, err)
+	}
+	_, err = dial(user).SearchFeatures(ctx, &featureatlasv1.SearchFeaturesRequest{Query: `name:"unterminated`})
+	if code, r := reason(err); code != codes.InvalidArgument || r != reasonInvalidQuery {
+		t.Errorf("bad query: %v, want InvalidArgument invalid_query", err)
+	}
+
+	sugg, err := dial(user).SuggestFeatures(ctx, &featureatlasv1.SuggestFeaturesRequest{Query: "FT-00001"})
+	if err != nil || len(sugg.GetItems()) == 0 || len(sugg.GetItems()[0].GetHighlights()) == 0 {
+		t.Errorf("SuggestFeatures = %v, %v; want highlighted suggestions", sugg, err)
+	}
+
+	// Validation is shared with the REST API
+	_, err = dial(editor).CreateFeature(ctx, &featureatlasv1.CreateFeatureRequest{Summary: strings.Repeat("s", store.MaxSummaryLen+1)})
+	if code, r := reason(err); code != codes.InvalidArgument || r != reasonValidation {
+		t.Fatalf("invalid CreateFeature: %v, want InvalidArgument", err)
+	}
+	var fields []string
+	for _, d := range status.Convert(err).Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				fields = append(fields, v.GetField())
+			}
+		}
+	}
+	if strings.Join(fields, ",") != "name,summary" {
+		t.Errorf("field violations = %v, want name and summary", fields)
+	}
+
+	_, err = dial(user).CreateFeature(ctx, &featureatlasv1.CreateFeatureRequest{Name: "Theirs", Summary: "s", Owner: "web"})
+	if code, _ := reason(err); code != codes.PermissionDenied {
+		t.Errorf("CreateFeature for another team: %v, want PermissionDenied", err)
+	}
+	f, err := dial(user).CreateFeature(ctx, &featureatlasv1.CreateFeatureRequest{
+		Name: " Refunds ", Summary: "Refund card payments", Owner: "Payments", Tags: []string{" card ", ""}, Status: "proposed",
+	})
+	if err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+	if f.GetName() != "Refunds" || f.GetStatus() != "proposed" || len(f.GetTags()) != 1 || f.GetVersion() != 1 {
+		t.Errorf("CreateFeature = %v", f)
+	}
+	_, err = dial(editor).CreateFeature(ctx, &featureatlasv1.CreateFeatureRequest{Name: "n", Summary: "s", Status: "removed"})
+	if code, _ := reason(err); code != codes.InvalidArgument {
+		t.Errorf("CreateFeature(removed): %v, want InvalidArgument", err)
+	}
+
+	got, err := dial(user).GetFeature(ctx, &featureatlasv1.GetFeatureRequest{Id: f.GetId()})
+	if err != nil || got.GetName() != "Refunds" {
+		t.Errorf("GetFeature = %v, %v", got, err)
+	}
+	_, err = dial(user).GetFeature(ctx, &featureatlasv1.GetFeatureRequest{Id: "FT-999999"})
+	if code, r := reason(err); code != codes.NotFound || r != reasonFeatureNotFound {
+		t.Errorf("GetFeature(missing): %v, want NotFound", err)
+	}
+
+	entries := st.Audit(store.AuditFilter{Action: store.AuditFeatureCreate})
+	if len(entries) != 1 || entries[0].Target != f.GetId() || entries[0].ActorName != "user" {
+		t.Errorf("audit entries = %+v, want the creation by user", entries)
+	}
+}
+
+func TestClients(t *testing.T) {
+	st := store.New()
+	admin, newcomer := testCA.Client("admin"), testCA.Client("newcomer")
+	register(t, st, admin, store.RoleAdmin)
+	client := serve(t, &Server{Store: st})(admin)
+	ctx := context.Background()
+
+	_, err := client.RegisterClient(ctx, &featureatlasv1.RegisterClientRequest{Name: "newcomer", Role: "root", CertPem: string(ca.EncodePEM(newcomer.Leaf))})
+	if code, _ := reason(err); code != codes.InvalidArgument {
+		t.Errorf("RegisterClient(bad role): %v, want InvalidArgument", err)
+	}
+	reg, err := client.RegisterClient(ctx, &featureatlasv1.RegisterClientRequest{
+		Name: "newcomer", Role: "editor", Teams: []string{"web", "Web"}, CertPem: string(ca.EncodePEM(newcomer.Leaf)),
+	})
+	if err != nil {
+		t.Fatalf("RegisterClient: %v", err)
+	}
+	fp := store.FingerprintSHA256(newcomer.Leaf)
+	if reg.GetClient().GetFingerprint() != fp || reg.GetClient().GetRole() != "editor" ||
+		len(reg.GetClient().GetTeams()) != 1 || reg.GetSubject() != "CN=newcomer" {
+		t.Errorf("RegisterClient = %v", reg)
+	}
+
+	list, err := client.ListClients(ctx, &featureatlasv1.ListClientsRequest{})
+	if err != nil || len(list.GetItems()) != 2 {
+		t.Fatalf("ListClients = %v, %v; want 2 clients", list, err)
+	}
+
+	_, err = client.RevokeClient(ctx, &featureatlasv1.RevokeClientRequest{Fingerprint: store.FingerprintSHA256(admin.Leaf)})
+	if code, r := reason(err); code != codes.FailedPrecondition || r != reasonSelfChange {
+		t.Errorf("revoking yourself: %v, want FailedPrecondition", err)
+	}
+	revoked, err := client.RevokeClient(ctx, &featureatlasv1.RevokeClientRequest{Fingerprint: strings.ToUpper(fp)})
+	if err != nil || !revoked.GetRevoked() || revoked.GetRevokedAt() == nil {
+		t.Fatalf("RevokeClient = %v, %v", revoked, err)
+	}
+	_, err = client.RegisterClient(ctx, &featureatlasv1.RegisterClientRequest{Name: "newcomer", CertPem: string(ca.EncodePEM(newcomer.Leaf))})
+	if code, r := reason(err); code != codes.FailedPrecondition || r != reasonCertificateRevoked {
+		t.Errorf("re-registering a revoked certificate: %v, want FailedPrecondition", err)
+	}
+	_, err = client.RevokeClient(ctx, &featureatlasv1.RevokeClientRequest{Fingerprint: "00"})
+	if code, _ := reason(err); code != codes.NotFound {
+		t.Errorf("RevokeClient(unknown): %v, want NotFound", err)
+	}
+
+	var actions []string
+	for _, e := range st.Audit(store.AuditFilter{}) {
+		actions = append(actions, e.Action)
+	}
+	if want := store.AuditClientUpsert + "," + store.AuditClientRevoke; strings.Join(actions, ",") != want {
+		t.Errorf("audit actions = %v, want %s", actions, want)
+	}
+}
+
+func TestUnauthenticatedWithoutPeer(t *testing.T) {
+	s := &Server{Store: store.New()}
+	_, err := s.authorize(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: featureatlasv1.FeatureAtlas_GetMe_FullMethodName},
+		func(context.Context, any) (any, error) { return nil, errors.New("handler called") })
+	if code, _ := reason(err); code != codes.Unauthenticated {
+		t.Errorf("authorize without a peer: %v, want Unauthenticated", err)
+	}
+}
+
+func TestObserve(t *testing.T) {
+	st := store.New()
+	admin := testCA.Client("admin")
+	register(t, st, admin, store.RoleAdmin)
+	reg := metrics.NewRegistry()
+	var logs bytes.Buffer
+	dial := serve(t, &Server{
+		Store:   st,
+		Metrics: NewMetrics(reg, httpapi.NewMetrics(reg, st)),
+		Logger:  slog.New(slog.NewJSONHandler(&logs, nil)),
+	})
+
+	// A client's request ID is kept after the server's and recorded in the
+	// audit entry
+	ctx := metadata.AppendToOutgoingContext(context.Background(), requestIDKey, "job-42")
+	var header metadata.MD
+	newcomer := testCA.Client("newcomer")
+	_, err := dial(admin).RegisterClient(ctx, &featureatlasv1.RegisterClientRequest{
+		Name: "newcomer", CertPem: string(testpki.CertPEM(newcomer)),
+	}, grpc.Header(&header))
+	if err != nil {
+		t.Fatalf("RegisterClient: %v", err)
+	}
+	got := header.Get(requestIDKey)
+	if len(got) != 1 || !strings.HasSuffix(got[0], "-job-42") || len(got[0]) != 24+len("-job-42") {
+		t.Fatalf("x-request-id header = %q, want a server ID followed by -job-42", got)
+	}
+	id := got[0]
+	if entries := st.Audit(store.AuditFilter{}); len(entries) != 1 || entries[0].RequestID != id {
+		t.Errorf("audit entries = %+v, want one with request ID %s", entries, id)
+	}
+
+	// A refused call gets an ID of its own and counts as an auth failure
+	_, err = dial(testCA.Client("stranger")).GetMe(context.Background(), &featureatlasv1.GetMeRequest{}, grpc.Header(&header))
+	if code, _ := reason(err); code != codes.Unauthenticated {
+		t.Fatalf("GetMe as a stranger: %v, want Unauthenticated", err)
+	}
+	if got := header.Get(requestIDKey); len(got) != 1 || got[0] == "" {
+		t.Errorf("x-request-id header of a refused call = %q", got)
+	}
+
+	var text bytes.Buffer
+	if err := reg.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`feature_atlas_grpc_calls_total{method="/featureatlas.v1.FeatureAtlas/RegisterClient",code="OK"} 1`,
+		`feature_atlas_grpc_calls_total{method="/featureatlas.v1.FeatureAtlas/GetMe",code="Unauthenticated"} 1`,
+		`feature_atlas_auth_failures_total{reason="unknown_certificate"} 1`,
+	} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("metrics lack %s", want)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines, want 2:\n%s", len(lines), logs.String())
+	}
+	var line map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &line); err != nil {
+		t.Fatalf("decode %s: %v", lines[0], err)
+	}
+	if line["msg"] != "call" || line["request_id"] != id || line["code"] != "OK" || line["client"] != "admin" ||
+		line["method"] != featureatlasv1.FeatureAtlas_RegisterClient_FullMethodName {
+		t.Errorf("log line = %s", lines[0])
+	}
+}
+
+func TestRecoverPanic(t *testing.T) {
+	var logs bytes.Buffer
+	s := &Server{Store: store.New(), Logger: slog.New(slog.NewJSONHandler(&logs, nil))}
+	_, err := s.recoverPanic(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: featureatlasv1.FeatureAtlas_GetMe_FullMethodName},
+		func(context.Context, any) (any, error) { panic("boom") })
+	if code, r := reason(err); code != codes.Internal || r != reasonInternal {
+		t.Errorf("after a panic: %v, want Internal", err)
+	}
+	if !strings.Contains(logs.String(), `"panic":"boom"`) {
+		t.Errorf("panic not logged: %s", logs.String())
+	}
+}
@@ -0,0 +1,167 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+)
+
+// RequestIDHeader carries the request ID in both directions: a client may
+// set it to correlate its own logs, and every response carries the ID the
+// server gave the request, which includes the client's.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen bounds IDs accepted from clients.
+const maxRequestIDLen = 128
+
+// requestRecord collects what inner middleware and handlers learn about a
+// request for the access log and metrics, written once it completes.
+type requestRecord struct {
+	certFingerprint string
+	client          store.Client
+	route           string // pattern of the route that served the request
+	authFailure     string // why the request was refused, if it was
+}
+
+// withRecord returns the request's record, attaching a new one to r if the
+// request has none yet.
+func withRecord(r *http.Request) (*requestRecord, *http.Request) {
+	if rec, ok := r.Context().Value(ctxRecordKey).(*requestRecord); ok {
+		return rec, r
+	}
+	rec := &requestRecord{}
+	return rec, r.WithContext(context.WithValue(r.Context(), ctxRecordKey, rec))
+}
+
+// recordOf returns the request's record, or a throwaway one outside
+// AccessLog and Instrument.
+func recordOf(ctx context.Context) *requestRecord {
+	if rec, ok := ctx.Value(ctxRecordKey).(*requestRecord); ok {
+		return rec
+	}
+	return &requestRecord{}
+}
+
+// AccessLog returns middleware that gives every request an ID and, unless
+// logger is nil, logs one structured line per request after it completes.
+// The ID is generated, extended with a valid X-Request-ID request header as
+// RequestID does, returned in the response header and available via
+// RequestIDFromContext.
+// It should wrap MTLS, so rejected requests are logged too.
+func AccessLog(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := RequestID(r.Header.Get(RequestIDHeader))
+		w.Header().Set(RequestIDHeader, id)
+
+		rec, r := withRecord(r)
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r.WithContext(WithRequestID(r.Context(), id)))
+
+		if logger == nil {
+			return
+		}
+		level := slog.LevelInfo
+		if sw.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("request_id", id),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", sw.status),
+			slog.Int64("bytes", sw.bytes),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client", rec.client.Name),
+			slog.String("fingerprint", rec.certFingerprint),
+			slog.String("remote_addr", r.RemoteAddr),
+		}
+		if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+			attrs = append(attrs, slog.String("trace_id", sc.TraceID().String()))
+		}
+		logger.LogAttrs(r.Context(), level, "request", attrs...)
+	})
+}
+
+// RequestIDFromContext returns the request ID AccessLog assigned, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(ctxRequestIDKey).(string)
+	return id
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID id, for
+// servers other than the REST API's to share RequestIDFromContext.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxRequestIDKey, id)
+}
+
+// RequestID returns the ID to give a request whose client supplied the
+// given one, which may be empty. IDs always start with one the server
+// generated, so they are unique and a client cannot pass its request off as
+// another in the logs or audit trail; a valid supplied ID follows after a
+// "-", so clients can still find their requests by their own IDs.
+func RequestID(supplied string) string {
+	if validRequestID(supplied) {
+		return newRequestID() + "-" + supplied
+	}
+	return newRequestID()
+}
+
+// newRequestID returns 96 random bits as hex.
+func newRequestID() string {
+	var b [12]byte
+	_, _ = rand.Read(b[:]) // never fails; see crypto/rand
+	return hex.EncodeToString(b[:])
+}
+
+// validRequestID reports whether a client-supplied ID is safe to echo and
+// log: 1 to maxRequestIDLen letters, digits and "-_.:".
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// statusWriter records the status code and body size of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to flush.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,128 @@
+package httpapi
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+)
+
+func TestValidRequestID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"job-42", true},
+		{"a.b_c:D-9", true},
+		{strings.Repeat("x", maxRequestIDLen), true},
+		{"", false},
+		{strings.Repeat("x", maxRequestIDLen+1), false},
+		{"job 42", false},
+		{"job\n42", false},
+		{`"quoted"`, false},
+		{"é", false},
+	}
+	for _, tt := range tests {
+		if got := validRequestID(tt.id); got != tt.want {
+			t.Errorf("validRequestID(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+// serverID matches the server-generated part that starts every request ID.
+var serverID = regexp.MustCompile(`^[0-9a-f]{24}`)
+
+func TestRequestID(t *testing.T) {
+	for _, supplied := range []string{"", "job 42", strings.Repeat("x", maxRequestIDLen+1)} {
+		if id := RequestID(supplied); !serverID.MatchString(id) || len(id) != 24 {
+			t.Errorf("RequestID(%q) = %q, want a new ID only", supplied, id)
+		}
+	}
+
+	a, b := RequestID("job-42"), RequestID("job-42")
+	if !serverID.MatchString(a) || a[24:] != "-job-42" {
+		t.Errorf("RequestID(job-42) = %q, want a new ID followed by -job-42", a)
+	}
+	if a == b {
+		t.Errorf("two requests supplying the same ID both got %q", a)
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	st := store.New()
+	editor := registerTestClient(t, st, "editor", store.RoleEditor)
+	var logs bytes.Buffer
+	h := AccessLog(slog.New(slog.NewJSONHandler(&logs, nil)), MTLS(st, nil, nil, (&Server{Store: st}).Routes()))
+
+	// do sends a request as cert, or without a certificate if it is nil,
+	// and returns the response and its access log line.
+	do := func(cert *x509.Certificate, method, path, body, requestID string) (*httptest.ResponseRecorder, map[string]any) {
+		t.Helper()
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.RemoteAddr = "192.0.2.7:5555"
+		r.TLS = &tls.ConnectionState{}
+		if cert != nil {
+			r.TLS.PeerCertificates = []*x509.Certificate{cert}
+		}
+		if requestID != "" {
+			r.Header.Set(RequestIDHeader, requestID)
+		}
+		logs.Reset()
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		var line map[string]any
+		if err := json.Unmarshal(logs.Bytes(), &line); err != nil {
+			t.Fatalf("decode log line %q: %v", logs.String(), err)
+		}
+		return w, line
+	}
+
+	// A client's ID follows the server's in the response, log and audit trail
+	w, line := do(editor, http.MethodPost, "/admin/v1/features",
+		`{"name":"Dark mode","summary":"Darker","owner":"web"}`, "job-42")
+	id := w.Header().Get(RequestIDHeader)
+	if w.Code != http.StatusCreated || !serverID.MatchString(id) || !strings.HasSuffix(id, "-job-42") {
+		t.Fatalf("status %d, %s %q; want 201 and a server ID followed by -job-42", w.Code, RequestIDHeader, id)
+	}
+	want := map[string]any{
+		"level":       "INFO",
+		"msg":         "request",
+		"request_id":  id,
+		"method":      http.MethodPost,
+		"path":        "/admin/v1/features",
+		"status":      float64(http.StatusCreated),
+		"bytes":       float64(w.Body.Len()),
+		"client":      "editor",
+		"fingerprint": store.FingerprintSHA256(editor),
+		"remote_addr": "192.0.2.7:5555",
+	}
+	for k, v := range want {
+		if line[k] != v {
+			t.Errorf("log %s = %v, want %v", k, line[k], v)
+		}
+	}
+	if _, ok := line["duration_ms"].(float64); !ok {
+		t.Errorf("log duration_ms = %v, want a number", line["duration_ms"])
+	}
+	if entries := st.Audit(store.AuditFilter{}); len(entries) != 1 || entries[0].RequestID != id {
+		t.Errorf("audit entries = %+v, want one with request ID %s", entries, id)
+	}
+
+	// An unusable ID is replaced, and refused requests are logged too
+	w, line = do(nil, http.MethodGet, "/api/v1/features", "", "bad id\r\nX-Injected: 1")
+	id = w.Header().Get(RequestIDHeader)
+	if w.Code != http.StatusUnauthorized || !serverID.MatchString(id) || len(id) != 24 {
+		t.Errorf("status %d, %s %q; want 401 and a new ID", w.Code, RequestIDHeader, id)
+	}
+	if line["request_id"] != id || line["status"] != float64(http.StatusUnauthorized) || line["client"] != "" {
+		t.Errorf("log line of a refused request = %v", line)
+	}
+}
@@ -0,0 +1,65 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+	"github.com/JoobyPM/feature-atlas-service/internal/tracing"
+)
+
+// actor returns the authenticated client of r as the actor of the changes
+// it makes, for their audit entries.
+func actor(r *http.Request) store.Actor {
+	client := ClientFromContext(r.Context())
+	return store.Actor{
+		Fingerprint: client.Fingerprint,
+		Name:        client.Name,
+		RequestID:   RequestIDFromContext(r.Context()),
+	}
+}
+
+// handleAudit lists audit entries, oldest first.
+// Query parameters: actor, action, target, since (RFC 3339), after (entry ID), limit.
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	q := r.URL.Query()
+	filter := store.AuditFilter{
+		Actor:  q.Get("actor"),
+		Action: q.Get("action"),
+		Target: q.Get("target"),
+		Limit:  min(atoiDefault(q.Get("limit"), 100), store.MaxPageSize),
+	}
+	if v := q.Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeFieldError(w, "since", "expected RFC 3339 time")
+			return
+		}
+		filter.Since = since
+	}
+	if v := q.Get("after"); v != "" {
+		after, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || after < 0 {
+			writeFieldError(w, "after", "expected entry ID")
+			return
+		}
+		filter.AfterID = after
+	}
+
+	end := tracing.StoreCall(r.Context(), "Audit")
+	items := s.Store.Audit(filter)
+	end(nil)
+	if items == nil {
+		items = []store.AuditEntry{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"items": items,
+		"count": len(items),
+	})
+}
@@ -0,0 +1,195 @@
+package httpapi
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/JoobyPM/feature-atlas-service/internal/ca"
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+	"github.com/JoobyPM/feature-atlas-service/internal/tracing"
+)
+
+// handleCertificates signs a client certificate from a CSR and registers it.
+// The request names the client (which becomes the certificate's CN), its role
+// and teams, and optionally a ttl no longer than the signer's maximum. The
+// response carries the certificate and the CA certificate, both PEM.
+func (s *Server) handleCertificates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	if s.CA == nil {
+		writeError(w, http.StatusNotImplemented, codeIssuanceDisabled, "certificate issuance is not enabled on this server")
+		return
+	}
+
+	body, err := readAllLimit(r.Body, 1<<20)
+	if err != nil {
+		writeInvalidBody(w)
+		return
+	}
+	var req struct {
+		Name   string   `json:"name"`
+		Role   string   `json:"role"`
+		Teams  []string `json:"teams"`
+		TTL    string   `json:"ttl"`
+		CSRPEM string   `json:"csr_pem"`
+	}
+	if unmarshalErr := json.Unmarshal(body, &req); unmarshalErr != nil {
+		writeInvalidBody(w)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	role, roleErrs := store.CheckRole(req.Role)
+	if req.Name == "" || strings.TrimSpace(req.CSRPEM) == "" || len(roleErrs) > 0 {
+		var errs fieldErrors
+		errs.require("name", req.Name)
+		errs.require("csr_pem", req.CSRPEM)
+		invalidFields(append(errs, roleErrs...)...).write(w)
+		return
+	}
+	var ttl time.Duration
+	if req.TTL != "" {
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+			writeFieldError(w, "ttl", "expected a positive duration such as 24h")
+			return
+		}
+	}
+
+	csr, err := ca.ParseCSRPEM([]byte(req.CSRPEM))
+	if err != nil {
+		writeFieldError(w, "csr_pem", err.Error())
+		return
+	}
+	cert, err := s.CA.Sign(csr, req.Name, ttl)
+	if errors.Is(err, ca.ErrTTL) {
+		writeFieldError(w, "ttl", err.Error())
+		return
+	}
+	if err != nil {
+		writeInternal(w, "failed to sign certificate")
+		return
+	}
+
+	// A fresh serial and key make the fingerprint new, so there is no
+	// previous registration to replace
+	client := store.Client{
+		Fingerprint: store.FingerprintSHA256(cert),
+		Name:        req.Name,
+		Role:        role,
+		Teams:       store.NormalizeTeams(req.Teams),
+		CreatedAt:   time.Now(),
+	}
+	end := tracing.StoreCall(r.Context(), "UpsertClient")
+	upsertErr := s.Store.As(actor(r)).IssueClient(client)
+	end(upsertErr)
+	if upsertErr != nil {
+		writeInternal(w, "failed to store client")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, s.issuedCertificate(client, cert))
+}
+
+// handleRenewCertificate replaces the caller's certificate: it signs a CSR
+// for a new key with the current certificate's subject and SANs and moves
+// the caller's registration to the new certificate. The old one keeps
+// working until the new one is first used, so a client that lost the
+// response can renew again. Only certificates registered by fingerprint can be renewed;
+// rule-mapped ones belong to whoever issued them, and declared ones to the
+// clients file.
+func (s *Server) handleRenewCertificate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	if s.CA == nil {
+		writeError(w, http.StatusNotImplemented, codeIssuanceDisabled, "certificate issuance is not enabled on this server")
+		return
+	}
+	if match, _ := r.Context().Value(ctxMatchKey).(string); match != matchFingerprint && match != matchPreviousFingerprint {
+		writeError(w, http.StatusConflict, codeRuleMapped, "certificate is mapped by an identity rule; renew it with its issuer")
+		return
+	}
+	if ClientFromContext(r.Context()).Source != "" {
+		// The clients file names the certificate; a new one would be dropped
+		// from the registry on the next sync
+		writeError(w, http.StatusConflict, codeClientManaged, store.ErrClientManaged.Error())
+		return
+	}
+
+	body, err := readAllLimit(r.Body, 1<<20)
+	if err != nil {
+		writeInvalidBody(w)
+		return
+	}
+	var req struct {
+		TTL    string `json:"ttl"`
+		CSRPEM string `json:"csr_pem"`
+	}
+	if unmarshalErr := json.Unmarshal(body, &req); unmarshalErr != nil {
+		writeInvalidBody(w)
+		return
+	}
+	if strings.TrimSpace(req.CSRPEM) == "" {
+		writeFieldError(w, "csr_pem", "required")
+		return
+	}
+	var ttl time.Duration
+	if req.TTL != "" {
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+			writeFieldError(w, "ttl", "expected a positive duration such as 24h")
+			return
+		}
+	}
+
+	csr, err := ca.ParseCSRPEM([]byte(req.CSRPEM))
+	if err != nil {
+		writeFieldError(w, "csr_pem", err.Error())
+		return
+	}
+	cert, err := s.CA.Renew(csr, CertFromContext(r.Context()), ttl)
+	if errors.Is(err, ca.ErrTTL) {
+		writeFieldError(w, "ttl", err.Error())
+		return
+	}
+	if err != nil {
+		writeInternal(w, "failed to sign certificate")
+		return
+	}
+
+	end := tracing.StoreCall(r.Context(), "RekeyClient")
+	// Renewing with the certificate a lost renewal replaced renews again
+	client, err := s.Store.As(actor(r)).RekeyClient(store.FingerprintSHA256(CertFromContext(r.Context())), store.FingerprintSHA256(cert))
+	end(err)
+	switch {
+	case errors.Is(err, store.ErrClientNotFound), errors.Is(err, store.ErrClientRevoked):
+		// Deleted or revoked since this request was authenticated
+		writeError(w, http.StatusConflict, codeClientGone, "client is no longer registered")
+		return
+	case err != nil:
+		writeInternal(w, "failed to store client")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, s.issuedCertificate(client, cert))
+}
+
+// issuedCertificate is the response body for a newly signed certificate.
+func (s *Server) issuedCertificate(client store.Client, cert *x509.Certificate) map[string]any {
+	return map[string]any{
+		"fingerprint": client.Fingerprint,
+		"name":        client.Name,
+		"role":        client.Role,
+		"teams":       client.Teams,
+		"subject":     cert.Subject.String(),
+		"serial":      cert.SerialNumber.Text(16),
+		"not_after":   cert.NotAfter,
+		"cert_pem":    string(ca.EncodePEM(cert)),
+		"ca_pem":      string(ca.EncodePEM(s.CA.Certificate())),
+	}
+}
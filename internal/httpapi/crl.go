@@ -0,0 +1,145 @@
+package httpapi
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// CRL is the set of client certificates revoked by a certificate revocation
+// list file. The list must be signed by one of the trusted issuers. Reload
+// re-reads the file; a nil *CRL revokes nothing. It is safe for concurrent use.
+type CRL struct {
+	path string
+
+	mu      sync.RWMutex
+	issuers []*x509.Certificate
+	revoked map[string]struct{} // revocationKey of each revoked certificate
+}
+
+// LoadCRL reads the PEM or DER revocation list at path, verifying its
+// signature against issuers (typically the client CA certificates).
+func LoadCRL(path string, issuers []*x509.Certificate) (*CRL, error) {
+	c := &CRL{path: path, issuers: issuers}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reload re-reads the revocation list. On error the previous list stays in effect.
+func (c *CRL) Reload() error {
+	//nolint:gosec // path is from trusted command-line flag
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return fmt.Errorf("read CRL: %w", err)
+	}
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != "X509 CRL" {
+			return fmt.Errorf("unexpected PEM block %q in CRL file", block.Type)
+		}
+		data = block.Bytes
+	}
+	rl, err := x509.ParseRevocationList(data)
+	if err != nil {
+		return fmt.Errorf("parse CRL: %w", err)
+	}
+	if err := c.verify(rl); err != nil {
+		return err
+	}
+	if !rl.NextUpdate.IsZero() && time.Now().After(rl.NextUpdate) {
+		// Still enforce what it lists: a stale list beats no list
+		log.Printf("warning: CRL %s is past its next update (%s)", c.path, rl.NextUpdate.Format(time.RFC3339))
+	}
+
+	revoked := make(map[string]struct{}, len(rl.RevokedCertificateEntries))
+	for _, e := range rl.RevokedCertificateEntries {
+		revoked[revocationKey(rl.RawIssuer, e.SerialNumber.String())] = struct{}{}
+	}
+
+	c.mu.Lock()
+	c.revoked = revoked
+	c.mu.Unlock()
+	return nil
+}
+
+// SetIssuers replaces the trusted issuers, e.g. after the client CA bundle
+// was reloaded. It takes effect on the next Reload.
+func (c *CRL) SetIssuers(issuers []*x509.Certificate) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.issuers = issuers
+	c.mu.Unlock()
+}
+
+// verify checks that rl was signed by one of the trusted issuers.
+func (c *CRL) verify(rl *x509.RevocationList) error {
+	c.mu.RLock()
+	issuers := c.issuers
+	c.mu.RUnlock()
+	for _, issuer := range issuers {
+		if bytes.Equal(issuer.RawSubject, rl.RawIssuer) && rl.CheckSignatureFrom(issuer) == nil {
+			return nil
+		}
+	}
+	return errors.New("CRL is not signed by a trusted client CA")
+}
+
+// Len returns the number of revoked certificates.
+func (c *CRL) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.revoked)
+}
+
+// IsRevoked reports whether cert is on the revocation list.
+func (c *CRL) IsRevoked(cert *x509.Certificate) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.revoked[revocationKey(cert.RawIssuer, cert.SerialNumber.String())]
+	return ok
+}
+
+// revocationKey identifies a certificate by issuer and serial number,
+// which is how revocation lists refer to it.
+func revocationKey(rawIssuer []byte, serial string) string {
+	return string(rawIssuer) + "\x00" + serial
+}
+
+// ParseCertsPEM parses every CERTIFICATE block in data.
+func ParseCertsPEM(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates found in PEM")
+	}
+	return certs, nil
+}
@@ -0,0 +1,128 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+)
+
+// Event stream timing.
+const (
+	// eventHeartbeat is how often an idle stream gets a comment line, so
+	// proxies keep it open and clients can tell it is alive.
+	eventHeartbeat = 25 * time.Second
+	// eventWriteTimeout bounds each write to a stream, which outlives the
+	// server's WriteTimeout.
+	eventWriteTimeout = 10 * time.Second
+	// eventRetry is the reconnection delay suggested to clients.
+	eventRetry = 3 * time.Second
+)
+
+// eventResync is the type of the event telling a client that events it
+// asked to resume from are gone, so it must reload what it derived from the
+// catalog.
+const eventResync = "resync"
+
+// handleEvents streams catalog changes as server-sent events. Each event's
+// ID is that of the store change; a client reconnecting with Last-Event-ID
+// gets the events it missed, or a resync event if they are no longer kept.
+// The stream ends when the client is revoked or deleted.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	after, resume := s.Store.LastEventID(), false
+	if v := strings.TrimSpace(r.Header.Get("Last-Event-ID")); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeFieldError(w, "Last-Event-ID", "must be an event ID")
+			return
+		}
+		after, resume = id, true
+	}
+	sub := s.Store.Subscribe(after)
+	defer sub.Close()
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no") // stop nginx from buffering the stream
+	w.WriteHeader(http.StatusOK)
+
+	client := ClientFromContext(r.Context())
+	rc := http.NewResponseController(w)
+	send := func(msg string) bool {
+		// Revoking or deleting a client cuts its stream off like its requests
+		c, ok := s.Store.GetClient(client.Fingerprint)
+		if !ok || c.Revoked || !c.Role.Can(store.PermFeaturesRead) {
+			return false
+		}
+		// Not every ResponseWriter supports deadlines; those are not servers
+		_ = rc.SetWriteDeadline(time.Now().Add(eventWriteTimeout)) //nolint:errcheck // see above
+		if _, err := io.WriteString(w, msg); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	// Tell a new client where it starts, so it can resume even if it
+	// disconnects before the first event
+	first := "retry: " + strconv.FormatInt(eventRetry.Milliseconds(), 10) + "\n"
+	switch {
+	case sub.Missed:
+		first += formatEvent(sub.Start, eventResync, struct{}{})
+	case !resume:
+		first += "id: " + strconv.FormatUint(sub.Start, 10) + "\n\n"
+	default:
+		first += "\n"
+	}
+	if !send(first) {
+		return
+	}
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-sub.C:
+			if !ok {
+				// Fell behind or the server is shutting down; the client
+				// resumes from the last event it got
+				return
+			}
+			if !send(eventMessage(ev)) {
+				return
+			}
+		case <-heartbeat.C:
+			if !send(": keepalive\n\n") {
+				return
+			}
+		}
+	}
+}
+
+// eventMessage formats a store event as a server-sent event: the feature
+// for feature events, the catalog size for a reseed.
+func eventMessage(ev store.Event) string {
+	if ev.Type == store.EventCatalogReseeded {
+		return formatEvent(ev.ID, ev.Type, map[string]int{"count": ev.Count})
+	}
+	return formatEvent(ev.ID, ev.Type, ev.Feature)
+}
+
+// formatEvent formats a server-sent event with id, type and data encoded
+// as JSON, which never spans lines.
+func formatEvent(id uint64, typ string, data any) string {
+	//nolint:errchkjson // data is a feature or a map of ints
+	b, _ := json.Marshal(data)
+	return "id: " + strconv.FormatUint(id, 10) + "\nevent: " + typ + "\ndata: " + string(b) + "\n\n"
+}
@@ -0,0 +1,185 @@
+package httpapi
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+)
+
+// sseEvent is a parsed server-sent event.
+type sseEvent struct {
+	id, typ, data string
+	retry         bool
+}
+
+// readSSE reads the next event from r, skipping comments.
+func readSSE(t *testing.T, r *bufio.Reader) (sseEvent, error) {
+	t.Helper()
+	var ev sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return ev, err
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if ev != (sseEvent{}) {
+				return ev, nil
+			}
+			continue
+		}
+		field, value, _ := strings.Cut(line, ": ")
+		switch field {
+		case "id":
+			ev.id = value
+		case "event":
+			ev.typ = value
+		case "data":
+			ev.data = value
+		case "retry":
+			ev.retry = true
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	st := store.New()
+	if err := st.SeedFeatures(2); err != nil {
+		t.Fatalf("SeedFeatures: %v", err)
+	}
+	reader := registerTestClient(t, st, "reader", store.RoleUser)
+	h := MTLS(st, nil, nil, (&Server{Store: st}).Routes())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{reader}}
+		h.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	open := func(lastEventID string) *bufio.Reader {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/v1/events", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatalf("GET /api/v1/events: %v", err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+			t.Fatalf("status %d, Content-Type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		return bufio.NewReader(resp.Body)
+	}
+	next := func(r *bufio.Reader) sseEvent {
+		t.Helper()
+		ev, err := readSSE(t, r)
+		if err != nil {
+			t.Fatalf("read event: %v", err)
+		}
+		return ev
+	}
+
+	// A new stream starts with its position
+	live := open("")
+	start := next(live)
+	if !start.retry || start.id != strconv.FormatUint(st.LastEventID(), 10) || start.typ != "" {
+		t.Fatalf("first message = %+v, want retry and id %d", start, st.LastEventID())
+	}
+
+	f, err := st.CreateFeature("Dark mode", "Darker", "web", nil)
+	if err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+	created := next(live)
+	var got store.Feature
+	if err := json.Unmarshal([]byte(created.data), &got); err != nil {
+		t.Fatalf("decode %q: %v", created.data, err)
+	}
+	if created.typ != store.EventFeatureCreated || got.ID != f.ID || got.Name != "Dark mode" {
+		t.Errorf("created event = %+v", created)
+	}
+
+	if err := st.DeleteFeature(f.ID, 0); err != nil {
+		t.Fatalf("DeleteFeature: %v", err)
+	}
+	if err := st.SeedFeatures(4); err != nil {
+		t.Fatalf("SeedFeatures: %v", err)
+	}
+	deleted, reseeded := next(live), next(live)
+	if deleted.typ != store.EventFeatureDeleted || reseeded.typ != store.EventCatalogReseeded || reseeded.data != `{"count":4}` {
+		t.Errorf("events = %+v, %+v", deleted, reseeded)
+	}
+
+	// Resuming replays what came after the given event
+	resumed := open(created.id)
+	if ev := next(resumed); !ev.retry || ev.id != "" {
+		t.Errorf("resumed stream starts with %+v, want retry only", ev)
+	}
+	if ev := next(resumed); ev.id != deleted.id || ev.typ != store.EventFeatureDeleted {
+		t.Errorf("first resumed event = %+v, want %+v", ev, deleted)
+	}
+	if ev := next(resumed); ev.id != reseeded.id {
+		t.Errorf("second resumed event = %+v, want %+v", ev, reseeded)
+	}
+
+	// An ID the server never issued calls for a reload
+	stale := open("999999")
+	if ev := next(stale); ev.typ != eventResync || ev.id != reseeded.id {
+		t.Errorf("stale resume starts with %+v, want resync at %s", ev, reseeded.id)
+	}
+
+	// Revoking the client ends its streams at the next write
+	if _, err := st.RevokeClient(store.FingerprintSHA256(reader)); err != nil {
+		t.Fatalf("RevokeClient: %v", err)
+	}
+	if _, err := st.CreateFeature("After", "Revocation", "web", nil); err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+	if ev, err := readSSE(t, live); !errors.Is(err, io.EOF) {
+		t.Errorf("revoked stream got %+v, %v; want EOF", ev, err)
+	}
+}
+
+func TestEventsShutdown(t *testing.T) {
+	st := store.New()
+	reader := registerTestClient(t, st, "reader", store.RoleUser)
+	h := MTLS(st, nil, nil, (&Server{Store: st}).Routes())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{reader}}
+		h.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL + "/api/v1/events")
+	if err != nil {
+		t.Fatalf("GET /api/v1/events: %v", err)
+	}
+	defer resp.Body.Close()
+	r := bufio.NewReader(resp.Body)
+	if _, err := readSSE(t, r); err != nil {
+		t.Fatalf("read first message: %v", err)
+	}
+
+	st.CloseSubscriptions()
+	if ev, err := readSSE(t, r); !errors.Is(err, io.EOF) {
+		t.Errorf("after CloseSubscriptions got %+v, %v; want EOF", ev, err)
+	}
+}
@@ -1,50 +1,104 @@
 package httpapi
 
 import (
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/JoobyPM/feature-atlas-service/internal/ca"
+	"github.com/JoobyPM/feature-atlas-service/internal/metrics"
 	"github.com/JoobyPM/feature-atlas-service/internal/store"
+	"github.com/JoobyPM/feature-atlas-service/internal/tracing"
 )
 
 // Server holds the application state and provides HTTP handlers.
 type Server struct {
-	Store *store.Store
+	Store   *store.Store
+	CA      *ca.Signer        // Signs client certificates; nil disables issuance
+	Metrics *metrics.Registry // Served on /metrics by HealthRoutes; nil disables it
 }
 
 // Routes returns the HTTP handler with all routes configured.
+// Every route declares the permission each of its methods requires;
+// methods not listed are rejected with 405. The MTLS middleware must wrap
+// the result so the client is known.
 func (s *Server) Routes() http.Handler {
 	mux := http.NewServeMux()
+	for _, rt := range s.routes() {
+		handle(mux, rt.pattern, rt.perms, rt.handler)
+	}
 
-	// Public API (auth middleware will wrap)
-	mux.HandleFunc("/api/v1/me", s.handleMe)
-	mux.HandleFunc("/api/v1/features", s.handleFeatures)
-	mux.HandleFunc("/api/v1/features/", s.handleFeatureByID)
-	mux.HandleFunc("/api/v1/suggest", s.handleSuggest)
-
-	// Admin API (auth + admin middleware will wrap)
-	mux.HandleFunc("/admin/v1/clients", s.handleClients)
-	mux.HandleFunc("/admin/v1/features", s.handleAdminFeatures)
-	mux.HandleFunc("/admin/v1/features/seed", s.handleSeed)
+	// Unknown paths get a problem body like every other error
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		writeError(w, http.StatusNotFound, codeNotFound, "no such endpoint")
+	})
 
 	return mux
 }
 
+// route is an API endpoint: a ServeMux pattern, the methods it accepts with
+// the permission each requires, and its handler.
+type route struct {
+	pattern string
+	perms   methodPerms
+	handler http.HandlerFunc
+}
+
+// routes lists the API endpoints; openapi.json documents each of them.
+func (s *Server) routes() []route {
+	return []route{
+		// Public API
+		{"/api/v1/me", methodPerms{http.MethodGet: ""}, s.handleMe},
+		{"/api/v1/me/certificate", methodPerms{http.MethodPost: ""}, s.handleRenewCertificate},
+		{"/api/v1/features", methodPerms{http.MethodGet: store.PermFeaturesRead}, s.handleFeatures},
+		{"/api/v1/features/", methodPerms{http.MethodGet: store.PermFeaturesRead}, s.handleFeatureByID},
+		{"/api/v1/suggest", methodPerms{http.MethodGet: store.PermFeaturesRead}, s.handleSuggest},
+		{"/api/v1/events", methodPerms{http.MethodGet: store.PermFeaturesRead}, s.handleEvents},
+		{"/api/v1/openapi.json", methodPerms{http.MethodGet: ""}, s.handleOpenAPI},
+
+		// Admin API
+		{"/admin/v1/clients", methodPerms{
+			http.MethodGet:  store.PermClientsManage,
+			http.MethodPost: store.PermClientsManage,
+		}, s.handleClients},
+		{"/admin/v1/clients/", methodPerms{
+			http.MethodPatch:  store.PermClientsManage,
+			http.MethodPost:   store.PermClientsManage,
+			http.MethodDelete: store.PermClientsManage,
+		}, s.handleClientByFingerprint},
+		{"/admin/v1/certificates", methodPerms{http.MethodPost: store.PermClientsManage}, s.handleCertificates},
+		// Feature writes need at least features:write:own; the handlers check
+		// the owner when the client lacks features:write
+		{"/admin/v1/features", methodPerms{http.MethodPost: store.PermFeaturesWriteOwn}, s.handleAdminFeatures},
+		{"/admin/v1/features/", methodPerms{
+			http.MethodPut:    store.PermFeaturesWriteOwn,
+			http.MethodPatch:  store.PermFeaturesWriteOwn,
+			http.MethodDelete: store.PermFeaturesDelete,
+		}, s.handleAdminFeatureByID},
+		{"/admin/v1/features/seed", methodPerms{http.MethodPost: store.PermCatalogSeed}, s.handleSeed},
+		{"/admin/v1/audit", methodPerms{http.MethodGet: store.PermAuditRead}, s.handleAudit},
+	}
+}
+
 // HealthRoutes returns routes that don't require authentication.
 func (s *Server) HealthRoutes() http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", s.handleHealthz)
 	mux.HandleFunc("/readyz", s.handleReadyz)
+	if s.Metrics != nil {
+		mux.Handle("/metrics", s.Metrics.Handler())
+	}
 	return mux
 }
 
 // handleHealthz returns basic liveness status.
 func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w, http.MethodGet)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
@@ -53,20 +107,19 @@ func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
 // handleReadyz returns readiness status including feature count.
 func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w, http.MethodGet)
 		return
 	}
-	features := s.Store.SearchFeatures("", 1)
 	writeJSON(w, http.StatusOK, map[string]any{
 		"status":          "ok",
-		"features_loaded": len(features) > 0,
+		"features_loaded": s.Store.FeatureCount() > 0,
 	})
 }
 
 // handleMe returns information about the authenticated client.
 func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w, http.MethodGet)
 		return
 	}
 
@@ -76,11 +129,16 @@ func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
 	resp := map[string]any{
 		"name":        client.Name,
 		"role":        client.Role,
+		"permissions": client.Role.Permissions(),
+		"teams":       client.Teams,
 		"fingerprint": client.Fingerprint,
 		"subject":     "",
+		"matched_by":  r.Context().Value(ctxMatchKey),
 	}
 	if cert != nil {
 		resp["subject"] = cert.Subject.String()
+		// Differs from the client's fingerprint when an identity rule matched
+		resp["cert_fingerprint"] = store.FingerprintSHA256(cert)
 	}
 	writeJSON(w, http.StatusOK, resp)
 }
@@ -88,60 +146,122 @@ func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
 // handleFeatures handles feature search requests.
 func (s *Server) handleFeatures(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w, http.MethodGet)
 		return
 	}
 
+	// Larger pages are clamped; clients follow next_cursor for the rest
 	q := r.URL.Query().Get("query")
-	limit := atoiDefault(r.URL.Query().Get("limit"), 20)
+	limit := min(atoiDefault(r.URL.Query().Get("limit"), store.DefaultPageSize), store.MaxPageSize)
 
-	items := s.Store.SearchFeatures(q, limit)
-	writeJSON(w, http.StatusOK, map[string]any{
-		"items": items,
-		"count": len(items),
-	})
+	end := tracing.StoreCall(r.Context(), "SearchPage")
+	page, err := s.Store.SearchPage(q, limit, r.URL.Query().Get("cursor"))
+	end(err)
+	if err != nil {
+		// ErrInvalidQuery carries the parse error; show it to the user
+		if errors.Is(err, store.ErrInvalidQuery) {
+			writeError(w, http.StatusBadRequest, codeInvalidQuery, err.Error())
+			return
+		}
+		writeFieldError(w, "cursor", "invalid cursor; restart from the first page")
+		return
+	}
+	resp := map[string]any{
+		"items": page.Items,
+		"count": len(page.Items),
+	}
+	if page.NextCursor != "" {
+		resp["next_cursor"] = page.NextCursor
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 // handleFeatureByID handles requests for a specific feature by ID.
 func (s *Server) handleFeatureByID(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w, http.MethodGet)
 		return
 	}
 
 	id := strings.TrimPrefix(r.URL.Path, "/api/v1/features/")
 	if id == "" {
-		http.Error(w, "missing id", http.StatusBadRequest)
+		writeFieldError(w, "id", "required")
 		return
 	}
+	if id, ok := strings.CutSuffix(id, "/history"); ok {
+		s.handleFeatureHistory(w, r, id)
+		return
+	}
+
+	// ?at= reads the revision in effect at that time. It carries no ETag:
+	// a past version is not something to write against.
+	if at := r.URL.Query().Get("at"); at != "" {
+		t, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			writeFieldError(w, "at", "expected RFC 3339 time")
+			return
+		}
+		end := tracing.StoreCall(r.Context(), "FeatureAt")
+		f, ok := s.Store.FeatureAt(id, t)
+		end(nil)
+		if !ok {
+			writeError(w, http.StatusNotFound, codeFeatureNotFound, "feature not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, f)
+		return
+	}
+
+	end := tracing.StoreCall(r.Context(), "GetFeature")
 	f, ok := s.Store.GetFeature(id)
+	end(nil)
 	if !ok {
-		http.Error(w, "not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, codeFeatureNotFound, "feature not found")
 		return
 	}
+	w.Header().Set("ETag", etag(f.Version))
 	writeJSON(w, http.StatusOK, f)
 }
 
+// handleFeatureHistory returns every revision of a feature, oldest first.
+func (s *Server) handleFeatureHistory(w http.ResponseWriter, r *http.Request, id string) {
+	end := tracing.StoreCall(r.Context(), "FeatureHistory")
+	revs, ok := s.Store.FeatureHistory(id)
+	end(nil)
+	if !ok {
+		writeError(w, http.StatusNotFound, codeFeatureNotFound, "feature not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"items": revs,
+		"count": len(revs),
+	})
+}
+
 // handleSuggest handles autocomplete/suggestion requests.
 func (s *Server) handleSuggest(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w, http.MethodGet)
 		return
 	}
 
 	q := r.URL.Query().Get("query")
-	limit := atoiDefault(r.URL.Query().Get("limit"), 10)
+	limit := min(atoiDefault(r.URL.Query().Get("limit"), store.DefaultSuggestLimit), store.MaxSuggestLimit)
 
+	end := tracing.StoreCall(r.Context(), "Suggest")
 	items := s.Store.Suggest(q, limit)
+	end(nil)
 
 	type sugg struct {
-		ID      string `json:"id"`
-		Name    string `json:"name"`
-		Summary string `json:"summary"`
+		ID         string            `json:"id"`
+		Name       string            `json:"name"`
+		Summary    string            `json:"summary"`
+		Status     store.Status      `json:"status"`
+		Highlights []store.Highlight `json:"highlights,omitempty"`
 	}
 	out := make([]sugg, 0, len(items))
-	for _, f := range items {
-		out = append(out, sugg{ID: f.ID, Name: f.Name, Summary: f.Summary})
+	for _, it := range items {
+		out = append(out, sugg{ID: it.ID, Name: it.Name, Summary: it.Summary, Status: it.Status, Highlights: it.Highlights})
 	}
 	writeJSON(w, http.StatusOK, map[string]any{"items": out, "count": len(out)})
 }
@@ -149,13 +269,13 @@ func (s *Server) handleSuggest(w http.ResponseWriter, r *http.Request) {
 // handleAdminFeatures handles feature creation via admin API.
 func (s *Server) handleAdminFeatures(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w, http.MethodPost)
 		return
 	}
 
 	body, err := readAllLimit(r.Body, 1<<20)
 	if err != nil {
-		http.Error(w, "bad body", http.StatusBadRequest)
+		writeInvalidBody(w)
 		return
 	}
 
@@ -164,61 +284,249 @@ func (s *Server) handleAdminFeatures(w http.ResponseWriter, r *http.Request) {
 		Summary string   `json:"summary"`
 		Owner   string   `json:"owner"`
 		Tags    []string `json:"tags"`
+		Status  string   `json:"status"`
 	}
 	if unmarshalErr := json.Unmarshal(body, &req); unmarshalErr != nil {
-		http.Error(w, "bad json", http.StatusBadRequest)
+		writeInvalidBody(w)
+		return
+	}
+
+	nf, errs := store.ParseNewFeature(req.Name, req.Summary, req.Owner, req.Tags, req.Status)
+	if len(errs) > 0 {
+		invalidFields(errs...).write(w)
+		return
+	}
+	if !ClientFromContext(r.Context()).CanWriteFeature(nf.Owner) {
+		writeError(w, http.StatusForbidden, codeForbidden, errNotYourTeam)
+		return
+	}
+
+	end := tracing.StoreCall(r.Context(), "CreateFeatureWithStatus")
+	feature, err := s.Store.As(actor(r)).CreateFeature(nf.Name, nf.Summary, nf.Owner, nf.Tags, nf.Status)
+	end(err)
+	if err != nil {
+		if errors.Is(err, store.ErrIDSpaceExhausted) {
+			writeInternal(w, "feature ID space exhausted")
+			return
+		}
+		writeInternal(w, "failed to store feature")
 		return
 	}
+	writeJSON(w, http.StatusCreated, feature)
+}
 
-	req.Name = strings.TrimSpace(req.Name)
-	req.Summary = strings.TrimSpace(req.Summary)
-	req.Owner = strings.TrimSpace(req.Owner)
+// handleAdminFeatureByID handles updates (PUT/PATCH) and deletion of a single feature.
+// Writes must carry an If-Match header with the feature's ETag, and a stale
+// version is rejected with 409 Conflict; "*" writes whatever the version.
+func (s *Server) handleAdminFeatureByID(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/admin/v1/features/")
+	if id == "" {
+		writeFieldError(w, "id", "required")
+		return
+	}
 
-	if req.Name == "" || req.Summary == "" {
-		http.Error(w, "name and summary required", http.StatusBadRequest)
+	switch r.Method {
+	case http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		writeMethodNotAllowed(w, http.MethodDelete, http.MethodPatch, http.MethodPut)
 		return
 	}
 
-	// Field length limits
-	const maxNameLen, maxSummaryLen, maxOwnerLen = 200, 1000, 100
-	if len(req.Name) > maxNameLen {
-		http.Error(w, "name too long (max 200)", http.StatusBadRequest)
+	// A write without a version would silently undo whatever changed since
+	// the caller read the feature; overwriting has to be asked for with "*"
+	if strings.TrimSpace(r.Header.Get("If-Match")) == "" {
+		writeError(w, http.StatusPreconditionRequired, codePreconditionRequired,
+			`If-Match required: send the feature's ETag, or "*" to overwrite any version`)
 		return
 	}
-	if len(req.Summary) > maxSummaryLen {
-		http.Error(w, "summary too long (max 1000)", http.StatusBadRequest)
+	ifVersion, ok := parseIfMatch(r.Header.Get("If-Match"))
+	if !ok {
+		writeFieldError(w, "If-Match", "expected a single feature version ETag")
 		return
 	}
-	if len(req.Owner) > maxOwnerLen {
-		http.Error(w, "owner too long (max 100)", http.StatusBadRequest)
+
+	audited := s.Store.As(actor(r))
+	if r.Method == http.MethodDelete {
+		end := tracing.StoreCall(r.Context(), "DeleteFeature")
+		err := audited.DeleteFeature(id, ifVersion)
+		end(err)
+		if err != nil {
+			writeFeatureWriteError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
-	// Sanitize tags
-	var tags []string
-	for _, t := range req.Tags {
-		t = strings.TrimSpace(t)
-		if t != "" {
-			tags = append(tags, t)
+	upd, prob := decodeFeatureUpdate(r, r.Method == http.MethodPut)
+	if prob != nil {
+		prob.write(w)
+		return
+	}
+
+	// Team-scoped clients may only touch their own features, and may only
+	// hand them over to another of their teams
+	client := ClientFromContext(r.Context())
+	if before, found := s.Store.GetFeature(id); found && !client.Role.Can(store.PermFeaturesWrite) {
+		if !client.CanWriteFeature(before.Owner) || (upd.Owner != nil && !client.CanWriteFeature(*upd.Owner)) {
+			writeError(w, http.StatusForbidden, codeForbidden, errNotYourTeam)
+			return
+		}
+		// Pin the checked version so a concurrent owner change is a conflict
+		// even with "*"
+		if ifVersion == 0 {
+			ifVersion = before.Version
 		}
 	}
 
-	feature := s.Store.CreateFeature(req.Name, req.Summary, req.Owner, tags)
-	if feature.ID == "" {
-		http.Error(w, "feature ID space exhausted", http.StatusInternalServerError)
+	end := tracing.StoreCall(r.Context(), "UpdateFeature")
+	feature, err := audited.UpdateFeature(id, upd, ifVersion)
+	end(err)
+	if err != nil {
+		if errors.Is(err, store.ErrVersionConflict) {
+			w.Header().Set("ETag", etag(feature.Version))
+		}
+		writeFeatureWriteError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusCreated, feature)
+	w.Header().Set("ETag", etag(feature.Version))
+	writeJSON(w, http.StatusOK, feature)
+}
+
+// errNotYourTeam is the 403 message for team-scoped writes outside the client's teams.
+const errNotYourTeam = "permission denied: features:write:own only covers features owned by your teams"
+
+// decodeFeatureUpdate parses and validates an update body.
+// For full replacement (PUT) name and summary are required and omitted
+// owner/tags are cleared; for PATCH only the fields present are changed.
+// Status and replaced_by are lifecycle fields: they change only when present.
+// Returns the problem to answer with if the body is invalid.
+func decodeFeatureUpdate(r *http.Request, replace bool) (store.FeatureUpdate, *problem) {
+	body, err := readAllLimit(r.Body, 1<<20)
+	if err != nil {
+		return store.FeatureUpdate{}, newProblem(http.StatusBadRequest, codeInvalidBody, "request body is not valid JSON")
+	}
+
+	var req struct {
+		Name       *string   `json:"name"`
+		Summary    *string   `json:"summary"`
+		Owner      *string   `json:"owner"`
+		Tags       *[]string `json:"tags"`
+		Status     *string   `json:"status"`
+		ReplacedBy *string   `json:"replaced_by"`
+	}
+	if unmarshalErr := json.Unmarshal(body, &req); unmarshalErr != nil {
+		return store.FeatureUpdate{}, newProblem(http.StatusBadRequest, codeInvalidBody, "request body is not valid JSON")
+	}
+
+	var errs fieldErrors
+	if replace {
+		if req.Name == nil {
+			errs.add("name", "required")
+		}
+		if req.Summary == nil {
+			errs.add("summary", "required")
+		}
+		if len(errs) > 0 {
+			return store.FeatureUpdate{}, invalidFields(errs...)
+		}
+		if req.Owner == nil {
+			req.Owner = new(string)
+		}
+		if req.Tags == nil {
+			req.Tags = &[]string{}
+		}
+	}
+
+	upd := store.FeatureUpdate{}
+	var name, summary, owner string
+	if req.Name != nil {
+		name = strings.TrimSpace(*req.Name)
+		if name == "" {
+			errs.add("name", "cannot be empty")
+		}
+		upd.Name = &name
+	}
+	if req.Summary != nil {
+		summary = strings.TrimSpace(*req.Summary)
+		if summary == "" {
+			errs.add("summary", "cannot be empty")
+		}
+		upd.Summary = &summary
+	}
+	if req.Owner != nil {
+		owner = strings.TrimSpace(*req.Owner)
+		upd.Owner = &owner
+	}
+	errs = append(errs, store.CheckFeatureFields(name, summary, owner)...)
+	if req.Tags != nil {
+		tags := store.CleanTags(*req.Tags)
+		upd.Tags = &tags
+	}
+	if req.Status != nil {
+		st, ok := store.ParseStatus(*req.Status)
+		if !ok {
+			errs.add("status", "must be one of proposed, active, deprecated, removed")
+		}
+		upd.Status = &st
+	}
+	if req.ReplacedBy != nil {
+		replacedBy := strings.TrimSpace(*req.ReplacedBy)
+		upd.ReplacedBy = &replacedBy
+	}
+	if len(errs) > 0 {
+		return store.FeatureUpdate{}, invalidFields(errs...)
+	}
+	return upd, nil
 }
 
-// handleSeed handles requests to reseed the feature catalog.
+// writeFeatureWriteError maps store errors from feature mutations to HTTP responses.
+func writeFeatureWriteError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrFeatureNotFound):
+		writeError(w, http.StatusNotFound, codeFeatureNotFound, "feature not found")
+	case errors.Is(err, store.ErrVersionConflict):
+		writeError(w, http.StatusConflict, codeVersionConflict, "feature was modified since the version in If-Match")
+	case errors.Is(err, store.ErrInvalidStatus):
+		writeError(w, http.StatusConflict, codeInvalidTransition, err.Error())
+	case errors.Is(err, store.ErrInvalidReplacement):
+		writeFieldError(w, "replaced_by", strings.TrimPrefix(err.Error(), store.ErrInvalidReplacement.Error()+": "))
+	default:
+		writeInternal(w, "failed to store feature")
+	}
+}
+
+// defaultSeedCount is the catalog size a seed request without count gets.
+const defaultSeedCount = 200
+
+// handleSeed handles requests to reseed the feature catalog. The replaced
+// features stay in the history as deleted.
 func (s *Server) handleSeed(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	count := defaultSeedCount
+	if v := r.URL.Query().Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > store.MaxFeatureID {
+			writeFieldError(w, "count", "must be between 1 and "+strconv.Itoa(store.MaxFeatureID))
+			return
+		}
+		count = n
+	}
+	end := tracing.StoreCall(r.Context(), "SeedFeatures")
+	err := s.Store.As(actor(r)).SeedFeatures(count)
+	end(err)
+	if errors.Is(err, store.ErrInvalidSeedCount) {
+		// New features get IDs after every one used so far
+		writeFieldError(w, "count", "is more than the feature IDs left")
+		return
+	}
+	if err != nil {
+		writeInternal(w, "failed to store catalog")
 		return
 	}
-	count := atoiDefault(r.URL.Query().Get("count"), 200)
-	s.Store.SeedFeatures(count)
 	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "seeded": count})
 }
 
@@ -226,62 +534,224 @@ func (s *Server) handleSeed(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleClients(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
-		writeJSON(w, http.StatusOK, map[string]any{"items": s.Store.ListClients()})
+		end := tracing.StoreCall(r.Context(), "ListClients")
+		clients := s.Store.ListClients()
+		end(nil)
+		writeJSON(w, http.StatusOK, map[string]any{"items": clients})
 		return
 
 	case http.MethodPost:
 		body, err := readAllLimit(r.Body, 1<<20)
 		if err != nil {
-			http.Error(w, "bad body", http.StatusBadRequest)
+			writeInvalidBody(w)
 			return
 		}
 
 		var req struct {
-			Name    string `json:"name"`
-			Role    string `json:"role"`
-			CertPEM string `json:"cert_pem"`
+			Name    string   `json:"name"`
+			Role    string   `json:"role"`
+			Teams   []string `json:"teams"`
+			CertPEM string   `json:"cert_pem"`
 		}
 		if unmarshalErr := json.Unmarshal(body, &req); unmarshalErr != nil {
-			http.Error(w, "bad json", http.StatusBadRequest)
+			writeInvalidBody(w)
 			return
 		}
-		req.Name = strings.TrimSpace(req.Name)
-		if req.Name == "" || strings.TrimSpace(req.CertPEM) == "" {
-			http.Error(w, "name and cert_pem required", http.StatusBadRequest)
+		client, cert, errs := ParseRegistration(req.Name, req.Role, req.Teams, req.CertPEM)
+		if len(errs) > 0 {
+			invalidFields(errs...).write(w)
 			return
 		}
-
-		cert, err := parseCertPEM(req.CertPEM)
-		if err != nil {
-			http.Error(w, "invalid cert_pem", http.StatusBadRequest)
+		end := tracing.StoreCall(r.Context(), "RegisterClient")
+		_, _, err = s.Store.As(actor(r)).RegisterClient(client)
+		end(err)
+		switch {
+		case errors.Is(err, store.ErrClientRevoked):
+			// Revocation is permanent for a certificate; issue a new one
+			writeError(w, http.StatusConflict, codeCertificateRevoked, "certificate revoked; issue a new one")
+			return
+		case errors.Is(err, store.ErrClientManaged):
+			writeError(w, http.StatusConflict, codeClientManaged, store.ErrClientManaged.Error())
+			return
+		case err != nil:
+			writeInternal(w, "failed to store client")
 			return
 		}
 
-		role := store.RoleUser
-		if strings.ToLower(req.Role) == "admin" {
-			role = store.RoleAdmin
-		}
-
-		fp := store.FingerprintSHA256(cert)
-		s.Store.UpsertClient(store.Client{
-			Fingerprint: fp,
-			Name:        req.Name,
-			Role:        role,
-			CreatedAt:   time.Now(),
-		})
-
 		writeJSON(w, http.StatusCreated, map[string]any{
-			"fingerprint": fp,
-			"name":        req.Name,
-			"role":        role,
+			"fingerprint": client.Fingerprint,
+			"name":        client.Name,
+			"role":        client.Role,
+			"teams":       client.Teams,
 			"subject":     cert.Subject.String(),
 		})
 		return
 
 	default:
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+		return
+	}
+}
+
+// ParseRegistration checks a client registration as both APIs take it: name
+// and cert_pem are required, the certificate must parse, and the role must
+// be known (user if empty). It returns the client to register and its
+// certificate, or an error for each invalid field.
+func ParseRegistration(name, role string, teams []string, certPEM string) (store.Client, *x509.Certificate, []store.FieldError) {
+	name = strings.TrimSpace(name)
+	var errs fieldErrors
+	errs.require("name", name)
+	var cert *x509.Certificate
+	if strings.TrimSpace(certPEM) == "" {
+		errs.add("cert_pem", "required")
+	} else if c, err := parseCertPEM(certPEM); err != nil {
+		errs.add("cert_pem", "not a PEM certificate")
+	} else {
+		cert = c
+	}
+	r, roleErrs := store.CheckRole(role)
+	errs = append(errs, roleErrs...)
+	if len(errs) > 0 {
+		return store.Client{}, nil, errs
+	}
+	return store.Client{
+		Fingerprint: store.FingerprintSHA256(cert),
+		Name:        name,
+		Role:        r,
+		Teams:       store.NormalizeTeams(teams),
+		CreatedAt:   time.Now(),
+	}, cert, nil
+}
+
+// handleClientByFingerprint changes the role or teams of (PATCH .../{fingerprint}),
+// revokes (POST .../{fingerprint}/revoke) or deletes (DELETE .../{fingerprint})
+// a registered client. The fingerprint is the hex SHA-256 of the certificate;
+// colons and case are ignored.
+func (s *Server) handleClientByFingerprint(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/admin/v1/clients/")
+	fp, revoke := strings.CutSuffix(rest, "/revoke")
+	fp = store.NormalizeFingerprint(fp)
+	if fp == "" || strings.Contains(fp, "/") {
+		writeError(w, http.StatusNotFound, codeClientNotFound, "client not found")
+		return
+	}
+
+	if revoke && r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	if !revoke && r.Method == http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodDelete, http.MethodPatch)
+		return
+	}
+
+	// Locking yourself out is never what was meant
+	if fp == ClientFromContext(r.Context()).Fingerprint {
+		writeError(w, http.StatusConflict, codeSelfChange, "cannot change, revoke or delete your own certificate")
+		return
+	}
+
+	target, ok := s.Store.GetClient(fp)
+	if !ok {
+		writeError(w, http.StatusNotFound, codeClientNotFound, "client not found")
+		return
+	}
+	// Declared clients change in the clients file; revoking stays possible
+	// so a leaked certificate can be shut out before the file is updated
+	if target.Source != "" && !revoke {
+		writeError(w, http.StatusConflict, codeClientManaged, store.ErrClientManaged.Error())
 		return
 	}
+
+	if r.Method == http.MethodPatch {
+		body, err := readAllLimit(r.Body, 1<<20)
+		if err != nil {
+			writeInvalidBody(w)
+			return
+		}
+		var req struct {
+			Role  *string   `json:"role"`
+			Teams *[]string `json:"teams"`
+		}
+		if unmarshalErr := json.Unmarshal(body, &req); unmarshalErr != nil {
+			writeInvalidBody(w)
+			return
+		}
+		if req.Role == nil && req.Teams == nil {
+			writeError(w, http.StatusBadRequest, codeValidation, "role or teams required")
+			return
+		}
+		upd := store.ClientUpdate{Teams: req.Teams}
+		if req.Role != nil {
+			role, ok := store.ParseRole(*req.Role)
+			if !ok {
+				invalidFields(store.UnknownRole(*req.Role)).write(w)
+				return
+			}
+			upd.Role = &role
+		}
+		end := tracing.StoreCall(r.Context(), "UpdateClient")
+		client, err := s.Store.As(actor(r)).UpdateClient(fp, upd)
+		end(err)
+		if err != nil {
+			writeClientWriteError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, client)
+		return
+	}
+
+	if revoke {
+		end := tracing.StoreCall(r.Context(), "RevokeClient")
+		client, err := s.Store.As(actor(r)).RevokeClient(fp)
+		end(err)
+		if err != nil {
+			writeClientWriteError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, client)
+		return
+	}
+
+	end := tracing.StoreCall(r.Context(), "DeleteClient")
+	err := s.Store.As(actor(r)).DeleteClient(fp)
+	end(err)
+	if err != nil {
+		writeClientWriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeClientWriteError maps a client mutation error to an HTTP response.
+func writeClientWriteError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrClientNotFound) {
+		writeError(w, http.StatusNotFound, codeClientNotFound, "client not found")
+		return
+	}
+	writeInternal(w, "failed to store client")
+}
+
+// etag formats a feature version as a strong entity tag.
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch extracts the expected version from an If-Match header.
+// "*" yields 0 (unconditional). Returns false if the header is not a single
+// version ETag.
+func parseIfMatch(h string) (int64, bool) {
+	h = strings.TrimSpace(h)
+	if h == "*" {
+		return 0, true
+	}
+	h = strings.TrimPrefix(h, "W/")
+	h = strings.Trim(h, `"`)
+	v, err := strconv.ParseInt(h, 10, 64)
+	if err != nil || v <= 0 {
+		return 0, false
+	}
+	return v, true
 }
 
 // writeJSON writes a JSON response with the given status code.
@@ -0,0 +1,71 @@
+package httpapi
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JoobyPM/feature-atlas-service/internal/ca"
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+	"github.com/JoobyPM/feature-atlas-service/internal/testpki"
+)
+
+// testCA issues the package's test certificates.
+var testCA = testpki.NewCA("Test CA")
+
+// newTestCert returns a certificate from testCA for a fresh key, with the
+// given subject CN and SANs; "spiffe://" and "https://" SANs become URIs,
+// ones with "@" emails and the rest DNS names.
+func newTestCert(cn string, sans ...string) *x509.Certificate {
+	tmpl := &x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+	for _, san := range sans {
+		switch {
+		case strings.Contains(san, "://"):
+			u, err := url.Parse(san)
+			if err != nil {
+				panic(err)
+			}
+			tmpl.URIs = append(tmpl.URIs, u)
+		case strings.Contains(san, "@"):
+			tmpl.EmailAddresses = append(tmpl.EmailAddresses, san)
+		default:
+			tmpl.DNSNames = append(tmpl.DNSNames, san)
+		}
+	}
+	return testCA.Issue(tmpl).Leaf
+}
+
+// newTestSigner returns a CA signer for testCA, loaded from files in a
+// temporary directory.
+func newTestSigner(t *testing.T) *ca.Signer {
+	t.Helper()
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key")
+	if err := testpki.WriteFiles(testCA.Certificate, certFile, keyFile); err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ca.Load(certFile, keyFile, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("ca.Load: %v", err)
+	}
+	return signer
+}
+
+// registerTestClient registers a new certificate for name with role.
+func registerTestClient(t *testing.T, st *store.Store, name string, role store.Role) *x509.Certificate {
+	t.Helper()
+	cert := newTestCert(name)
+	if err := st.UpsertClient(store.Client{
+		Fingerprint: store.FingerprintSHA256(cert),
+		Name:        name,
+		Role:        role,
+		CreatedAt:   time.Now(),
+	}); err != nil {
+		t.Fatalf("UpsertClient: %v", err)
+	}
+	return cert
+}
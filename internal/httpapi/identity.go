@@ -0,0 +1,264 @@
+package httpapi
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+)
+
+// IdentityRules map client certificates that are not registered by
+// fingerprint to registered clients, by subject, SAN or issuing CA. This lets a
+// client rotate certificates without being registered again. Rules come from
+// a YAML file; Reload re-reads it. A nil *IdentityRules maps nothing. It is
+// safe for concurrent use.
+//
+// Example file:
+//
+//	rules:
+//	  - client: 7af17ab7...        # fingerprint of the registered client
+//	    match:
+//	      cn: alice
+//	  - client: 0c1d2e3f...
+//	    match:
+//	      san_uri: spiffe://example.org/ns/payments/*
+//	      issuer_fingerprint: 5d41402a...  # the intermediate that signs them
+type IdentityRules struct {
+	filename string
+
+	mu    sync.RWMutex
+	rules []IdentityRule
+}
+
+// IdentityRule maps certificates matching every field set in Match to the
+// registered client with fingerprint Client.
+type IdentityRule struct {
+	Client string        `yaml:"client"`
+	Match  IdentityMatch `yaml:"match"`
+}
+
+// IdentityMatch holds the patterns a certificate must match. Patterns use
+// path.Match syntax, so "*" matches within a URI path segment or DNS label
+// sequence without "/". SAN patterns match if any SAN of that kind does.
+// IssuerFingerprint is not a pattern: it is the SHA-256 fingerprint of the
+// CA certificate that issued the client certificate, which must be the one
+// above it in a chain verified by the TLS handshake. Unlike the issuer's
+// name, it cannot be copied by another CA the server trusts.
+type IdentityMatch struct {
+	CN                string `yaml:"cn,omitempty"`                 // subject common name
+	SANURI            string `yaml:"san_uri,omitempty"`            // e.g. a SPIFFE ID
+	SANDNS            string `yaml:"san_dns,omitempty"`            // DNS name
+	SANEmail          string `yaml:"san_email,omitempty"`          // email address
+	IssuerFingerprint string `yaml:"issuer_fingerprint,omitempty"` // hex SHA-256 of the issuing CA certificate
+}
+
+// patterns returns the non-empty patterns of m as "name=pattern" pairs,
+// in field order.
+func (m IdentityMatch) patterns() [][2]string {
+	var out [][2]string
+	for _, f := range [][2]string{
+		{"cn", m.CN}, {"san_uri", m.SANURI}, {"san_dns", m.SANDNS}, {"san_email", m.SANEmail},
+		{"issuer_fingerprint", m.IssuerFingerprint},
+	} {
+		if f[1] != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// matches reports whether cert, verified by chains, satisfies every pattern
+// of m.
+func (m IdentityMatch) matches(cert *x509.Certificate, chains [][]*x509.Certificate) bool {
+	if m.CN != "" && !globMatch(m.CN, cert.Subject.CommonName) {
+		return false
+	}
+	if m.SANURI != "" && !slicesMatch(m.SANURI, uriStrings(cert)) {
+		return false
+	}
+	if m.SANDNS != "" && !slicesMatch(m.SANDNS, cert.DNSNames) {
+		return false
+	}
+	if m.SANEmail != "" && !slicesMatch(m.SANEmail, cert.EmailAddresses) {
+		return false
+	}
+	if m.IssuerFingerprint != "" && !issuedBy(m.IssuerFingerprint, chains) {
+		return false
+	}
+	return true
+}
+
+// issuedBy reports whether any of the verified chains has the CA certificate
+// with fingerprint fp directly above the leaf.
+func issuedBy(fp string, chains [][]*x509.Certificate) bool {
+	for _, chain := range chains {
+		if len(chain) > 1 && store.FingerprintSHA256(chain[1]) == fp {
+			return true
+		}
+	}
+	return false
+}
+
+// String describes the rule's patterns for logs and /me.
+func (m IdentityMatch) String() string {
+	var parts []string
+	for _, f := range m.patterns() {
+		parts = append(parts, f[0]+"="+f[1])
+	}
+	return strings.Join(parts, " ")
+}
+
+// LoadIdentityRules reads the rules file at filename.
+func LoadIdentityRules(filename string) (*IdentityRules, error) {
+	r := &IdentityRules{filename: filename}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload re-reads the rules file. On error the previous rules stay in effect.
+func (r *IdentityRules) Reload() error {
+	//nolint:gosec // path is from trusted command-line flag
+	data, err := os.ReadFile(r.filename)
+	if err != nil {
+		return fmt.Errorf("read identity rules: %w", err)
+	}
+	var file struct {
+		Rules []IdentityRule `yaml:"rules"`
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse identity rules: %w", err)
+	}
+	for i := range file.Rules {
+		rule := &file.Rules[i]
+		rule.Client = store.NormalizeFingerprint(rule.Client)
+		if rule.Client == "" {
+			return fmt.Errorf("identity rule %d: client fingerprint is required", i+1)
+		}
+		patterns := rule.Match.patterns()
+		if len(patterns) == 0 {
+			// A rule without patterns would map every certificate
+			return fmt.Errorf("identity rule %d: match needs at least one of cn, san_uri, san_dns, san_email, issuer_fingerprint", i+1)
+		}
+		if rule.Match.IssuerFingerprint != "" {
+			rule.Match.IssuerFingerprint = store.NormalizeFingerprint(rule.Match.IssuerFingerprint)
+			if !isFingerprint(rule.Match.IssuerFingerprint) {
+				return fmt.Errorf("identity rule %d: issuer_fingerprint must be a hex SHA-256 fingerprint", i+1)
+			}
+		}
+		for _, f := range patterns {
+			if _, err := path.Match(f[1], ""); err != nil {
+				return fmt.Errorf("identity rule %d: bad %s pattern %q: %w", i+1, f[0], f[1], err)
+			}
+		}
+	}
+
+	r.mu.Lock()
+	r.rules = file.Rules
+	r.mu.Unlock()
+	return nil
+}
+
+// Len returns the number of rules.
+func (r *IdentityRules) Len() int {
+	if r == nil {
+		return 0
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.rules)
+}
+
+// Match returns the first rule cert matches, in file order. chains are the
+// certificate's chains as verified by the TLS handshake; rules with an
+// issuer_fingerprint match nothing without them.
+func (r *IdentityRules) Match(cert *x509.Certificate, chains [][]*x509.Certificate) (IdentityRule, bool) {
+	if r == nil {
+		return IdentityRule{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, rule := range r.rules {
+		if rule.Match.matches(cert, chains) {
+			return rule, true
+		}
+	}
+	return IdentityRule{}, false
+}
+
+// How resolveClient matched a certificate to a client, besides by rule.
+const (
+	matchFingerprint         = "fingerprint"          // registered with the certificate's fingerprint
+	matchPreviousFingerprint = "previous fingerprint" // replaced by a renewal whose certificate is not yet in use
+)
+
+// resolveClient finds the registered client cert acts as: the one registered
+// with its exact fingerprint (the strictest mapping, always tried first), the
+// one a pending renewal moved away from it, or else the client named by the
+// first matching identity rule. It also returns how the certificate was
+// matched.
+func resolveClient(s *store.Store, rules *IdentityRules, cert *x509.Certificate, chains [][]*x509.Certificate) (store.Client, string, bool) {
+	fp := store.FingerprintSHA256(cert)
+	if c, ok := s.GetClient(fp); ok {
+		return c, matchFingerprint, true
+	}
+	if c, ok := s.RenewedClient(fp); ok {
+		return c, matchPreviousFingerprint, true
+	}
+	rule, ok := rules.Match(cert, chains)
+	if !ok {
+		return store.Client{}, "", false
+	}
+	c, ok := s.GetClient(rule.Client)
+	if !ok {
+		return store.Client{}, "", false
+	}
+	return c, "rule: " + rule.Match.String(), true
+}
+
+// isFingerprint reports whether fp is a normalized hex SHA-256 fingerprint.
+func isFingerprint(fp string) bool {
+	if len(fp) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(fp)
+	return err == nil
+}
+
+// globMatch reports whether s matches pattern (validated on load).
+func globMatch(pattern, s string) bool {
+	ok, _ := path.Match(pattern, s)
+	return ok
+}
+
+// slicesMatch reports whether any of values matches pattern.
+func slicesMatch(pattern string, values []string) bool {
+	for _, v := range values {
+		if globMatch(pattern, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// uriStrings returns the URI SANs of cert as strings.
+func uriStrings(cert *x509.Certificate) []string {
+	out := make([]string, len(cert.URIs))
+	for i, u := range cert.URIs {
+		out[i] = u.String()
+	}
+	return out
+}
@@ -0,0 +1,190 @@
+package httpapi
+
+import (
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+)
+
+// loadTestRules writes yaml to a rules file and loads it.
+func loadTestRules(t *testing.T, yaml string) *IdentityRules {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(file, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadIdentityRules(file)
+	if err != nil {
+		t.Fatalf("LoadIdentityRules: %v", err)
+	}
+	return rules
+}
+
+func TestIdentityMatch(t *testing.T) {
+	cert := newTestCert("build-bot", "spiffe://example.org/ns/payments/sa/ci", "ci.example.org", "ci@example.org")
+	intermediate := newTestCert("CI Intermediate CA")
+	other := newTestCert("CI Intermediate CA") // same name, different certificate
+	chains := [][]*x509.Certificate{{cert, intermediate}}
+
+	tests := []struct {
+		name  string
+		match IdentityMatch
+		want  bool
+	}{
+		{"cn exact", IdentityMatch{CN: "build-bot"}, true},
+		{"cn glob", IdentityMatch{CN: "build-*"}, true},
+		{"cn mismatch", IdentityMatch{CN: "deploy-bot"}, false},
+		{"cn is not a substring match", IdentityMatch{CN: "build"}, false},
+		{"uri glob within segment", IdentityMatch{SANURI: "spiffe://example.org/ns/payments/sa/*"}, true},
+		{"uri glob does not cross /", IdentityMatch{SANURI: "spiffe://example.org/ns/*"}, false},
+		{"dns glob", IdentityMatch{SANDNS: "*.example.org"}, true},
+		{"email", IdentityMatch{SANEmail: "ci@example.org"}, true},
+		{"email mismatch", IdentityMatch{SANEmail: "ops@example.org"}, false},
+		{"issuer fingerprint", IdentityMatch{IssuerFingerprint: store.FingerprintSHA256(intermediate)}, true},
+		{"issuer with the same name", IdentityMatch{IssuerFingerprint: store.FingerprintSHA256(other)}, false},
+		{"issuer is not the leaf", IdentityMatch{IssuerFingerprint: store.FingerprintSHA256(cert)}, false},
+		{"every pattern must match", IdentityMatch{CN: "build-bot", SANDNS: "*.example.com"}, false},
+		{"all patterns match", IdentityMatch{CN: "build-bot", SANDNS: "ci.*", IssuerFingerprint: store.FingerprintSHA256(intermediate)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.match.matches(cert, chains); got != tt.want {
+				t.Errorf("%s matches = %v, want %v", tt.match, got, tt.want)
+			}
+		})
+	}
+
+	// Without a verified chain there is no issuer to match
+	if (IdentityMatch{IssuerFingerprint: store.FingerprintSHA256(intermediate)}).matches(cert, nil) {
+		t.Error("issuer_fingerprint matched without verified chains")
+	}
+}
+
+func TestIdentityRulesOrder(t *testing.T) {
+	rules := loadTestRules(t, `
+rules:
+  - client: AA:AA
+    match:
+      cn: build-bot
+  - client: bbbb
+    match:
+      cn: build-*
+  - client: cccc
+    match:
+      cn: "*"
+`)
+	if rules.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", rules.Len())
+	}
+	tests := []struct {
+		cn   string
+		want string
+	}{
+		{"build-bot", "aaaa"}, // the first match wins, with the fingerprint normalized
+		{"build-agent", "bbbb"},
+		{"deploy", "cccc"},
+	}
+	for _, tt := range tests {
+		rule, ok := rules.Match(newTestCert(tt.cn), nil)
+		if !ok || rule.Client != tt.want {
+			t.Errorf("Match(%s) = %q, %v; want %q", tt.cn, rule.Client, ok, tt.want)
+		}
+	}
+
+	var none *IdentityRules
+	if _, ok := none.Match(newTestCert("build-bot"), nil); ok || none.Len() != 0 {
+		t.Error("nil rules should match nothing")
+	}
+}
+
+func TestIdentityRulesReload(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		err  string
+	}{
+		{"no client", "rules:\n  - match:\n      cn: x\n", "client fingerprint is required"},
+		{"no patterns", "rules:\n  - client: aaaa\n    match: {}\n", "at least one of"},
+		{"empty pattern", "rules:\n  - client: aaaa\n    match:\n      cn: \"\"\n", "at least one of"},
+		{"bad glob", "rules:\n  - client: aaaa\n    match:\n      cn: \"[\"\n", "bad cn pattern"},
+		{"short issuer fingerprint", "rules:\n  - client: aaaa\n    match:\n      issuer_fingerprint: abcd\n", "issuer_fingerprint must be"},
+		{"issuer name", "rules:\n  - client: aaaa\n    match:\n      issuer: CN=CA\n", "field issuer not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := loadTestRules(t, "rules:\n  - client: ffff\n    match:\n      cn: kept\n")
+			if err := os.WriteFile(rules.filename, []byte(tt.yaml), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			err := rules.Reload()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("Reload = %v, want an error containing %q", err, tt.err)
+			}
+			// The previous rules stay in effect
+			if rule, ok := rules.Match(newTestCert("kept"), nil); !ok || rule.Client != "ffff" {
+				t.Errorf("after a failed reload Match = %+v, %v", rule, ok)
+			}
+		})
+	}
+}
+
+func TestResolveClient(t *testing.T) {
+	st := store.New()
+	mapped := registerTestClient(t, st, "mapped", store.RoleUser)
+	own := registerTestClient(t, st, "build-bot", store.RoleEditor)
+	rules := loadTestRules(t, "rules:\n  - client: "+store.FingerprintSHA256(mapped)+"\n    match:\n      cn: build-*\n")
+
+	tests := []struct {
+		name      string
+		cert      *x509.Certificate
+		wantName  string
+		wantMatch string
+	}{
+		// own's CN matches the rule too, but its registration comes first
+		{"registered fingerprint wins", own, "build-bot", "fingerprint"},
+		{"rule", newTestCert("build-agent"), "mapped", "rule: cn=build-*"},
+		{"no match", newTestCert("deploy"), "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, match, ok := resolveClient(st, rules, tt.cert, nil)
+			if ok != (tt.wantName != "") || c.Name != tt.wantName || match != tt.wantMatch {
+				t.Errorf("resolveClient = %q, %q, %v; want %q, %q", c.Name, match, ok, tt.wantName, tt.wantMatch)
+			}
+		})
+	}
+
+	// A rule naming a client that is gone maps nothing
+	if err := st.DeleteClient(store.FingerprintSHA256(mapped)); err != nil {
+		t.Fatalf("DeleteClient: %v", err)
+	}
+	if _, _, ok := resolveClient(st, rules, newTestCert("build-agent"), nil); ok {
+		t.Error("rule for a deleted client still resolves")
+	}
+}
+
+func TestAuthenticateRenewal(t *testing.T) {
+	st := store.New()
+	old := registerTestClient(t, st, "renewer", store.RoleUser)
+	renewed := newTestCert("renewer")
+	if _, err := st.RekeyClient(store.FingerprintSHA256(old), store.FingerprintSHA256(renewed)); err != nil {
+		t.Fatalf("RekeyClient: %v", err)
+	}
+
+	// The replaced certificate works until the new one is used
+	c, match, failure := Authenticate(st, nil, nil, old, nil)
+	if failure != "" || c.Name != "renewer" || match != matchPreviousFingerprint {
+		t.Fatalf("old certificate before confirmation: %q, %q, %q", c.Name, match, failure)
+	}
+	c, match, failure = Authenticate(st, nil, nil, renewed, nil)
+	if failure != "" || match != matchFingerprint || c.PreviousFingerprint != "" {
+		t.Fatalf("new certificate: %+v, %q, %q", c, match, failure)
+	}
+	if _, _, failure := Authenticate(st, nil, nil, old, nil); failure != AuthUnknownCertificate {
+		t.Errorf("old certificate after confirmation: failure %q, want %q", failure, AuthUnknownCertificate)
+	}
+}
@@ -0,0 +1,91 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JoobyPM/feature-atlas-service/internal/metrics"
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+)
+
+// unroutedLabel is the route label of requests refused before reaching a
+// route (authentication, rate limiting) or matching none.
+const unroutedLabel = "none"
+
+// Metrics counts API requests and authentication failures, and reports
+// catalog and client totals read from the store at scrape time.
+type Metrics struct {
+	requests     *metrics.CounterVec
+	duration     *metrics.HistogramVec
+	authFailures *metrics.CounterVec
+}
+
+// NewMetrics registers the API metrics and store gauges with reg.
+func NewMetrics(reg *metrics.Registry, st *store.Store) *Metrics {
+	m := &Metrics{
+		requests: reg.NewCounterVec("feature_atlas_http_requests_total",
+			"API requests by route pattern, method and status code.", "route", "method", "status"),
+		duration: reg.NewHistogramVec("feature_atlas_http_request_duration_seconds",
+			"API request latency by route pattern, method and status code.", nil, "route", "method", "status"),
+		authFailures: reg.NewCounterVec("feature_atlas_auth_failures_total",
+			"Requests refused by authentication or authorization, by reason.", "reason"),
+	}
+	reg.NewGaugeFunc("feature_atlas_features", "Features in the catalog.", func() float64 {
+		return float64(st.FeatureCount())
+	})
+	reg.NewGaugeFunc("feature_atlas_clients", "Registered clients, including revoked ones.", func() float64 {
+		total, _ := st.ClientCounts()
+		return float64(total)
+	})
+	reg.NewGaugeFunc("feature_atlas_clients_revoked", "Registered clients that are revoked.", func() float64 {
+		_, revoked := st.ClientCounts()
+		return float64(revoked)
+	})
+	return m
+}
+
+// Instrument returns middleware recording every request in m. It should
+// wrap MTLS, so refused requests are counted too; a nil m records nothing.
+func Instrument(m *Metrics, next http.Handler) http.Handler {
+	if m == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec, r := withRecord(r)
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		route := rec.route
+		if route == "" {
+			route = unroutedLabel
+		}
+		status := strconv.Itoa(sw.status)
+		method := methodLabel(r.Method)
+		m.requests.Inc(route, method, status)
+		m.duration.Observe(time.Since(start).Seconds(), route, method, status)
+		if rec.authFailure != "" {
+			m.authFailures.Inc(rec.authFailure)
+		}
+	})
+}
+
+// AuthFailure counts a call refused for reason, one of the Auth* reasons,
+// by a server other than the REST API's. A nil m records nothing.
+func (m *Metrics) AuthFailure(reason string) {
+	if m != nil {
+		m.authFailures.Inc(reason)
+	}
+}
+
+// methodLabel bounds the method label to the methods the API knows.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	default:
+		return "other"
+	}
+}
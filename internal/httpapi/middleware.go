@@ -7,60 +7,124 @@ import (
 	"encoding/pem"
 	"errors"
 	"io"
+	"maps"
 	"net/http"
-	"strings"
+	"slices"
 
 	"github.com/JoobyPM/feature-atlas-service/internal/store"
 )
 
 type ctxKey string
 
+// Reasons a request is refused, as counted by Instrument.
+const (
+	AuthNoCertificate      = "no_certificate"      // no client certificate presented
+	AuthUnknownCertificate = "unknown_certificate" // not registered and no identity rule matched
+	AuthRevokedClient      = "revoked_client"      // client revoked in the store
+	AuthRevokedCertificate = "revoked_certificate" // certificate on the CRL
+	AuthForbidden          = "forbidden"           // role lacks the route's permission
+)
+
 const (
 	ctxClientKey ctxKey = "client"
 	ctxCertKey   ctxKey = "cert"
+	ctxMatchKey  ctxKey = "match"
+
+	ctxRequestIDKey ctxKey = "request_id"
+	ctxRecordKey    ctxKey = "record"
 )
 
 // MTLS returns middleware that validates mTLS client certificates.
 // It extracts the client certificate from the TLS connection and looks up
-// the client in the store by fingerprint.
+// the client in the store by fingerprint, falling back to identity rules
+// (may be nil) for certificates not registered themselves. Certificates on
+// crl (may be nil) and clients marked revoked in the store are refused.
 // Security: Uses uniform error message to avoid leaking registration status.
-func MTLS(s *store.Store, next http.Handler) http.Handler {
+func MTLS(s *store.Store, crl *CRL, rules *IdentityRules, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := recordOf(r.Context())
 		// net/http sets Request.TLS for TLS-enabled connections.
 		if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
 			// Use generic message - don't reveal that cert was missing vs invalid
-			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			rec.authFailure = AuthNoCertificate
+			writeError(w, http.StatusUnauthorized, codeUnauthorized, "unauthorized")
 			return
 		}
 		// PeerCertificates are parsed certs sent by peer, leaf first.
 		cert := r.TLS.PeerCertificates[0]
-		fp := store.FingerprintSHA256(cert)
 
-		client, ok := s.GetClient(fp)
-		if !ok {
+		client, match, failure := Authenticate(s, crl, rules, cert, r.TLS.VerifiedChains)
+		rec.certFingerprint, rec.client, rec.authFailure = store.FingerprintSHA256(cert), client, failure
+		if failure != "" {
 			// Use same generic message - don't reveal that cert exists but isn't registered
-			// This prevents enumeration attacks on registered certificates
-			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			// (or was revoked). This prevents enumeration attacks on registered certificates
+			writeError(w, http.StatusUnauthorized, codeUnauthorized, "unauthorized")
 			return
 		}
 
 		ctx := context.WithValue(r.Context(), ctxClientKey, client)
 		ctx = context.WithValue(ctx, ctxCertKey, cert)
+		ctx = context.WithValue(ctx, ctxMatchKey, match)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-// AdminOnly returns middleware that restricts access to admin clients only.
+// Authenticate resolves the client cert acts as, as MTLS does for every
+// request, and how the certificate was matched. chains are the chains the
+// TLS handshake verified cert with. failure is the Auth* reason to refuse
+// the certificate for, or empty if it is accepted. The first use of a
+// renewed certificate retires the one it replaced.
+func Authenticate(s *store.Store, crl *CRL, rules *IdentityRules, cert *x509.Certificate, chains [][]*x509.Certificate) (client store.Client, matchedBy, failure string) {
+	client, matchedBy, ok := resolveClient(s, rules, cert, chains)
+	switch {
+	case !ok:
+		failure = AuthUnknownCertificate
+	case client.Revoked:
+		failure = AuthRevokedClient
+	case crl.IsRevoked(cert):
+		failure = AuthRevokedCertificate
+	case matchedBy == matchFingerprint && client.PreviousFingerprint != "":
+		// On failure the previous certificate keeps working until the next request
+		if confirmed, err := s.ConfirmRekey(client.Fingerprint); err == nil {
+			client = confirmed
+		}
+	}
+	return client, matchedBy, failure
+}
+
+// methodPerms maps the HTTP methods a route accepts to the permission each
+// requires. An empty permission admits any authenticated client.
+type methodPerms map[string]store.Permission
+
+// methods returns the methods p accepts, sorted.
+func (p methodPerms) methods() []string {
+	return slices.Sorted(maps.Keys(p))
+}
+
+// handle registers h for pattern behind a permission check, and notes the
+// pattern as the request's route for metrics.
+func handle(mux *http.ServeMux, pattern string, perms methodPerms, h http.HandlerFunc) {
+	next := requirePermission(perms, h)
+	mux.Handle(pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recordOf(r.Context()).route = pattern
+		next.ServeHTTP(w, r)
+	}))
+}
+
+// requirePermission returns middleware that admits a request only if the
+// client's role grants the permission declared for its method.
 // Must be used after MTLS middleware.
-func AdminOnly(next http.Handler) http.Handler {
+func requirePermission(perms methodPerms, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Only enforce admin check on /admin routes
-		if strings.HasPrefix(r.URL.Path, "/admin/") {
-			client := ClientFromContext(r.Context())
-			if client.Role != store.RoleAdmin {
-				http.Error(w, "admin only", http.StatusForbidden)
-				return
-			}
+		perm, ok := perms[r.Method]
+		if !ok {
+			writeMethodNotAllowed(w, perms.methods()...)
+			return
+		}
+		if perm != "" && !ClientFromContext(r.Context()).Role.Can(perm) {
+			recordOf(r.Context()).authFailure = AuthForbidden
+			writeError(w, http.StatusForbidden, codeForbidden, "permission denied: requires "+string(perm))
+			return
 		}
 		next.ServeHTTP(w, r)
 	})
@@ -0,0 +1,293 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+
+	"github.com/JoobyPM/feature-atlas-service/internal/ca"
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+	"github.com/JoobyPM/feature-atlas-service/internal/testpki"
+)
+
+func TestOpenAPISpecValid(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData(openAPISpec)
+	if err != nil {
+		t.Fatalf("load openapi.json: %v", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("openapi.json is not a valid OpenAPI 3 document: %v", err)
+	}
+}
+
+// TestOpenAPI calls every method of every route in Server.routes and checks
+// that the requests and responses match openapi.json, so the document cannot
+// drift from the handlers unnoticed.
+func TestOpenAPI(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData(openAPISpec)
+	if err != nil {
+		t.Fatalf("load openapi.json: %v", err)
+	}
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		t.Fatalf("router: %v", err)
+	}
+	openapi3filter.RegisterBodyDecoder(problemContentType, openapi3filter.JSONBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("text/event-stream", openapi3filter.FileBodyDecoder)
+
+	st := store.New()
+	if err := st.SeedFeatures(5); err != nil {
+		t.Fatalf("SeedFeatures: %v", err)
+	}
+	admin := registerTestClient(t, st, "admin", store.RoleAdmin)
+	user := registerTestClient(t, st, "user", store.RoleUser)
+	renewer := registerTestClient(t, st, "renewer", store.RoleUser)
+	victim := registerTestClient(t, st, "victim", store.RoleUser)
+	doomed := registerTestClient(t, st, "doomed", store.RoleUser)
+	newcomer := newTestCert("newcomer")
+	csr := testpki.CSR("csr")
+	s := &Server{Store: st, CA: newTestSigner(t)}
+
+	// Note the route each request reached, to check that all were exercised
+	covered := make(map[string]bool)
+	routes := s.Routes()
+	h := AccessLog(nil, MTLS(st, nil, nil, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routes.ServeHTTP(w, r)
+		if route := recordOf(r.Context()).route; route != "" {
+			covered[r.Method+" "+route] = true
+		}
+	})))
+
+	fp := store.FingerprintSHA256
+	tests := []struct {
+		name    string
+		cert    *x509.Certificate // client certificate; admin if nil
+		method  string
+		path    string
+		header  map[string]string
+		body    any
+		status  int
+		invalid bool // the request deliberately violates the document
+		stream  bool // the response is a stream; the request is cancelled up front to end it
+	}{
+		{name: "me", method: http.MethodGet, path: "/api/v1/me", status: http.StatusOK},
+		{name: "openapi", method: http.MethodGet, path: "/api/v1/openapi.json", status: http.StatusOK},
+		{name: "search", method: http.MethodGet, path: "/api/v1/features?limit=2", status: http.StatusOK},
+		{name: "search query", method: http.MethodGet, path: "/api/v1/features?query=status:active", status: http.StatusOK},
+		{name: "search no match", method: http.MethodGet, path: "/api/v1/features?query=zzzzzzzz", status: http.StatusOK},
+		{name: "search bad cursor", method: http.MethodGet, path: "/api/v1/features?cursor=bogus", status: http.StatusBadRequest},
+		{name: "get feature", method: http.MethodGet, path: "/api/v1/features/FT-000001", status: http.StatusOK},
+		{name: "get feature at", method: http.MethodGet, path: "/api/v1/features/FT-000001?at=" + time.Now().Add(time.Minute).UTC().Format(time.RFC3339), status: http.StatusOK},
+		{name: "get missing feature", method: http.MethodGet, path: "/api/v1/features/FT-999999", status: http.StatusNotFound},
+		{name: "suggest", method: http.MethodGet, path: "/api/v1/suggest?query=ft-0", status: http.StatusOK},
+		{name: "suggest oversized limit", method: http.MethodGet, path: "/api/v1/suggest?limit=2000000000", status: http.StatusOK},
+		{
+			name: "create feature", method: http.MethodPost, path: "/admin/v1/features",
+			body:   map[string]any{"name": "Dark mode", "summary": "Darker", "owner": "web", "tags": []string{"ui"}},
+			status: http.StatusCreated,
+		},
+		{
+			name: "create feature missing name", method: http.MethodPost, path: "/admin/v1/features",
+			body: map[string]any{"summary": "Darker"}, status: http.StatusBadRequest, invalid: true,
+		},
+		{
+			name: "create feature for another team", cert: user, method: http.MethodPost, path: "/admin/v1/features",
+			body: map[string]any{"name": "Theirs", "summary": "Not mine", "owner": "web"}, status: http.StatusForbidden,
+		},
+		{
+			name: "replace feature", method: http.MethodPut, path: "/admin/v1/features/FT-000002",
+			header: map[string]string{"If-Match": `"1"`},
+			body:   map[string]any{"name": "Renamed", "summary": "New summary", "status": "deprecated", "replaced_by": "FT-000003"},
+			status: http.StatusOK,
+		},
+		{
+			name: "update feature", method: http.MethodPatch, path: "/admin/v1/features/FT-000003",
+			header: map[string]string{"If-Match": `"1"`},
+			body:   map[string]any{"tags": []string{"a", "b"}}, status: http.StatusOK,
+		},
+		{
+			name: "update feature without If-Match", method: http.MethodPatch, path: "/admin/v1/features/FT-000003",
+			body: map[string]any{"summary": "Blind"}, status: http.StatusPreconditionRequired, invalid: true,
+		},
+		{
+			name: "update feature stale", method: http.MethodPatch, path: "/admin/v1/features/FT-000003",
+			header: map[string]string{"If-Match": `"1"`},
+			body:   map[string]any{"summary": "Too late"}, status: http.StatusConflict,
+		},
+		{name: "history", method: http.MethodGet, path: "/api/v1/features/FT-000003/history", status: http.StatusOK},
+		{
+			name: "delete feature", method: http.MethodDelete, path: "/admin/v1/features/FT-000004",
+			header: map[string]string{"If-Match": "*"}, status: http.StatusNoContent,
+		},
+		{name: "history of deleted", method: http.MethodGet, path: "/api/v1/features/FT-000004/history", status: http.StatusOK},
+		{name: "list clients", method: http.MethodGet, path: "/admin/v1/clients", status: http.StatusOK},
+		{name: "list clients as user", cert: user, method: http.MethodGet, path: "/admin/v1/clients", status: http.StatusForbidden},
+		{
+			name: "register client", method: http.MethodPost, path: "/admin/v1/clients",
+			body:   map[string]any{"name": "newcomer", "role": "editor", "cert_pem": string(ca.EncodePEM(newcomer))},
+			status: http.StatusCreated,
+		},
+		{
+			name: "register client bad role", method: http.MethodPost, path: "/admin/v1/clients",
+			body:   map[string]any{"name": "newcomer", "role": "root", "cert_pem": string(ca.EncodePEM(newcomer))},
+			status: http.StatusBadRequest, invalid: true,
+		},
+		{
+			name: "update client", method: http.MethodPatch, path: "/admin/v1/clients/" + fp(victim),
+			body: map[string]any{"role": "editor", "teams": []string{"web"}}, status: http.StatusOK,
+		},
+		{name: "revoke client", method: http.MethodPost, path: "/admin/v1/clients/" + fp(victim) + "/revoke", status: http.StatusOK},
+		{name: "delete client", method: http.MethodDelete, path: "/admin/v1/clients/" + fp(doomed), status: http.StatusNoContent},
+		{name: "delete self", method: http.MethodDelete, path: "/admin/v1/clients/" + fp(admin), status: http.StatusConflict},
+		{
+			name: "issue certificate", method: http.MethodPost, path: "/admin/v1/certificates",
+			body:   map[string]any{"name": "issued", "role": "user", "teams": []string{"web"}, "ttl": "1h", "csr_pem": csr},
+			status: http.StatusCreated,
+		},
+		{
+			name: "renew certificate", cert: renewer, method: http.MethodPost, path: "/api/v1/me/certificate",
+			body: map[string]any{"csr_pem": csr}, status: http.StatusCreated,
+		},
+		{
+			// The new certificate was never used, so the old one still works
+			name: "renew certificate again", cert: renewer, method: http.MethodPost, path: "/api/v1/me/certificate",
+			body: map[string]any{"csr_pem": csr}, status: http.StatusCreated,
+		},
+		{name: "audit", method: http.MethodGet, path: "/admin/v1/audit?action=feature.&limit=10", status: http.StatusOK},
+		{name: "seed", method: http.MethodPost, path: "/admin/v1/features/seed?count=3", status: http.StatusOK},
+		{name: "seed negative count", method: http.MethodPost, path: "/admin/v1/features/seed?count=-5", status: http.StatusBadRequest, invalid: true},
+		{name: "seed more than the IDs left", method: http.MethodPost, path: "/admin/v1/features/seed?count=999999", status: http.StatusBadRequest},
+		{name: "events", method: http.MethodGet, path: "/api/v1/events", status: http.StatusOK, stream: true},
+		{
+			name: "events resumed", method: http.MethodGet, path: "/api/v1/events",
+			header: map[string]string{"Last-Event-ID": "1"}, status: http.StatusOK, stream: true,
+		},
+		{
+			name: "events bad Last-Event-ID", method: http.MethodGet, path: "/api/v1/events",
+			header: map[string]string{"Last-Event-ID": "latest"}, status: http.StatusBadRequest, invalid: true,
+		},
+		{name: "unknown path", method: http.MethodGet, path: "/api/v1/nope", status: http.StatusNotFound, invalid: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var body []byte
+			if tc.body != nil {
+				var err error
+				if body, err = json.Marshal(tc.body); err != nil {
+					t.Fatal(err)
+				}
+			}
+			req := httptest.NewRequest(tc.method, tc.path, bytes.NewReader(body))
+			if body != nil {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			for k, v := range tc.header {
+				req.Header.Set(k, v)
+			}
+			cert := tc.cert
+			if cert == nil {
+				cert = admin
+			}
+			req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+			if tc.stream {
+				ctx, cancel := context.WithCancel(req.Context())
+				cancel()
+				req = req.WithContext(ctx)
+			}
+
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+			if rr.Code != tc.status {
+				t.Fatalf("status = %d, want %d; body: %s", rr.Code, tc.status, rr.Body)
+			}
+			if tc.invalid && tc.status == http.StatusNotFound {
+				return // not in the document at all
+			}
+
+			// The handler consumed the body; validation reads it again
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			route, params, err := router.FindRoute(req)
+			if err != nil {
+				t.Fatalf("%s %s is not in openapi.json: %v", tc.method, tc.path, err)
+			}
+			input := &openapi3filter.RequestValidationInput{Request: req, PathParams: params, Route: route}
+			if err := openapi3filter.ValidateRequest(context.Background(), input); err != nil && !tc.invalid {
+				t.Errorf("request does not match openapi.json: %v", err)
+			} else if err == nil && tc.invalid {
+				t.Errorf("openapi.json accepts a request the server rejects")
+			}
+			if err := openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 rr.Code,
+				Header:                 rr.Header(),
+				Body:                   io.NopCloser(bytes.NewReader(rr.Body.Bytes())),
+				Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+			}); err != nil {
+				t.Errorf("response does not match openapi.json: %v\nbody: %s", err, rr.Body)
+			}
+		})
+	}
+
+	for _, rt := range s.routes() {
+		for method := range rt.perms {
+			if !covered[method+" "+rt.pattern] {
+				t.Errorf("%s %s is not exercised", method, rt.pattern)
+			}
+		}
+	}
+	if t.Failed() {
+		t.Log("update openapi.json along with the handlers, and the cases here along with Server.routes")
+	}
+
+	// Other methods are refused with the route's methods in Allow
+	for _, rt := range s.routes() {
+		path := rt.pattern
+		if strings.HasSuffix(path, "/") {
+			path += "FT-000001"
+		}
+		req := httptest.NewRequest(http.MethodOptions, path, nil)
+		req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{admin}}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		want := strings.Join(slices.Sorted(maps.Keys(rt.perms)), ", ")
+		if rr.Code != http.StatusMethodNotAllowed || rr.Header().Get("Allow") != want {
+			t.Errorf("OPTIONS %s: status %d, Allow %q; want 405 allowing %q", path, rr.Code, rr.Header().Get("Allow"), want)
+		}
+	}
+}
+
+// TestOpenAPIServed checks the document is served as is.
+func TestOpenAPIServed(t *testing.T) {
+	st := store.New()
+	cert := registerTestClient(t, st, "reader", store.RoleUser)
+	h := MTLS(st, nil, nil, (&Server{Store: st}).Routes())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil)
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if !bytes.Equal(rr.Body.Bytes(), openAPISpec) {
+		t.Error("served document differs from openapi.json")
+	}
+}
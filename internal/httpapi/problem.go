@@ -0,0 +1,121 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+)
+
+// Error codes of problem responses. Clients branch on these rather than on
+// the message, which may change.
+const (
+	codeInvalidBody          = "invalid_body"      // unreadable or malformed JSON body
+	codeValidation           = "validation_failed" // see the field errors
+	codeInvalidQuery         = "invalid_query"     // search query syntax
+	codeUnauthorized         = "unauthorized"      // no or unknown certificate
+	codeForbidden            = "forbidden"         // role or team lacks the permission
+	codeNotFound             = "not_found"         // no such route
+	codeFeatureNotFound      = "feature_not_found"
+	codeClientNotFound       = "client_not_found"
+	codeMethodNotAllowed     = "method_not_allowed"
+	codeVersionConflict      = "version_conflict"      // If-Match names a stale version
+	codePreconditionRequired = "precondition_required" // feature write without If-Match
+	codeInvalidTransition    = "invalid_transition"    // status change the lifecycle forbids
+	codeClientManaged        = "client_managed"        // declared in the clients file
+	codeCertificateRevoked   = "certificate_revoked"   // revoked certificates stay revoked
+	codeSelfChange           = "self_change"           // callers may not change their own client
+	codeRuleMapped           = "rule_mapped"           // certificate belongs to an identity rule
+	codeClientGone           = "client_not_registered" // deleted or revoked mid-request
+	codeRateLimited          = "rate_limited"
+	codeIssuanceDisabled     = "issuance_disabled" // no CA key configured
+	codeInternal             = "internal"
+)
+
+// problemContentType is the media type of error responses (RFC 9457).
+const problemContentType = "application/problem+json"
+
+// problem is the body of every error response: RFC 9457 problem details
+// extended with a machine-readable code, per-field validation errors and
+// the request ID.
+type problem struct {
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Code      string       `json:"code"`
+	Detail    string       `json:"detail"`
+	Errors    []fieldError `json:"errors,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// fieldError explains why one request field, parameter or header was rejected.
+type fieldError = store.FieldError
+
+// fieldErrors collects the field errors of a request.
+type fieldErrors []fieldError
+
+// add records that field is invalid.
+func (e *fieldErrors) add(field, message string) {
+	*e = append(*e, fieldError{Field: field, Message: message})
+}
+
+// require records field as missing if value is blank.
+func (e *fieldErrors) require(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		e.add(field, "required")
+	}
+}
+
+// newProblem returns a problem with the given status, code and detail.
+func newProblem(status int, code, detail string) *problem {
+	return &problem{Title: http.StatusText(status), Status: status, Code: code, Detail: detail}
+}
+
+// invalidFields returns a 400 problem listing errs, which must not be empty.
+// The detail joins them, so clients that only show the message lose nothing.
+func invalidFields(errs ...fieldError) *problem {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Field + ": " + e.Message
+	}
+	p := newProblem(http.StatusBadRequest, codeValidation, strings.Join(msgs, "; "))
+	p.Errors = errs
+	return p
+}
+
+// write sends p, carrying the request ID AccessLog set on w.
+func (p *problem) write(w http.ResponseWriter) {
+	p.RequestID = w.Header().Get(RequestIDHeader)
+	w.Header().Set("Content-Type", problemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	//nolint:errchkjson // response writer errors handled by server
+	json.NewEncoder(w).Encode(p)
+}
+
+// writeError answers with a problem of the given status, code and detail.
+func writeError(w http.ResponseWriter, status int, code, detail string) {
+	newProblem(status, code, detail).write(w)
+}
+
+// writeFieldError answers 400 for a single invalid field.
+func writeFieldError(w http.ResponseWriter, field, message string) {
+	invalidFields(fieldError{Field: field, Message: message}).write(w)
+}
+
+// writeMethodNotAllowed answers 405, listing the allowed methods in the
+// Allow header.
+func writeMethodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+}
+
+// writeInvalidBody answers 400 for a body that cannot be read or decoded.
+func writeInvalidBody(w http.ResponseWriter) {
+	writeError(w, http.StatusBadRequest, codeInvalidBody, "request body is not valid JSON")
+}
+
+// writeInternal answers 500 with detail, which must not leak internals.
+func writeInternal(w http.ResponseWriter, detail string) {
+	writeError(w, http.StatusInternalServerError, codeInternal, detail)
+}
@@ -0,0 +1,151 @@
+package httpapi
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+)
+
+// RateLimit is a token bucket: Rate requests per second on average, with
+// bursts of up to Burst requests.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// rateUnits are the units ParseRateLimits accepts after the slash.
+var rateUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+}
+
+// ParseRateLimits parses per-role limits such as "user=10/s,editor=600/m:100".
+// Each entry is role=N/unit with unit s, m or h, and an optional :burst that
+// defaults to N. Roles without an entry are not limited.
+func ParseRateLimits(spec string) (map[store.Role]RateLimit, error) {
+	limits := make(map[store.Role]RateLimit)
+	for entry := range strings.SplitSeq(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q: expected role=N/unit", entry)
+		}
+		role, ok := store.ParseRole(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("%q: unknown role %q", entry, name)
+		}
+		value, burstText, hasBurst := strings.Cut(value, ":")
+		countText, unitText, ok := strings.Cut(value, "/")
+		unit, known := rateUnits[strings.TrimSpace(unitText)]
+		count, err := strconv.Atoi(strings.TrimSpace(countText))
+		if !ok || !known || err != nil || count <= 0 {
+			return nil, fmt.Errorf("%q: expected a positive count per s, m or h, such as 10/s", entry)
+		}
+		burst := count
+		if hasBurst {
+			if burst, err = strconv.Atoi(strings.TrimSpace(burstText)); err != nil || burst <= 0 {
+				return nil, fmt.Errorf("%q: burst must be a positive integer", entry)
+			}
+		}
+		limits[role] = RateLimit{Rate: float64(count) / unit.Seconds(), Burst: burst}
+	}
+	return limits, nil
+}
+
+// bucketSweepInterval is how often idle buckets are dropped.
+const bucketSweepInterval = time.Minute
+
+// RateLimiter keeps a token bucket per client, sized by the client's role.
+// It is safe for concurrent use.
+type RateLimiter struct {
+	limits map[store.Role]RateLimit
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a limiter applying limits per role; roles missing
+// from limits are not limited.
+func NewRateLimiter(limits map[store.Role]RateLimit) *RateLimiter {
+	return &RateLimiter{limits: limits, buckets: make(map[string]*bucket)}
+}
+
+// Allow takes a token from the bucket for key under role's limit at now. If
+// none is left it returns false and how long until one is.
+func (l *RateLimiter) Allow(key string, role store.Role, now time.Time) (bool, time.Duration) {
+	limit, ok := l.limits[role]
+	if !ok {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.lastSweep) >= bucketSweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+	// Refill for the time since the last request; clamping also applies a
+	// smaller burst at once when the client's role changed
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+}
+
+// sweep drops buckets idle long enough to have refilled completely, which
+// behave exactly like a fresh bucket. The caller holds l.mu.
+func (l *RateLimiter) sweep(now time.Time) {
+	l.lastSweep = now
+	var longest float64
+	for _, limit := range l.limits {
+		longest = math.Max(longest, float64(limit.Burst)/limit.Rate)
+	}
+	idle := time.Duration(longest * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= idle {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Throttle returns middleware that answers 429 Too Many Requests, with a
+// Retry-After in whole seconds, once the client has used up its bucket.
+// Must be used after MTLS middleware; a nil limiter admits everything.
+func Throttle(l *RateLimiter, next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := ClientFromContext(r.Context())
+		if ok, wait := l.Allow(client.Fingerprint, client.Role, time.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			writeError(w, http.StatusTooManyRequests, codeRateLimited, "rate limit exceeded")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,131 @@
+package httpapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+)
+
+func TestParseRateLimits(t *testing.T) {
+	limits, err := ParseRateLimits(" user=10/s, editor=600/m:100 ,,admin=36/h")
+	if err != nil {
+		t.Fatalf("ParseRateLimits: %v", err)
+	}
+	want := map[store.Role]RateLimit{
+		store.RoleUser:   {Rate: 10, Burst: 10},
+		store.RoleEditor: {Rate: 10, Burst: 100},
+		store.RoleAdmin:  {Rate: 0.01, Burst: 36},
+	}
+	if len(limits) != len(want) {
+		t.Errorf("got %d limits, want %d", len(limits), len(want))
+	}
+	for role, w := range want {
+		if got := limits[role]; got != w {
+			t.Errorf("limit for %s = %+v, want %+v", role, got, w)
+		}
+	}
+
+	if limits, err := ParseRateLimits(""); err != nil || len(limits) != 0 {
+		t.Errorf("ParseRateLimits(\"\") = %v, %v; want no limits", limits, err)
+	}
+
+	for _, spec := range []string{
+		"user",
+		"guest=10/s",
+		"user=10",
+		"user=10/d",
+		"user=0/s",
+		"user=-1/s",
+		"user=x/s",
+		"user=10/s:0",
+		"user=10/s:x",
+	} {
+		if _, err := ParseRateLimits(spec); err == nil {
+			t.Errorf("ParseRateLimits(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestRateLimiterAllow(t *testing.T) {
+	l := NewRateLimiter(map[store.Role]RateLimit{store.RoleUser: {Rate: 2, Burst: 3}})
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// A burst, then a wait until the next token
+	for i := range 3 {
+		if ok, _ := l.Allow("a", store.RoleUser, now); !ok {
+			t.Fatalf("request %d of the burst refused", i+1)
+		}
+	}
+	if ok, wait := l.Allow("a", store.RoleUser, now); ok || wait != 500*time.Millisecond {
+		t.Errorf("past the burst: %v, %v; want refused for 500ms", ok, wait)
+	}
+	if ok, wait := l.Allow("a", store.RoleUser, now.Add(250*time.Millisecond)); ok || wait != 250*time.Millisecond {
+		t.Errorf("halfway to a token: %v, %v; want refused for 250ms", ok, wait)
+	}
+	if ok, _ := l.Allow("a", store.RoleUser, now.Add(500*time.Millisecond)); !ok {
+		t.Error("refused once a token was refilled")
+	}
+
+	// Buckets are per key, and refill no further than the burst
+	if ok, _ := l.Allow("b", store.RoleUser, now); !ok {
+		t.Error("another key shares a's bucket")
+	}
+	later := now.Add(time.Hour)
+	for i := range 3 {
+		if ok, _ := l.Allow("a", store.RoleUser, later); !ok {
+			t.Fatalf("request %d after an hour refused", i+1)
+		}
+	}
+	if ok, _ := l.Allow("a", store.RoleUser, later); ok {
+		t.Error("bucket refilled past its burst")
+	}
+
+	// Roles without a limit are never refused
+	for range 100 {
+		if ok, _ := l.Allow("c", store.RoleAdmin, now); !ok {
+			t.Fatal("unlimited role refused")
+		}
+	}
+
+	// Idle buckets are swept once they would be full again
+	l.Allow("a", store.RoleUser, later.Add(bucketSweepInterval))
+	l.mu.Lock()
+	_, hasB := l.buckets["b"]
+	n := len(l.buckets)
+	l.mu.Unlock()
+	if hasB || n != 1 {
+		t.Errorf("after a sweep %d buckets remain, b among them: %v", n, hasB)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	st := store.New()
+	user := registerTestClient(t, st, "user", store.RoleUser)
+	l := NewRateLimiter(map[store.Role]RateLimit{store.RoleUser: {Rate: 0.1, Burst: 1}})
+	h := MTLS(st, nil, nil, Throttle(l, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+	get := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/features", nil)
+		r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{user}}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := get(); w.Code != http.StatusNoContent {
+		t.Fatalf("first request: status %d", w.Code)
+	}
+	w := get()
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "10" ||
+		!strings.Contains(w.Body.String(), codeRateLimited) {
+		t.Errorf("second request: status %d, Retry-After %q, body %s; want 429 after 10s",
+			w.Code, w.Header().Get("Retry-After"), w.Body)
+	}
+}
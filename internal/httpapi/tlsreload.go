@@ -0,0 +1,141 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLSReloader serves the server certificate and client CA bundle from files
+// that can be replaced while the service runs. Reload re-reads them; TLS
+// handshakes started afterwards use the new material, while established
+// connections and the store are untouched. It is safe for concurrent use.
+type TLSReloader struct {
+	certFile, keyFile, caFile string
+
+	mu      sync.RWMutex
+	config  *tls.Config
+	caCerts []*x509.Certificate
+}
+
+// LoadTLS reads the server key pair and the PEM client CA bundle.
+func LoadTLS(certFile, keyFile, caFile string) (*TLSReloader, error) {
+	t := &TLSReloader{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	if err := t.Reload(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Reload re-reads the key pair and CA bundle. On error the previous
+// material stays in effect.
+func (t *TLSReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(t.certFile, t.keyFile)
+	if err != nil {
+		return fmt.Errorf("load server key pair: %w", err)
+	}
+	//nolint:gosec // path is from trusted command-line flag
+	caPEM, err := os.ReadFile(t.caFile)
+	if err != nil {
+		return fmt.Errorf("read client CA: %w", err)
+	}
+	caCerts, err := ParseCertsPEM(caPEM)
+	if err != nil {
+		return fmt.Errorf("parse client CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	for _, c := range caCerts {
+		pool.AddCert(c)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		// RequireAndVerifyClientCert requires a valid client cert signed by ClientCAs.
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  pool,
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+
+	t.mu.Lock()
+	t.config = config
+	t.caCerts = caCerts
+	t.mu.Unlock()
+	return nil
+}
+
+// Config returns the tls.Config to serve with. It resolves every handshake
+// against the material loaded last, so it never needs replacing.
+func (t *TLSReloader) Config() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			t.mu.RLock()
+			defer t.mu.RUnlock()
+			return t.config, nil
+		},
+	}
+}
+
+// ClientCACerts returns the client CA certificates loaded last.
+func (t *TLSReloader) ClientCACerts() []*x509.Certificate {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.caCerts
+}
+
+// ServerCertificate returns the parsed server certificate loaded last.
+func (t *TLSReloader) ServerCertificate() *x509.Certificate {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.config.Certificates[0].Leaf
+}
+
+// WatchFiles polls paths every interval and calls onChange once per round in
+// which any of them was modified, replaced or removed, until ctx is done.
+// Empty paths are ignored. Polling needs no platform support and catches
+// files swapped by rename, as secret mounts and certificate tools do.
+func WatchFiles(ctx context.Context, interval time.Duration, paths []string, onChange func()) {
+	type stamp struct {
+		modTime time.Time
+		size    int64
+		exists  bool
+	}
+	stat := func(p string) stamp {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return stamp{} // missing or unreadable; a later write shows up as a change
+		}
+		return stamp{modTime: fi.ModTime(), size: fi.Size(), exists: true}
+	}
+
+	seen := make(map[string]stamp, len(paths))
+	for _, p := range paths {
+		if p != "" {
+			seen[p] = stat(p)
+		}
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		changed := false
+		for p, old := range seen {
+			if cur := stat(p); cur != old {
+				seen[p] = cur
+				changed = true
+			}
+		}
+		if changed {
+			onChange()
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/JoobyPM/feature-atlas-service/internal/testpki"
+)
+
+// testTLSFiles are the server key pair and client CA files a TLSReloader
+// reads, in a temporary directory.
+type testTLSFiles struct {
+	cert, key, ca string
+}
+
+func newTestTLSFiles(t *testing.T) testTLSFiles {
+	t.Helper()
+	dir := t.TempDir()
+	return testTLSFiles{
+		cert: filepath.Join(dir, "server.crt"),
+		key:  filepath.Join(dir, "server.key"),
+		ca:   filepath.Join(dir, "ca.crt"),
+	}
+}
+
+// serverCert returns a certificate from c for localhost with the subject
+// CN cn, so tests can tell servers apart.
+func serverCert(c *testpki.CA, cn string) tls.Certificate {
+	return c.Issue(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: cn},
+		DNSNames:    []string{"localhost"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+}
+
+// write replaces the files with server's key pair and the CA bundle cas.
+func (f testTLSFiles) write(t *testing.T, server tls.Certificate, cas ...*testpki.CA) {
+	t.Helper()
+	if err := testpki.WriteFiles(server, f.cert, f.key); err != nil {
+		t.Fatal(err)
+	}
+	var bundle []byte
+	for _, c := range cas {
+		bundle = append(bundle, testpki.CertPEM(c.Certificate)...)
+	}
+	if err := os.WriteFile(f.ca, bundle, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTLSReloaderReload(t *testing.T) {
+	files := newTestTLSFiles(t)
+	ca1, ca2 := testpki.NewCA("CA 1"), testpki.NewCA("CA 2")
+	server1 := serverCert(ca1, "server 1")
+	files.write(t, server1, ca1)
+
+	r, err := LoadTLS(files.cert, files.key, files.ca)
+	if err != nil {
+		t.Fatalf("LoadTLS: %v", err)
+	}
+	if !r.ServerCertificate().Equal(server1.Leaf) || len(r.ClientCACerts()) != 1 {
+		t.Fatalf("loaded %s with %d CAs", r.ServerCertificate().Subject, len(r.ClientCACerts()))
+	}
+
+	// Broken material is reported and the previous material stays in effect
+	server2 := serverCert(ca2, "server 2")
+	tests := []struct {
+		name  string
+		file  string
+		data  []byte
+		setup func()
+	}{
+		{name: "garbage certificate", file: files.cert, data: []byte("not a certificate")},
+		{name: "key of another certificate", file: files.cert, data: testpki.CertPEM(server2)},
+		{name: "garbage CA bundle", file: files.ca, data: []byte("-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n")},
+		{name: "missing CA bundle", setup: func() { _ = os.Remove(files.ca) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files.write(t, server1, ca1)
+			if tt.setup != nil {
+				tt.setup()
+			} else if err := os.WriteFile(tt.file, tt.data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Reload(); err == nil {
+				t.Fatal("Reload succeeded")
+			}
+			if !r.ServerCertificate().Equal(server1.Leaf) {
+				t.Errorf("serving %s after a failed reload", r.ServerCertificate().Subject)
+			}
+			if cas := r.ClientCACerts(); len(cas) != 1 || !cas[0].Equal(ca1.Leaf) {
+				t.Errorf("client CAs changed after a failed reload")
+			}
+		})
+	}
+
+	files.write(t, server2, ca1, ca2)
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if !r.ServerCertificate().Equal(server2.Leaf) || len(r.ClientCACerts()) != 2 {
+		t.Errorf("after reload serving %s with %d CAs", r.ServerCertificate().Subject, len(r.ClientCACerts()))
+	}
+}
+
+func TestTLSReloaderHandshake(t *testing.T) {
+	files := newTestTLSFiles(t)
+	ca1, ca2 := testpki.NewCA("CA 1"), testpki.NewCA("CA 2")
+	files.write(t, serverCert(ca1, "server 1"), ca1)
+	r, err := LoadTLS(files.cert, files.key, files.ca)
+	if err != nil {
+		t.Fatalf("LoadTLS: %v", err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	go func() {
+		tl := tls.NewListener(lis, r.Config())
+		for {
+			conn, err := tl.Accept()
+			if err != nil {
+				return
+			}
+			// Answer a completed handshake; a refused one gets an alert
+			if conn.(*tls.Conn).Handshake() == nil {
+				_, _ = conn.Write([]byte("k"))
+			}
+			conn.Close()
+		}
+	}()
+
+	// dial connects with client, trusting roots, and returns the server's
+	// certificate, or an error if either side refused the other.
+	dial := func(client tls.Certificate, roots ...*testpki.CA) (*x509.Certificate, error) {
+		pool := x509.NewCertPool()
+		for _, c := range roots {
+			pool.AddCert(c.Leaf)
+		}
+		conn, err := tls.Dial("tcp", lis.Addr().String(), &tls.Config{
+			Certificates: []tls.Certificate{client},
+			RootCAs:      pool,
+			ServerName:   "localhost",
+			MinVersion:   tls.VersionTLS12,
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+		// With TLS 1.3 the server checks the client certificate after the
+		// client's handshake completes, so its verdict arrives with the reply
+		if _, err := conn.Read(make([]byte, 1)); err != nil {
+			return nil, err
+		}
+		return conn.ConnectionState().PeerCertificates[0], nil
+	}
+
+	client1, client2 := ca1.Client("client 1"), ca2.Client("client 2")
+	if got, err := dial(client1, ca1); err != nil || got.Subject.CommonName != "server 1" {
+		t.Fatalf("before rotation: %v, %v", got, err)
+	}
+	if _, err := dial(client2, ca1); err == nil {
+		t.Fatal("client of an unknown CA accepted")
+	}
+
+	// Rotate both the server certificate and the client CA
+	files.write(t, serverCert(ca2, "server 2"), ca2)
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got, err := dial(client2, ca2); err != nil || got.Subject.CommonName != "server 2" {
+		t.Errorf("after rotation: %v, %v", got, err)
+	}
+	if _, err := dial(client1, ca1, ca2); err == nil {
+		t.Error("client of the removed CA still accepted")
+	}
+}
+
+func TestWatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	for _, p := range []string{a, b} {
+		if err := os.WriteFile(p, []byte("1"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan struct{}, 10)
+	done := make(chan struct{})
+	go func() {
+		WatchFiles(ctx, 5*time.Millisecond, []string{a, "", b}, func() { changes <- struct{}{} })
+		close(done)
+	}()
+	// Let the first poll record the files
+	time.Sleep(20 * time.Millisecond)
+
+	expect := func(what string, change bool) {
+		t.Helper()
+		select {
+		case <-changes:
+			if !change {
+				t.Errorf("%s: unexpected change", what)
+			}
+		case <-time.After(200 * time.Millisecond):
+			if change {
+				t.Errorf("%s: no change reported", what)
+			}
+		}
+	}
+
+	expect("untouched files", false)
+	if err := os.WriteFile(a, []byte("22"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	expect("rewritten file", true)
+
+	// Replaced by rename, as secret mounts and certificate tools do
+	tmp := filepath.Join(dir, "b.tmp")
+	if err := os.WriteFile(tmp, []byte("333"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, b); err != nil {
+		t.Fatal(err)
+	}
+	expect("replaced file", true)
+
+	if err := os.Remove(a); err != nil {
+		t.Fatal(err)
+	}
+	expect("removed file", true)
+	expect("nothing more", false)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("WatchFiles did not return after ctx was done")
+	}
+}
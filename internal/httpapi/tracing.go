@@ -0,0 +1,55 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the service's spans; without a configured provider it is a no-op.
+var tracer = otel.Tracer("github.com/JoobyPM/feature-atlas-service/internal/httpapi")
+
+// Trace returns middleware that wraps each request in a server span, joining
+// the caller's trace when the request carries W3C trace-context headers. The
+// span is named after the route that served the request and records the
+// status, client and request ID. It should be outermost, so the span covers
+// authentication, rate limiting and logging.
+func Trace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPRequestMethodKey.String(r.Method), semconv.URLPath(r.URL.Path)))
+		defer span.End()
+
+		rec, r := withRecord(r.WithContext(ctx))
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		if rec.route != "" {
+			span.SetName(r.Method + " " + rec.route)
+			span.SetAttributes(semconv.HTTPRoute(rec.route))
+		}
+		span.SetAttributes(
+			semconv.HTTPResponseStatusCode(sw.status),
+			attribute.String("feature_atlas.request_id", sw.Header().Get(RequestIDHeader)),
+		)
+		if rec.certFingerprint != "" {
+			span.SetAttributes(
+				attribute.String("feature_atlas.client.name", rec.client.Name),
+				attribute.String("feature_atlas.client.fingerprint", rec.certFingerprint),
+			)
+		}
+		if rec.authFailure != "" {
+			span.SetAttributes(attribute.String("feature_atlas.auth_failure", rec.authFailure))
+		}
+		if sw.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(sw.status))
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package httpapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/JoobyPM/feature-atlas-service/internal/store"
+)
+
+func TestTraceStoreSpans(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	defer otel.SetTracerProvider(prev)
+
+	st := store.New()
+	editor := registerTestClient(t, st, "editor", store.RoleEditor)
+	h := Trace(MTLS(st, nil, nil, (&Server{Store: st}).Routes()))
+
+	// do sends a request as editor and returns the store span it caused,
+	// checking that it belongs to the request's span.
+	do := func(method, path, body string, status int, storeOp string) sdktrace.ReadOnlySpan {
+		t.Helper()
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{editor}}
+		if method == http.MethodPatch {
+			r.Header.Set("If-Match", "*")
+		}
+		w := httptest.NewRecorder()
+		start := len(rec.Ended())
+		h.ServeHTTP(w, r)
+		if w.Code != status {
+			t.Fatalf("%s %s: status %d, want %d", method, path, w.Code, status)
+		}
+		spans := rec.Ended()[start:]
+		if len(spans) != 2 || spans[0].Name() != "store."+storeOp ||
+			spans[0].Parent().SpanID() != spans[1].SpanContext().SpanID() {
+			names := make([]string, len(spans))
+			for i, s := range spans {
+				names[i] = s.Name()
+			}
+			t.Fatalf("%s %s: spans %v, want store.%s within the request's span", method, path, names, storeOp)
+		}
+		return spans[0]
+	}
+
+	write := do(http.MethodPost, "/admin/v1/features", `{"name":"Dark mode","summary":"Darker","owner":"web"}`,
+		http.StatusCreated, "CreateFeatureWithStatus")
+	read := do(http.MethodGet, "/api/v1/features/FT-000001", "", http.StatusOK, "GetFeature")
+	for _, s := range []sdktrace.ReadOnlySpan{write, read} {
+		if s.Status().Code == codes.Error {
+			t.Errorf("%s: status %+v, want no error", s.Name(), s.Status())
+		}
+	}
+
+	failed := do(http.MethodPatch, "/admin/v1/features/FT-000042", `{"summary":"Gone"}`, http.StatusNotFound, "UpdateFeature")
+	if failed.Status().Code != codes.Error || len(failed.Events()) != 1 || failed.Events()[0].Name != "exception" {
+		t.Errorf("failed write: status %+v, events %+v; want the error recorded", failed.Status(), failed.Events())
+	}
+}
@@ -38,14 +38,17 @@ var (
 )
 
 // Entry represents a feature in the manifest with sync metadata.
+// Status and ReplacedBy mirror the server lifecycle; an empty status means active.
 type Entry struct {
-	Name     string   `yaml:"name"`
-	Summary  string   `yaml:"summary"`
-	Owner    string   `yaml:"owner,omitempty"`
-	Tags     []string `yaml:"tags,omitempty"`
-	Synced   bool     `yaml:"synced"`
-	SyncedAt string   `yaml:"synced_at,omitempty"` // RFC3339 timestamp
-	Alias    string   `yaml:"alias,omitempty"`     // Original local ID after sync
+	Name       string   `yaml:"name"`
+	Summary    string   `yaml:"summary"`
+	Owner      string   `yaml:"owner,omitempty"`
+	Tags       []string `yaml:"tags,omitempty"`
+	Status     string   `yaml:"status,omitempty"`      // proposed, active, deprecated, removed
+	ReplacedBy string   `yaml:"replaced_by,omitempty"` // Successor of a deprecated/removed feature
+	Synced     bool     `yaml:"synced"`
+	SyncedAt   string   `yaml:"synced_at,omitempty"` // RFC3339 timestamp
+	Alias      string   `yaml:"alias,omitempty"`     // Original local ID after sync
 }
 
 // Manifest represents the local feature catalog file.
@@ -281,6 +284,7 @@ type Feature struct {
 	Summary  string
 	Owner    string
 	Tags     []string
+	Status   string
 	IsSynced bool // True if feature exists on server (has FT-NNNNNN ID)
 }
 
@@ -314,6 +318,7 @@ func (m *Manifest) AddSyncedFeature(f Feature) error {
 		Summary:  f.Summary,
 		Owner:    f.Owner,
 		Tags:     f.Tags,
+		Status:   f.Status,
 		Synced:   f.IsSynced,
 		SyncedAt: syncedAt,
 	}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("app_requests_total", "Requests handled.", "route", "status")
+	latency := r.NewHistogramVec("app_latency_seconds", "Request latency.", []float64{0.1, 1}, "route")
+	r.NewGaugeFunc("app_items", "Items stored.", func() float64 { return 42 })
+
+	requests.Inc("/b", "200")
+	requests.Inc("/a", "500")
+	requests.Add(2, "/a", "500")
+	latency.Observe(0.05, "/a")
+	latency.Observe(0.1, "/a") // bounds are inclusive
+	latency.Observe(3, "/a")
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	want := `# HELP app_requests_total Requests handled.
+# TYPE app_requests_total counter
+app_requests_total{route="/a",status="500"} 3
+app_requests_total{route="/b",status="200"} 1
+# HELP app_latency_seconds Request latency.
+# TYPE app_latency_seconds histogram
+app_latency_seconds_bucket{route="/a",le="0.1"} 2
+app_latency_seconds_bucket{route="/a",le="1"} 2
+app_latency_seconds_bucket{route="/a",le="+Inf"} 3
+app_latency_seconds_sum{route="/a"} 3.15
+app_latency_seconds_count{route="/a"} 3
+# HELP app_items Items stored.
+# TYPE app_items gauge
+app_items 42
+`
+	if b.String() != want {
+		t.Errorf("WriteText =\n%s\nwant\n%s", b.String(), want)
+	}
+	if got := requests.Value("/a", "500"); got != 3 {
+		t.Errorf("Value = %v, want 3", got)
+	}
+	if got := latency.Count("/a"); got != 3 {
+		t.Errorf("Count = %d, want 3", got)
+	}
+}
+
+func TestEscaping(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("x_total", "Line one\nline \\two.", "v")
+	c.Inc("say \"hi\"\n\\")
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	for _, want := range []string{
+		`# HELP x_total Line one\nline \\two.`,
+		`x_total{v="say \"hi\"\n\\"} 1`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("output lacks %q:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.NewGaugeFunc("up", "Always one.", func() float64 { return 1 })
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "\nup 1\n") {
+		t.Errorf("body = %q", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want 405", rec.Code)
+	}
+}
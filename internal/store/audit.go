@@ -0,0 +1,231 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Audit actions recorded for mutating API calls.
+const (
+	AuditClientUpsert     = "client.upsert"
+	AuditClientUpdate     = "client.update"
+	AuditClientRevoke     = "client.revoke"
+	AuditClientDelete     = "client.delete"
+	AuditCertificateIssue = "certificate.issue"
+	AuditCertificateRenew = "certificate.renew"
+	AuditFeatureCreate    = "feature.create"
+	AuditFeatureUpdate    = "feature.update"
+	AuditFeatureDelete    = "feature.delete"
+	AuditCatalogSeed      = "catalog.seed"
+)
+
+// AuditEntry records one mutating API call: who did what to which target,
+// with the target's state before and after (omitted when it did not exist).
+// Entries are append-only; ID increases by one with every entry.
+type AuditEntry struct {
+	ID               int64           `json:"id"`
+	Time             time.Time       `json:"time"`
+	ActorFingerprint string          `json:"actor_fingerprint"`
+	ActorName        string          `json:"actor_name"`
+	Action           string          `json:"action"`
+	Target           string          `json:"target"`
+	Before           json.RawMessage `json:"before,omitempty"`
+	After            json.RawMessage `json:"after,omitempty"`
+	RequestID        string          `json:"request_id,omitempty"` // request that made the change, if any
+}
+
+// AuditFilter selects audit entries. Zero fields match everything.
+type AuditFilter struct {
+	Actor   string    // Actor fingerprint or name
+	Action  string    // Exact action, or a prefix ending in "." (e.g. "feature.")
+	Target  string    // Exact target
+	Since   time.Time // Entries at or after this time
+	AfterID int64     // Entries with a greater ID, for following the log
+	Limit   int       // Maximum number of entries (default 100)
+}
+
+// matches reports whether e passes the filter.
+func (f AuditFilter) matches(e AuditEntry) bool {
+	if e.ID <= f.AfterID {
+		return false
+	}
+	if f.Actor != "" && f.Actor != e.ActorFingerprint && f.Actor != e.ActorName {
+		return false
+	}
+	if f.Action != "" && f.Action != e.Action &&
+		(!strings.HasSuffix(f.Action, ".") || !strings.HasPrefix(e.Action, f.Action)) {
+		return false
+	}
+	if f.Target != "" && f.Target != e.Target {
+		return false
+	}
+	return f.Since.IsZero() || !e.Time.Before(f.Since)
+}
+
+// AppendAudit assigns e the next ID (and the current time, if unset),
+// persists it and returns the stored entry. Changes made through Audited
+// record their entries themselves; this is for events that change nothing
+// else.
+func (s *Store) AppendAudit(e AuditEntry) (AuditEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stampAuditLocked(&e)
+	if err := s.commitLocked(Record{Op: OpAppendAudit, Audit: &e}); err != nil {
+		return AuditEntry{}, err
+	}
+	return e, nil
+}
+
+// stampAuditLocked assigns e the next ID and, if unset, the current time.
+// Caller must hold the write lock.
+func (s *Store) stampAuditLocked(e *AuditEntry) {
+	e.ID = 1
+	if n := len(s.audit); n > 0 {
+		e.ID = s.audit[n-1].ID + 1
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+}
+
+// auditLocked attaches to rec the audit entry for a change made by a, so the
+// change and its entry are committed together. before and after are the
+// target's state around the change; nil means it did not exist. Nothing is
+// attached if a is nil. Caller must hold the write lock.
+func (s *Store) auditLocked(rec *Record, a *Actor, action, target string, before, after any) error {
+	if a == nil {
+		return nil
+	}
+	e := AuditEntry{
+		ActorFingerprint: a.Fingerprint,
+		ActorName:        a.Name,
+		Action:           action,
+		Target:           target,
+		RequestID:        a.RequestID,
+	}
+	var err error
+	if e.Before, err = auditPayload(before); err != nil {
+		return fmt.Errorf("audit %s %s: %w", action, target, err)
+	}
+	if e.After, err = auditPayload(after); err != nil {
+		return fmt.Errorf("audit %s %s: %w", action, target, err)
+	}
+	s.stampAuditLocked(&e)
+	rec.Audit = &e
+	return nil
+}
+
+// auditPayload marshals v for an audit entry, returning nil for nil.
+func auditPayload(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// Actor identifies who makes a change, for its audit entry.
+type Actor struct {
+	Fingerprint string // certificate fingerprint, empty for the server itself
+	Name        string
+	RequestID   string // request that made the change, if any
+}
+
+// Audited makes changes to a Store on behalf of an actor. Each change is
+// committed together with its audit entry, so one is never stored without
+// the other.
+type Audited struct {
+	s     *Store
+	actor Actor
+}
+
+// As returns a handle for changing s on behalf of a.
+func (s *Store) As(a Actor) Audited {
+	return Audited{s: s, actor: a}
+}
+
+// IssueClient is UpsertClient for the certificate just issued to c.
+func (a Audited) IssueClient(c Client) error {
+	return a.s.upsertClient(&a.actor, c)
+}
+
+// RegisterClient is Store.RegisterClient, audited.
+func (a Audited) RegisterClient(c Client) (prev Client, existed bool, err error) {
+	return a.s.registerClient(&a.actor, c)
+}
+
+// RevokeClient is Store.RevokeClient, audited.
+func (a Audited) RevokeClient(fp string) (Client, error) {
+	return a.s.revokeClient(&a.actor, fp)
+}
+
+// UpdateClient is Store.UpdateClient, audited.
+func (a Audited) UpdateClient(fp string, upd ClientUpdate) (Client, error) {
+	return a.s.updateClient(&a.actor, fp, upd)
+}
+
+// RekeyClient is Store.RekeyClient, audited as a certificate renewal.
+func (a Audited) RekeyClient(oldFP, newFP string) (Client, error) {
+	return a.s.rekeyClient(&a.actor, oldFP, newFP)
+}
+
+// DeleteClient is Store.DeleteClient, audited.
+func (a Audited) DeleteClient(fp string) error {
+	return a.s.deleteClient(&a.actor, fp)
+}
+
+// SeedFeatures is Store.SeedFeatures, audited.
+func (a Audited) SeedFeatures(count int) error {
+	return a.s.seedFeatures(&a.actor, count)
+}
+
+// CreateFeature is Store.CreateFeatureWithStatus, audited.
+func (a Audited) CreateFeature(name, summary, owner string, tags []string, status Status) (Feature, error) {
+	return a.s.createFeature(&a.actor, name, summary, owner, tags, status)
+}
+
+// UpdateFeature is Store.UpdateFeature, audited.
+func (a Audited) UpdateFeature(id string, upd FeatureUpdate, ifVersion int64) (Feature, error) {
+	return a.s.updateFeature(&a.actor, id, upd, ifVersion)
+}
+
+// DeleteFeature is Store.DeleteFeature, audited.
+func (a Audited) DeleteFeature(id string, ifVersion int64) error {
+	return a.s.deleteFeature(&a.actor, id, ifVersion)
+}
+
+// Audit returns the entries matching f, oldest first. Without AfterID it
+// returns the most recent matches; with AfterID, the first matches after it.
+func (s *Store) Audit(f AuditFilter) []AuditEntry {
+	if f.Limit <= 0 {
+		f.Limit = 100
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []AuditEntry
+	if f.AfterID > 0 {
+		for _, e := range s.audit {
+			if f.matches(e) {
+				out = append(out, e)
+				if len(out) == f.Limit {
+					break
+				}
+			}
+		}
+		return out
+	}
+
+	// Walk backwards from the newest entry, then restore chronological order
+	for i := len(s.audit) - 1; i >= 0 && len(out) < f.Limit; i-- {
+		if f.matches(s.audit[i]) {
+			out = append(out, s.audit[i])
+		}
+	}
+	slices.Reverse(out)
+	return out
+}
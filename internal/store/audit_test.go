@@ -0,0 +1,297 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// auditIDs returns the IDs of entries, in order.
+func auditIDs(entries []AuditEntry) []int64 {
+	ids := make([]int64, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func TestAudit(t *testing.T) {
+	s := New()
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, e := range []AuditEntry{
+		{ActorFingerprint: "fp-admin", ActorName: "admin", Action: AuditFeatureCreate, Target: "FT-000001"},
+		{ActorFingerprint: "fp-admin", ActorName: "admin", Action: AuditFeatureUpdate, Target: "FT-000001"},
+		{ActorFingerprint: "fp-bob", ActorName: "bob", Action: AuditClientUpsert, Target: "fp-carol"},
+		{ActorFingerprint: "fp-admin", ActorName: "admin", Action: AuditFeatureDelete, Target: "FT-000001"},
+		{ActorFingerprint: "fp-bob", ActorName: "bob", Action: AuditCatalogSeed, Target: "catalog"},
+	} {
+		e.Time = base.Add(time.Duration(i) * time.Hour)
+		got, err := s.AppendAudit(e)
+		if err != nil {
+			t.Fatalf("AppendAudit: %v", err)
+		}
+		if got.ID != int64(i+1) {
+			t.Errorf("entry %d got ID %d", i, got.ID)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter AuditFilter
+		want   []int64
+	}{
+		{"all", AuditFilter{}, []int64{1, 2, 3, 4, 5}},
+		{"actor by name", AuditFilter{Actor: "bob"}, []int64{3, 5}},
+		{"actor by fingerprint", AuditFilter{Actor: "fp-admin"}, []int64{1, 2, 4}},
+		{"exact action", AuditFilter{Action: AuditFeatureUpdate}, []int64{2}},
+		{"action prefix", AuditFilter{Action: "feature."}, []int64{1, 2, 4}},
+		{"no partial action", AuditFilter{Action: "feature"}, nil},
+		{"target", AuditFilter{Target: "FT-000001"}, []int64{1, 2, 4}},
+		{"since", AuditFilter{Since: base.Add(3 * time.Hour)}, []int64{4, 5}},
+		{"latest first limit", AuditFilter{Limit: 2}, []int64{4, 5}},
+		{"after ID", AuditFilter{AfterID: 2, Limit: 2}, []int64{3, 4}},
+		{"after last", AuditFilter{AfterID: 5}, nil},
+		{"combined", AuditFilter{Actor: "admin", Action: "feature.", AfterID: 1}, []int64{2, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := auditIDs(s.Audit(tt.filter))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Audit(%+v) = %v, want %v", tt.filter, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Audit(%+v) = %v, want %v", tt.filter, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAudit_DefaultsTime(t *testing.T) {
+	s := New()
+	before := time.Now().UTC()
+	e, err := s.AppendAudit(AuditEntry{Action: AuditFeatureCreate, Target: "FT-000001"})
+	if err != nil {
+		t.Fatalf("AppendAudit: %v", err)
+	}
+	if e.Time.Before(before) || e.Time.After(time.Now().UTC()) {
+		t.Errorf("Time = %v, want now", e.Time)
+	}
+}
+
+func TestFileBackend_AuditPersists(t *testing.T) {
+	dir := t.TempDir()
+
+	s := openFileStore(t, dir)
+	after, err := json.Marshal(map[string]string{"id": "FT-000001"})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if _, err := s.AppendAudit(AuditEntry{ActorName: "admin", Action: AuditFeatureCreate, Target: "FT-000001", After: after}); err != nil {
+		t.Fatalf("AppendAudit: %v", err)
+	}
+	// Reseeding the catalog must not wipe the trail recording it
+	if err := s.SeedFeatures(5); err != nil {
+		t.Fatalf("SeedFeatures: %v", err)
+	}
+	if _, err := s.AppendAudit(AuditEntry{ActorName: "admin", Action: AuditCatalogSeed, Target: "catalog"}); err != nil {
+		t.Fatalf("AppendAudit: %v", err)
+	}
+
+	// Reopen without Close: the first entry comes from the snapshot written
+	// by the seed, the second from the WAL
+	s2 := openFileStore(t, dir)
+	defer s2.Close()
+
+	got := s2.Audit(AuditFilter{})
+	if len(got) != 2 {
+		t.Fatalf("restored %d audit entries, want 2", len(got))
+	}
+	if got[0].Action != AuditFeatureCreate || string(got[0].After) != string(after) {
+		t.Errorf("first entry = %+v", got[0])
+	}
+	if got[1].ID != 2 || got[1].Action != AuditCatalogSeed {
+		t.Errorf("second entry = %+v", got[1])
+	}
+
+	// IDs keep increasing after a restore
+	e, err := s2.AppendAudit(AuditEntry{Action: AuditFeatureDelete})
+	if err != nil {
+		t.Fatalf("AppendAudit: %v", err)
+	}
+	if e.ID != 3 {
+		t.Errorf("next ID = %d, want 3", e.ID)
+	}
+}
+
+// recordingBackend keeps the records appended to it and fails appends and
+// compactions while fail is set.
+type recordingBackend struct {
+	Backend
+	recs []Record
+	fail bool
+}
+
+func (b *recordingBackend) Append(rec Record) error {
+	if b.fail {
+		return errors.New("disk full")
+	}
+	b.recs = append(b.recs, rec)
+	return b.Backend.Append(rec)
+}
+
+func (b *recordingBackend) Compact(snap *Snapshot) error {
+	if b.fail {
+		return errors.New("disk full")
+	}
+	return b.Backend.Compact(snap)
+}
+
+func TestAudited(t *testing.T) {
+	b := &recordingBackend{Backend: NewMemoryBackend()}
+	s, err := Open(b)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	f, err := s.CreateFeature("Auth", "Login flow", "Security", nil)
+	if err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+	as := s.As(Actor{Fingerprint: "fp-admin", Name: "admin", RequestID: "req-1"})
+
+	// The change and its entry are one record
+	summary := "Login and logout"
+	updated, err := as.UpdateFeature(f.ID, FeatureUpdate{Summary: &summary}, 0)
+	if err != nil {
+		t.Fatalf("UpdateFeature: %v", err)
+	}
+	last := b.recs[len(b.recs)-1]
+	if len(b.recs) != 2 || last.Op != OpPutFeature || last.Audit == nil {
+		t.Fatalf("records = %+v, want the update with its audit entry", b.recs)
+	}
+	e := *last.Audit
+	if e.ID != 1 || e.Action != AuditFeatureUpdate || e.Target != f.ID || e.ActorName != "admin" ||
+		e.ActorFingerprint != "fp-admin" || e.RequestID != "req-1" {
+		t.Errorf("entry = %+v", e)
+	}
+	var before, after Feature
+	if err := json.Unmarshal(e.Before, &before); err != nil || before.Version != 1 {
+		t.Errorf("before = %s, %v; want version 1", e.Before, err)
+	}
+	if err := json.Unmarshal(e.After, &after); err != nil || after.Version != updated.Version || after.Summary != summary {
+		t.Errorf("after = %s, %v; want %+v", e.After, err, updated)
+	}
+
+	// A change that is not persisted leaves no entry, and vice versa
+	b.fail = true
+	if err := as.DeleteFeature(f.ID, 0); err == nil {
+		t.Fatal("DeleteFeature succeeded with a failing backend")
+	}
+	if err := as.SeedFeatures(3); err == nil {
+		t.Fatal("SeedFeatures succeeded with a failing backend")
+	}
+	b.fail = false
+	if _, ok := s.GetFeature(f.ID); !ok {
+		t.Error("feature deleted despite the failed commit")
+	}
+	if got := s.Audit(AuditFilter{}); len(got) != 1 {
+		t.Errorf("audit after failed commits = %+v, want only the update", got)
+	}
+
+	// Refused changes are not audited; a seed is, in its snapshot
+	if err := as.DeleteFeature(f.ID, 1); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("DeleteFeature stale version = %v, want ErrVersionConflict", err)
+	}
+	if err := as.SeedFeatures(3); err != nil {
+		t.Fatalf("SeedFeatures: %v", err)
+	}
+	got := s.Audit(AuditFilter{})
+	if len(got) != 2 || got[1].Action != AuditCatalogSeed || string(got[1].Before) != `{"features":1}` ||
+		string(got[1].After) != `{"features":3}` {
+		t.Errorf("audit after seed = %+v", got)
+	}
+
+	// Unaudited changes stay unaudited
+	if _, err := s.CreateFeature("Billing", "Invoices", "Finance", nil); err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+	if got := s.Audit(AuditFilter{}); len(got) != 2 {
+		t.Errorf("unaudited create added an entry: %+v", got)
+	}
+}
+
+func TestAudited_Clients(t *testing.T) {
+	s := New()
+	as := s.As(Actor{Fingerprint: "fp-admin", Name: "admin"})
+	if err := as.IssueClient(Client{Fingerprint: "aa", Name: "alice", Role: RoleUser}); err != nil {
+		t.Fatalf("IssueClient: %v", err)
+	}
+	if _, _, err := as.RegisterClient(Client{Fingerprint: "aa", Name: "alice", Role: RoleEditor}); err != nil {
+		t.Fatalf("RegisterClient: %v", err)
+	}
+	role := RoleAdmin
+	if _, err := as.UpdateClient("aa", ClientUpdate{Role: &role}); err != nil {
+		t.Fatalf("UpdateClient: %v", err)
+	}
+	if _, err := as.RekeyClient("aa", "bb"); err != nil {
+		t.Fatalf("RekeyClient: %v", err)
+	}
+	if _, err := as.RevokeClient("bb"); err != nil {
+		t.Fatalf("RevokeClient: %v", err)
+	}
+	// Revoking again changes nothing, so there is nothing to audit
+	if _, err := as.RevokeClient("bb"); err != nil {
+		t.Fatalf("RevokeClient again: %v", err)
+	}
+	if err := as.DeleteClient("bb"); err != nil {
+		t.Fatalf("DeleteClient: %v", err)
+	}
+	if _, err := s.SyncClients(SourceClientsFile, []Client{{Fingerprint: "cc", Name: "ops", Role: RoleAdmin}}); err != nil {
+		t.Fatalf("SyncClients: %v", err)
+	}
+
+	want := []struct{ action, target, actor string }{
+		{AuditCertificateIssue, "aa", "admin"},
+		{AuditClientUpsert, "aa", "admin"},
+		{AuditClientUpdate, "aa", "admin"},
+		{AuditCertificateRenew, "bb", "admin"},
+		{AuditClientRevoke, "bb", "admin"},
+		{AuditClientDelete, "bb", "admin"},
+		{AuditClientUpsert, "cc", SourceClientsFile},
+	}
+	got := s.Audit(AuditFilter{})
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries %+v, want %d", len(got), got, len(want))
+	}
+	for i, w := range want {
+		if got[i].Action != w.action || got[i].Target != w.target || got[i].ActorName != w.actor {
+			t.Errorf("entry %d = %s %s by %s, want %s %s by %s", i,
+				got[i].Action, got[i].Target, got[i].ActorName, w.action, w.target, w.actor)
+		}
+	}
+	if got[0].Before != nil || got[5].After != nil || got[1].Before == nil {
+		t.Errorf("before/after of issue, upsert, delete = %s/%s, %s/%s, %s/%s",
+			got[0].Before, got[0].After, got[1].Before, got[1].After, got[5].Before, got[5].After)
+	}
+}
+
+func TestFileBackend_AuditedReplays(t *testing.T) {
+	dir := t.TempDir()
+	s := openFileStore(t, dir)
+	f, err := s.As(Actor{Name: "admin"}).CreateFeature("Auth", "Login flow", "Security", nil, StatusActive)
+	if err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+
+	// Reopen without Close, so both come back from the same WAL record
+	s2 := openFileStore(t, dir)
+	defer s2.Close()
+	if _, ok := s2.GetFeature(f.ID); !ok {
+		t.Error("feature not replayed")
+	}
+	if got := s2.Audit(AuditFilter{}); len(got) != 1 || got[0].Target != f.ID {
+		t.Errorf("audit after replay = %+v", got)
+	}
+}
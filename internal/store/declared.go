@@ -0,0 +1,89 @@
+package store
+
+import (
+	"slices"
+	"time"
+)
+
+// SourceClientsFile marks clients declared in the daemon's clients file.
+const SourceClientsFile = "clients-file"
+
+// ClientChange is one change made by SyncClients. Before is nil for an added
+// client and After is nil for a removed one.
+type ClientChange struct {
+	Before *Client
+	After  *Client
+}
+
+// SyncResult reports what SyncClients did.
+type SyncResult struct {
+	Changes []ClientChange
+	Revoked []Client // declared but revoked, left as they are
+}
+
+// SyncClients makes the clients managed by source match want: listed clients
+// are added or updated (name, role, teams) and taken over from the API if
+// registered there, and clients previously synced from source but no longer
+// listed are removed. Revoked clients are neither brought back nor removed,
+// so their certificates stay refused. Unchanged
+// clients are not rewritten, so syncing the same list twice is a no-op.
+// Every change is audited with source as the actor's name.
+func (s *Store) SyncClients(source string, want []Client) (SyncResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var res SyncResult
+	actor := Actor{Name: source}
+	listed := make(map[string]bool, len(want))
+	for _, c := range want {
+		listed[c.Fingerprint] = true
+		prev, exists := s.clients[c.Fingerprint]
+		if exists && prev.Revoked {
+			res.Revoked = append(res.Revoked, prev)
+			continue
+		}
+		c.Teams = NormalizeTeams(c.Teams)
+		c.Source = source
+		c.CreatedAt = time.Now()
+		if exists {
+			c.CreatedAt = prev.CreatedAt
+			if prev.Name == c.Name && prev.Role == c.Role && prev.Source == c.Source && slices.Equal(prev.Teams, c.Teams) {
+				continue
+			}
+		}
+		rec := Record{Op: OpUpsertClient, Client: &c}
+		change := ClientChange{After: &c}
+		var before any
+		if exists {
+			change.Before, before = &prev, prev
+		}
+		if err := s.auditLocked(&rec, &actor, AuditClientUpsert, c.Fingerprint, before, c); err != nil {
+			return res, err
+		}
+		if err := s.commitLocked(rec); err != nil {
+			return res, err
+		}
+		res.Changes = append(res.Changes, change)
+	}
+
+	// Sorted so removals are logged and audited in a stable order
+	var stale []string
+	for fp, c := range s.clients {
+		if c.Source == source && !listed[fp] && !c.Revoked {
+			stale = append(stale, fp)
+		}
+	}
+	slices.Sort(stale)
+	for _, fp := range stale {
+		prev := s.clients[fp]
+		rec := Record{Op: OpDeleteClient, Fingerprint: fp}
+		if err := s.auditLocked(&rec, &actor, AuditClientDelete, fp, prev, nil); err != nil {
+			return res, err
+		}
+		if err := s.commitLocked(rec); err != nil {
+			return res, err
+		}
+		res.Changes = append(res.Changes, ClientChange{Before: &prev})
+	}
+	return res, nil
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"testing"
+)
+
+func TestSyncClients(t *testing.T) {
+	s := New()
+	s.UpsertClient(Client{Fingerprint: "api", Name: "registered", Role: RoleUser})
+	s.UpsertClient(Client{Fingerprint: "taken", Name: "old name", Role: RoleUser})
+
+	res, err := s.SyncClients(SourceClientsFile, []Client{
+		{Fingerprint: "ops", Name: "ops", Role: RoleAdmin},
+		{Fingerprint: "taken", Name: "ci", Role: RoleEditor, Teams: []string{" Payments "}},
+	})
+	if err != nil {
+		t.Fatalf("SyncClients: %v", err)
+	}
+	if len(res.Changes) != 2 || res.Changes[0].Before != nil || res.Changes[1].Before == nil {
+		t.Fatalf("first sync changes = %+v, want one add and one takeover", res.Changes)
+	}
+	ci, _ := s.GetClient("taken")
+	if ci.Source != SourceClientsFile || ci.Role != RoleEditor || len(ci.Teams) != 1 || ci.Teams[0] != "Payments" {
+		t.Errorf("taken over client = %+v", ci)
+	}
+
+	// Same list again changes nothing
+	res, err = s.SyncClients(SourceClientsFile, []Client{
+		{Fingerprint: "ops", Name: "ops", Role: RoleAdmin},
+		{Fingerprint: "taken", Name: "ci", Role: RoleEditor, Teams: []string{"Payments"}},
+	})
+	if err != nil || len(res.Changes) != 0 {
+		t.Fatalf("repeat sync = %+v, %v; want no changes", res.Changes, err)
+	}
+
+	// Revoked clients stay revoked, whether listed or dropped
+	if _, err := s.RevokeClient("ops"); err != nil {
+		t.Fatalf("RevokeClient: %v", err)
+	}
+	res, err = s.SyncClients(SourceClientsFile, []Client{{Fingerprint: "ops", Name: "ops", Role: RoleAdmin}})
+	if err != nil {
+		t.Fatalf("SyncClients: %v", err)
+	}
+	if len(res.Revoked) != 1 || len(res.Changes) != 1 || res.Changes[0].After != nil || res.Changes[0].Before.Fingerprint != "taken" {
+		t.Fatalf("sync after revoke = %+v, want ops skipped and taken removed", res)
+	}
+	if c, ok := s.GetClient("ops"); !ok || !c.Revoked {
+		t.Errorf("revoked declared client = %+v, %v; want still revoked", c, ok)
+	}
+	if _, err := s.SyncClients(SourceClientsFile, nil); err != nil {
+		t.Fatalf("SyncClients: %v", err)
+	}
+	if _, ok := s.GetClient("ops"); !ok {
+		t.Error("dropping a revoked client from the file must not delete it")
+	}
+
+	// Clients registered through the API are never touched
+	if c, ok := s.GetClient("api"); !ok || c.Source != "" {
+		t.Errorf("API client = %+v, %v; want unchanged", c, ok)
+	}
+}
@@ -0,0 +1,192 @@
+package store
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types, one per kind of catalog change.
+const (
+	EventFeatureCreated  = "feature.created"
+	EventFeatureUpdated  = "feature.updated"
+	EventFeatureDeleted  = "feature.deleted"
+	EventCatalogReseeded = "catalog.reseeded"
+)
+
+// Event is a change to the feature catalog. ID is the sequence number of the
+// commit that made the change, so IDs increase with every change and, with a
+// persistent backend, across restarts; they are not contiguous.
+type Event struct {
+	ID      uint64
+	Type    string
+	Time    time.Time
+	Feature *Feature // the feature as created or updated, or its last state if deleted
+	Count   int      // size of the new catalog, for EventCatalogReseeded
+}
+
+// Event feed limits.
+const (
+	// eventBacklog is the number of recent events kept for subscribers
+	// resuming after a disconnect.
+	eventBacklog = 1024
+	// subscriberBuffer is the number of undelivered events a subscriber may
+	// fall behind by before it is dropped.
+	subscriberBuffer = 256
+)
+
+// eventFeed keeps recent events and fans new ones out to subscribers.
+type eventFeed struct {
+	mu     sync.Mutex
+	recent []Event // oldest first, at most eventBacklog
+	floor  uint64  // every event with an ID above floor is in recent
+	head   uint64  // ID of the last event, or floor if there was none
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+// Subscription delivers catalog events. C is closed when the subscription
+// ends: on Close, on Store.CloseSubscriptions, or when the subscriber falls
+// too far behind, in which case it should subscribe again after the last
+// event it received.
+type Subscription struct {
+	C <-chan Event
+	// Start is the ID the subscription starts after; events on C follow it.
+	Start uint64
+	// Missed reports that events after the requested ID are no longer kept,
+	// so the subscription starts at the current event instead and the
+	// subscriber must reload whatever it derived from the catalog.
+	Missed bool
+
+	ch   chan Event
+	feed *eventFeed
+}
+
+// resetFeedLocked starts the event feed after the store's current sequence
+// number, as nothing before it is kept. Caller must hold the write lock.
+func (s *Store) resetFeedLocked() {
+	s.events.mu.Lock()
+	defer s.events.mu.Unlock()
+	s.events.recent = nil
+	s.events.floor, s.events.head = s.seq, s.seq
+}
+
+// eventLocked returns the event rec will cause once committed, if any.
+// Caller must hold the write lock, and rec must not be applied yet.
+func (s *Store) eventLocked(rec Record) (Event, bool) {
+	switch rec.Op {
+	case OpPutFeature:
+		if rec.Feature == nil {
+			return Event{}, false
+		}
+		ev := Event{Type: EventFeatureUpdated, Time: rec.Feature.UpdatedAt}
+		if _, exists := s.features[rec.Feature.ID]; !exists {
+			ev.Type = EventFeatureCreated
+		}
+		f := *rec.Feature
+		ev.Feature = &f
+		return ev, true
+	case OpDeleteFeature:
+		f, exists := s.features[rec.FeatureID]
+		if !exists {
+			return Event{}, false
+		}
+		return Event{Type: EventFeatureDeleted, Time: rec.Time, Feature: &f}, true
+	default:
+		return Event{}, false
+	}
+}
+
+// publishLocked records ev and delivers it to every subscriber, dropping
+// those whose buffer is full. Caller must hold the write lock, so events are
+// published in ID order.
+func (s *Store) publishLocked(ev Event) {
+	feed := &s.events
+	feed.mu.Lock()
+	defer feed.mu.Unlock()
+
+	if len(feed.recent) == eventBacklog {
+		feed.floor = feed.recent[0].ID
+		feed.recent = append(feed.recent[:0], feed.recent[1:]...)
+	}
+	feed.recent = append(feed.recent, ev)
+	feed.head = ev.ID
+
+	for sub := range feed.subs {
+		select {
+		case sub.ch <- ev:
+		default:
+			// Blocking would stall every writer; the subscriber resumes
+			// from the backlog instead
+			delete(feed.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// LastEventID returns the ID of the latest event, for subscribing to new
+// events only.
+func (s *Store) LastEventID() uint64 {
+	s.events.mu.Lock()
+	defer s.events.mu.Unlock()
+	return s.events.head
+}
+
+// Subscribe returns a subscription to the events after the one with ID
+// after, starting with those already published. If some of them are no
+// longer kept, or after is ahead of the feed (e.g. it came from a store that
+// has since been replaced), the subscription starts at the latest event
+// with Missed set. Call Close when done.
+func (s *Store) Subscribe(after uint64) *Subscription {
+	feed := &s.events
+	feed.mu.Lock()
+	defer feed.mu.Unlock()
+
+	sub := &Subscription{Start: after, feed: feed}
+	if after < feed.floor || after > feed.head {
+		sub.Start, sub.Missed = feed.head, true
+	}
+	i := len(feed.recent)
+	for i > 0 && feed.recent[i-1].ID > sub.Start {
+		i--
+	}
+	backlog := feed.recent[i:]
+	sub.ch = make(chan Event, len(backlog)+subscriberBuffer)
+	sub.C = sub.ch
+	for _, ev := range backlog {
+		sub.ch <- ev
+	}
+
+	if feed.closed {
+		close(sub.ch)
+		return sub
+	}
+	if feed.subs == nil {
+		feed.subs = make(map[*Subscription]struct{})
+	}
+	feed.subs[sub] = struct{}{}
+	return sub
+}
+
+// Close ends the subscription and closes C if it is still open.
+func (sub *Subscription) Close() {
+	sub.feed.mu.Lock()
+	defer sub.feed.mu.Unlock()
+	if _, ok := sub.feed.subs[sub]; ok {
+		delete(sub.feed.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// CloseSubscriptions ends every subscription, current and future, after the
+// events already delivered to it, so long-lived streams let a server shut
+// down.
+func (s *Store) CloseSubscriptions() {
+	feed := &s.events
+	feed.mu.Lock()
+	defer feed.mu.Unlock()
+	feed.closed = true
+	for sub := range feed.subs {
+		delete(feed.subs, sub)
+		close(sub.ch)
+	}
+}
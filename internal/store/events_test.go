@@ -0,0 +1,199 @@
+package store
+
+import (
+	"testing"
+)
+
+// drain returns the events buffered on sub without waiting for more.
+func drain(sub *Subscription) []Event {
+	var evs []Event
+	for {
+		select {
+		case ev, ok := <-sub.C:
+			if !ok {
+				return evs
+			}
+			evs = append(evs, ev)
+		default:
+			return evs
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	s := New()
+	sub := s.Subscribe(s.LastEventID())
+	defer sub.Close()
+	if sub.Missed {
+		t.Fatal("subscribing at the latest event should not miss any")
+	}
+
+	f, err := s.CreateFeature("Auth", "Login flow", "Security", nil)
+	if err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+	// Client changes are not catalog events
+	if err := s.UpsertClient(Client{Fingerprint: "aa", Name: "alice", Role: RoleUser}); err != nil {
+		t.Fatalf("UpsertClient: %v", err)
+	}
+	summary := "Login and logout"
+	if _, err := s.UpdateFeature(f.ID, FeatureUpdate{Summary: &summary}, 0); err != nil {
+		t.Fatalf("UpdateFeature: %v", err)
+	}
+	if err := s.DeleteFeature(f.ID, 0); err != nil {
+		t.Fatalf("DeleteFeature: %v", err)
+	}
+	if err := s.SeedFeatures(3); err != nil {
+		t.Fatalf("SeedFeatures: %v", err)
+	}
+
+	evs := drain(sub)
+	want := []string{EventFeatureCreated, EventFeatureUpdated, EventFeatureDeleted, EventCatalogReseeded}
+	if len(evs) != len(want) {
+		t.Fatalf("got %d events %+v, want %v", len(evs), evs, want)
+	}
+	for i, ev := range evs {
+		if ev.Type != want[i] {
+			t.Errorf("event %d type = %q, want %q", i, ev.Type, want[i])
+		}
+		if i > 0 && ev.ID <= evs[i-1].ID {
+			t.Errorf("event %d ID %d does not follow %d", i, ev.ID, evs[i-1].ID)
+		}
+	}
+	if evs[1].Feature == nil || evs[1].Feature.Summary != summary || evs[1].Feature.Version != 2 {
+		t.Errorf("update event feature = %+v", evs[1].Feature)
+	}
+	if evs[2].Feature == nil || evs[2].Feature.ID != f.ID || evs[2].Time.IsZero() {
+		t.Errorf("delete event = %+v", evs[2])
+	}
+	if evs[3].Count != 3 {
+		t.Errorf("reseed event count = %d, want 3", evs[3].Count)
+	}
+	if got := s.LastEventID(); got != evs[3].ID {
+		t.Errorf("LastEventID = %d, want %d", got, evs[3].ID)
+	}
+
+	// Resuming replays exactly the events after the given one
+	resumed := s.Subscribe(evs[1].ID)
+	defer resumed.Close()
+	got := drain(resumed)
+	if resumed.Missed || len(got) != 2 || got[0].ID != evs[2].ID || got[1].ID != evs[3].ID {
+		t.Errorf("resume after %d: missed %v, events %+v", evs[1].ID, resumed.Missed, got)
+	}
+
+	// An ID the store never reached means the subscriber's view is stale
+	ahead := s.Subscribe(evs[3].ID + 100)
+	defer ahead.Close()
+	if !ahead.Missed || ahead.Start != evs[3].ID || len(drain(ahead)) != 0 {
+		t.Errorf("subscribe ahead of the feed: missed %v, start %d", ahead.Missed, ahead.Start)
+	}
+}
+
+func TestEventsBacklog(t *testing.T) {
+	s := New()
+	first, err := s.CreateFeature("First", "Summary", "Team", nil)
+	if err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+	for i := range eventBacklog {
+		if _, err := s.UpdateFeature(first.ID, FeatureUpdate{Name: new(string)}, 0); err != nil {
+			t.Fatalf("UpdateFeature %d: %v", i, err)
+		}
+	}
+
+	// The creation has been pushed out of the backlog
+	sub := s.Subscribe(0)
+	defer sub.Close()
+	if !sub.Missed || sub.Start != s.LastEventID() {
+		t.Errorf("subscribe before the backlog: missed %v, start %d", sub.Missed, sub.Start)
+	}
+	kept := s.Subscribe(s.LastEventID() - eventBacklog)
+	defer kept.Close()
+	if kept.Missed || len(drain(kept)) != eventBacklog {
+		t.Errorf("subscribe at the backlog's start: missed %v", kept.Missed)
+	}
+}
+
+func TestEventsSlowSubscriber(t *testing.T) {
+	s := New()
+	slow := s.Subscribe(s.LastEventID())
+	for i := range subscriberBuffer + 1 {
+		if _, err := s.CreateFeature("Feature", "Summary", "Team", nil); err != nil {
+			t.Fatalf("CreateFeature %d: %v", i, err)
+		}
+	}
+
+	// The buffered events are still delivered before C closes
+	n := 0
+	for range slow.C {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("slow subscriber got %d events, want %d", n, subscriberBuffer)
+	}
+	slow.Close() // no-op once dropped
+}
+
+func TestEventsPersistedIDs(t *testing.T) {
+	b, err := OpenFileBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenFileBackend: %v", err)
+	}
+	s, err := Open(b)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := s.CreateFeature("Auth", "Login flow", "Security", nil); err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+	last := s.LastEventID()
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	b, err = OpenFileBackend(b.Dir())
+	if err != nil {
+		t.Fatalf("reopen backend: %v", err)
+	}
+	s, err = Open(b)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s.Close()
+
+	// Events from before the restart are gone, so resuming from one misses
+	// nothing only if it was the last
+	earlier := s.Subscribe(last - 1)
+	earlier.Close()
+	if !earlier.Missed {
+		t.Error("resuming from an earlier event should report missed events")
+	}
+	sub := s.Subscribe(last)
+	defer sub.Close()
+	if sub.Missed {
+		t.Error("resuming from the last event before a restart should not miss any")
+	}
+	f, err := s.CreateFeature("Billing", "Invoices", "Finance", nil)
+	if err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+	evs := drain(sub)
+	if len(evs) != 1 || evs[0].ID <= last || evs[0].Feature.ID != f.ID {
+		t.Errorf("events after restart = %+v, want one with an ID above %d", evs, last)
+	}
+}
+
+func TestCloseSubscriptions(t *testing.T) {
+	s := New()
+	sub := s.Subscribe(s.LastEventID())
+	s.CloseSubscriptions()
+	if _, ok := <-sub.C; ok {
+		t.Error("C should be closed")
+	}
+	sub.Close()
+
+	late := s.Subscribe(s.LastEventID())
+	if _, ok := <-late.C; ok {
+		t.Error("subscriptions after CloseSubscriptions should be closed")
+	}
+}
@@ -0,0 +1,218 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// File names used by FileBackend inside its data directory.
+const (
+	SnapshotFile = "snapshot.json"
+	WALFile      = "wal.jsonl"
+)
+
+// ErrCorruptLog is returned when a write-ahead log record cannot be decoded.
+var ErrCorruptLog = errors.New("corrupt write-ahead log")
+
+// FileBackend persists store state on local disk as a JSON snapshot plus an
+// append-only write-ahead log (one JSON record per line).
+// Every Append is fsynced before it returns; Compact rewrites the snapshot
+// atomically and truncates the log.
+type FileBackend struct {
+	dir string
+	wal walFile
+	// broken is set when a failed append could not be rolled back; appending
+	// after the leftover fragment would corrupt the log, so all appends fail
+	broken error
+}
+
+// walFile is the part of *os.File the log is written through, so tests can
+// make writes fail.
+type walFile interface {
+	io.WriteSeeker
+	Sync() error
+	Truncate(size int64) error
+	Name() string
+	Close() error
+}
+
+// OpenFileBackend opens (or creates) a file-backed store in dir.
+func OpenFileBackend(dir string) (*FileBackend, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+	//nolint:gosec // dir is from trusted command-line flag
+	wal, err := os.OpenFile(filepath.Join(dir, WALFile), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open wal: %w", err)
+	}
+	return &FileBackend{dir: dir, wal: wal}, nil
+}
+
+// Dir returns the data directory path.
+func (b *FileBackend) Dir() string {
+	return b.dir
+}
+
+// Load reads the snapshot and replays the log that follows it.
+// A trailing record without a newline is the remains of an interrupted
+// write that was never acknowledged; it is truncated away.
+func (b *FileBackend) Load() (*Snapshot, []Record, error) {
+	snap, err := b.loadSnapshot()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := os.ReadFile(b.wal.Name())
+	if err != nil {
+		return nil, nil, fmt.Errorf("read wal: %w", err)
+	}
+
+	var recs []Record
+	offset := 0
+	for offset < len(data) {
+		end := bytes.IndexByte(data[offset:], '\n')
+		if end < 0 {
+			// Torn write: drop the unterminated tail
+			if truncErr := b.wal.Truncate(int64(offset)); truncErr != nil {
+				return nil, nil, fmt.Errorf("truncate wal: %w", truncErr)
+			}
+			break
+		}
+		line := data[offset : offset+end]
+		offset += end + 1
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var rec Record
+		if unmarshalErr := json.Unmarshal(line, &rec); unmarshalErr != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ErrCorruptLog, unmarshalErr)
+		}
+		recs = append(recs, rec)
+	}
+
+	return snap, recs, nil
+}
+
+// loadSnapshot reads the snapshot file, returning nil if none exists.
+func (b *FileBackend) loadSnapshot() (*Snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(b.dir, SnapshotFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil //nolint:nilnil // nil snapshot is valid for "empty store"
+		}
+		return nil, fmt.Errorf("read snapshot: %w", err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("corrupt snapshot: %w", err)
+	}
+	return &snap, nil
+}
+
+// Append writes rec to the log and fsyncs it. If either fails, the log is
+// cut back to where it was, so a partly written record cannot run into the
+// next one.
+func (b *FileBackend) Append(rec Record) error {
+	if b.broken != nil {
+		return b.broken
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal record: %w", err)
+	}
+	line = append(line, '\n')
+	offset, err := b.wal.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("seek wal: %w", err)
+	}
+	if _, err := b.wal.Write(line); err != nil {
+		return b.rollback(offset, fmt.Errorf("write wal: %w", err))
+	}
+	if err := b.wal.Sync(); err != nil {
+		return b.rollback(offset, fmt.Errorf("sync wal: %w", err))
+	}
+	return nil
+}
+
+// rollback truncates the log to offset after the failed append err. If
+// that fails too, the backend refuses further appends.
+func (b *FileBackend) rollback(offset int64, err error) error {
+	if truncErr := b.wal.Truncate(offset); truncErr != nil {
+		b.broken = fmt.Errorf("wal left inconsistent by an earlier failure: %w", errors.Join(err, truncErr))
+		return b.broken
+	}
+	return err
+}
+
+// Compact writes snap atomically (temp file + rename) and truncates the log.
+// A crash between the two steps is harmless: replay skips records whose Seq
+// is already covered by the snapshot.
+func (b *FileBackend) Compact(snap *Snapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("marshal snapshot: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(b.dir, ".snapshot-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		_ = os.Remove(tmpPath) //nolint:errcheck // Best effort cleanup
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		_ = os.Remove(tmpPath) //nolint:errcheck // Best effort cleanup
+		return fmt.Errorf("sync snapshot: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		_ = os.Remove(tmpPath) //nolint:errcheck // Best effort cleanup
+		return fmt.Errorf("close snapshot: %w", err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(b.dir, SnapshotFile)); err != nil {
+		_ = os.Remove(tmpPath) //nolint:errcheck // Best effort cleanup
+		return fmt.Errorf("rename snapshot: %w", err)
+	}
+	// The rename is only durable once the directory entry is
+	if err := syncDir(b.dir); err != nil {
+		return fmt.Errorf("sync data dir: %w", err)
+	}
+
+	if err := b.wal.Truncate(0); err != nil {
+		return fmt.Errorf("truncate wal: %w", err)
+	}
+	if err := b.wal.Sync(); err != nil {
+		return err
+	}
+	b.broken = nil // whatever a failed append left is gone
+	return nil
+}
+
+// syncDir fsyncs the directory dir.
+func syncDir(dir string) error {
+	//nolint:gosec // dir is from trusted command-line flag
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	if err := d.Sync(); err != nil {
+		d.Close()
+		return err
+	}
+	return d.Close()
+}
+
+// Close closes the log file.
+func (b *FileBackend) Close() error {
+	return b.wal.Close()
+}
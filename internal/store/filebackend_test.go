@@ -0,0 +1,342 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// openFileStore opens a file-backed store in dir, failing the test on error.
+func openFileStore(t *testing.T, dir string) *Store {
+	t.Helper()
+	b, err := OpenFileBackend(dir)
+	if err != nil {
+		t.Fatalf("OpenFileBackend: %v", err)
+	}
+	s, err := Open(b)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return s
+}
+
+func TestFileBackend_PersistsAcrossReopen(t *testing.T) {
+	dir := t.TempDir()
+
+	s := openFileStore(t, dir)
+	if err := s.UpsertClient(Client{Fingerprint: "fp1", Name: "alice", Role: RoleUser}); err != nil {
+		t.Fatalf("UpsertClient: %v", err)
+	}
+	f, err := s.CreateFeature("Auth", "User login flow", "Security", []string{"auth"})
+	if err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+
+	// Simulate a crash: reopen without Close so state comes from the WAL only
+	s2 := openFileStore(t, dir)
+	defer s2.Close()
+
+	c, ok := s2.GetClient("fp1")
+	if !ok || c.Name != "alice" {
+		t.Errorf("client not restored: %+v, ok=%v", c, ok)
+	}
+	got, ok := s2.GetFeature(f.ID)
+	if !ok || got.Name != "Auth" {
+		t.Errorf("feature not restored: %+v, ok=%v", got, ok)
+	}
+}
+
+func TestFileBackend_ReplaysUpdatesAndDeletes(t *testing.T) {
+	dir := t.TempDir()
+
+	s := openFileStore(t, dir)
+	a, _ := s.CreateFeature("Auth", "User login flow", "", nil)
+	b, _ := s.CreateFeature("Billing", "Payments", "", nil)
+	name := "Authentication"
+	if _, err := s.UpdateFeature(a.ID, FeatureUpdate{Name: &name}, a.Version); err != nil {
+		t.Fatalf("UpdateFeature: %v", err)
+	}
+	if err := s.DeleteFeature(b.ID, b.Version); err != nil {
+		t.Fatalf("DeleteFeature: %v", err)
+	}
+
+	s2 := openFileStore(t, dir)
+	defer s2.Close()
+
+	got, ok := s2.GetFeature(a.ID)
+	if !ok || got.Name != name || got.Version != 2 {
+		t.Errorf("update not replayed: %+v", got)
+	}
+	if _, ok := s2.GetFeature(b.ID); ok {
+		t.Error("delete not replayed")
+	}
+
+	// The deleted ID stays retired after a restart
+	c, err := s2.CreateFeature("Catalog", "Next ID", "", nil)
+	if err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+	if c.ID != "FT-000003" {
+		t.Errorf("new feature ID = %q, want %q", c.ID, "FT-000003")
+	}
+}
+
+func TestFileBackend_SeedWritesSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	s := openFileStore(t, dir)
+	if _, err := s.CreateFeature("Old", "Replaced by seed", "", nil); err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+	if err := s.SeedFeatures(5); err != nil {
+		t.Fatalf("SeedFeatures: %v", err)
+	}
+	if _, err := s.CreateFeature("New", "After seed", "", nil); err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, SnapshotFile)); err != nil {
+		t.Fatalf("snapshot not written: %v", err)
+	}
+
+	s2 := openFileStore(t, dir)
+	defer s2.Close()
+
+	if n := s2.FeatureCount(); n != 6 {
+		t.Errorf("FeatureCount = %d, want 6", n)
+	}
+	// The seed continued after FT-000001, and so did the next feature
+	f, _ := s2.GetFeature("FT-000007")
+	if f.Name != "New" {
+		t.Errorf("FT-000007 name = %q, want %q", f.Name, "New")
+	}
+	if revs, _ := s2.FeatureHistory("FT-000001"); len(revs) != 2 || !revs[1].Deleted {
+		t.Errorf("FeatureHistory(FT-000001) = %+v, want it deleted by the seed", revs)
+	}
+}
+
+func TestFileBackend_CloseCompacts(t *testing.T) {
+	dir := t.TempDir()
+
+	s := openFileStore(t, dir)
+	if _, err := s.CreateFeature("Auth", "User login flow", "", nil); err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, WALFile))
+	if err != nil {
+		t.Fatalf("stat wal: %v", err)
+	}
+	if info.Size() != 0 {
+		t.Errorf("wal size after close = %d, want 0", info.Size())
+	}
+
+	s2 := openFileStore(t, dir)
+	defer s2.Close()
+	if n := s2.FeatureCount(); n != 1 {
+		t.Errorf("FeatureCount = %d, want 1", n)
+	}
+	// The search index is rebuilt from the snapshot
+	if got := s2.SearchFeatures("login", 10); len(got) != 1 {
+		t.Errorf("SearchFeatures after reopen returned %d results, want 1", len(got))
+	}
+}
+
+func TestFileBackend_StaleLogAfterSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	// Records covered by the snapshot must not be replayed again,
+	// e.g. after a crash between snapshot rename and log truncation.
+	b, err := OpenFileBackend(dir)
+	if err != nil {
+		t.Fatalf("OpenFileBackend: %v", err)
+	}
+	stale := Feature{ID: "FT-000001", Name: "Stale"}
+	if err := b.Append(Record{Seq: 1, Op: OpPutFeature, Feature: &stale}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	snap := &Snapshot{Seq: 2, Features: []Feature{{ID: "FT-000001", Name: "Fresh"}}}
+	data := []byte(`{"seq":2,"clients":[],"features":[{"id":"FT-000001","name":"Fresh"}]}`)
+	if err := os.WriteFile(filepath.Join(dir, SnapshotFile), data, 0o600); err != nil {
+		t.Fatalf("write snapshot: %v", err)
+	}
+	b.Close()
+
+	s := openFileStore(t, dir)
+	defer s.Close()
+	f, _ := s.GetFeature("FT-000001")
+	if f.Name != snap.Features[0].Name {
+		t.Errorf("name = %q, want %q", f.Name, snap.Features[0].Name)
+	}
+}
+
+func TestFileBackend_TornWrite(t *testing.T) {
+	dir := t.TempDir()
+
+	s := openFileStore(t, dir)
+	if _, err := s.CreateFeature("Auth", "User login flow", "", nil); err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+
+	// Append a partial record, as if the process died mid-write
+	walPath := filepath.Join(dir, WALFile)
+	f, err := os.OpenFile(walPath, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		t.Fatalf("open wal: %v", err)
+	}
+	if _, err := f.WriteString(`{"seq":2,"op":"put_fea`); err != nil {
+		t.Fatalf("write wal: %v", err)
+	}
+	f.Close()
+
+	s2 := openFileStore(t, dir)
+	if n := s2.FeatureCount(); n != 1 {
+		t.Errorf("FeatureCount = %d, want 1", n)
+	}
+
+	// New writes must land on a clean line
+	if _, err := s2.CreateFeature("Billing", "Payments", "", nil); err != nil {
+		t.Fatalf("CreateFeature after torn write: %v", err)
+	}
+	s3 := openFileStore(t, dir)
+	defer s3.Close()
+	if n := s3.FeatureCount(); n != 2 {
+		t.Errorf("FeatureCount after recovery = %d, want 2", n)
+	}
+}
+
+// failingWAL writes only half of each write and fails it, or fails Sync,
+// like a disk that ran out of space; with failTrunc set Truncate fails too.
+type failingWAL struct {
+	*os.File
+	failWrite, failSync, failTrunc bool
+}
+
+func (f *failingWAL) Write(p []byte) (int, error) {
+	if !f.failWrite {
+		return f.File.Write(p)
+	}
+	n, _ := f.File.Write(p[:len(p)/2])
+	return n, syscall.ENOSPC
+}
+
+func (f *failingWAL) Sync() error {
+	if f.failSync {
+		return syscall.EIO
+	}
+	return f.File.Sync()
+}
+
+func (f *failingWAL) Truncate(size int64) error {
+	if f.failTrunc {
+		return syscall.EIO
+	}
+	return f.File.Truncate(size)
+}
+
+func TestFileBackend_FailedAppendRollsBack(t *testing.T) {
+	dir := t.TempDir()
+	b, err := OpenFileBackend(dir)
+	if err != nil {
+		t.Fatalf("OpenFileBackend: %v", err)
+	}
+	s, err := Open(b)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	wal := &failingWAL{File: b.wal.(*os.File)}
+	b.wal = wal
+	if _, err := s.CreateFeature("Auth", "User login flow", "", nil); err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+
+	wal.failWrite = true
+	if _, err := s.CreateFeature("Torn", "Half written", "", nil); !errors.Is(err, syscall.ENOSPC) {
+		t.Fatalf("CreateFeature with a failing write = %v, want ENOSPC", err)
+	}
+	wal.failWrite, wal.failSync = false, true
+	if _, err := s.CreateFeature("Unsynced", "Written but not synced", "", nil); !errors.Is(err, syscall.EIO) {
+		t.Fatalf("CreateFeature with a failing sync = %v, want EIO", err)
+	}
+	wal.failSync = false
+	if _, err := s.CreateFeature("Billing", "Payments", "", nil); err != nil {
+		t.Fatalf("CreateFeature after the failures: %v", err)
+	}
+
+	// Nothing of the failed appends is left to corrupt the log
+	s2 := openFileStore(t, dir)
+	defer s2.Close()
+	if n := s2.FeatureCount(); n != 2 {
+		t.Errorf("FeatureCount after reopen = %d, want 2", n)
+	}
+
+	// A fragment that cannot be cut away stops all appends until a compaction
+	wal.failWrite, wal.failTrunc = true, true
+	if _, err := s.CreateFeature("Torn", "Half written", "", nil); err == nil {
+		t.Fatal("CreateFeature with a failing write succeeded")
+	}
+	wal.failWrite, wal.failTrunc = false, false
+	if _, err := s.CreateFeature("Refused", "After a failed rollback", "", nil); err == nil {
+		t.Fatal("CreateFeature after a failed rollback succeeded")
+	}
+	if err := b.Compact(&Snapshot{}); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if err := b.Append(Record{Seq: 1}); err != nil {
+		t.Errorf("Append after Compact: %v", err)
+	}
+}
+
+func TestFileBackend_CorruptRecord(t *testing.T) {
+	dir := t.TempDir()
+	walPath := filepath.Join(dir, WALFile)
+	if err := os.WriteFile(walPath, []byte("not json\n{\"seq\":2}\n"), 0o600); err != nil {
+		t.Fatalf("write wal: %v", err)
+	}
+
+	b, err := OpenFileBackend(dir)
+	if err != nil {
+		t.Fatalf("OpenFileBackend: %v", err)
+	}
+	defer b.Close()
+
+	_, err = Open(b)
+	if !errors.Is(err, ErrCorruptLog) {
+		t.Errorf("Open error = %v, want ErrCorruptLog", err)
+	}
+}
+
+func TestFileBackend_ReplaysClientRevocationAndDeletion(t *testing.T) {
+	dir := t.TempDir()
+
+	s := openFileStore(t, dir)
+	for _, c := range []Client{
+		{Fingerprint: "fp1", Name: "alice", Role: RoleUser},
+		{Fingerprint: "fp2", Name: "bob", Role: RoleUser},
+	} {
+		if err := s.UpsertClient(c); err != nil {
+			t.Fatalf("UpsertClient: %v", err)
+		}
+	}
+	if _, err := s.RevokeClient("fp1"); err != nil {
+		t.Fatalf("RevokeClient: %v", err)
+	}
+	if err := s.DeleteClient("fp2"); err != nil {
+		t.Fatalf("DeleteClient: %v", err)
+	}
+
+	s2 := openFileStore(t, dir)
+	defer s2.Close()
+
+	if c, ok := s2.GetClient("fp1"); !ok || !c.Revoked {
+		t.Errorf("revocation not restored: %+v, ok=%v", c, ok)
+	}
+	if _, ok := s2.GetClient("fp2"); ok {
+		t.Error("deleted client restored")
+	}
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"time"
+)
+
+// Revision is one version of a feature, in effect from its UpdatedAt until
+// the next revision. A deletion is recorded as a final revision with Deleted
+// set: it carries the feature's last state, a version one past it and the
+// time of deletion as UpdatedAt.
+type Revision struct {
+	Feature
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// recordRevisionLocked moves the current version of the feature with the
+// given ID into its history, if it exists. Caller must hold the write lock.
+func (s *Store) recordRevisionLocked(id string) {
+	if f, ok := s.features[id]; ok {
+		s.history[id] = append(s.history[id], Revision{Feature: f})
+	}
+}
+
+// recordDeletionLocked appends a deletion revision for f at time at (the
+// feature's last update time if zero, e.g. for records written before
+// deletions were timestamped). Caller must hold the write lock.
+func (s *Store) recordDeletionLocked(f Feature, at time.Time) {
+	s.recordRevisionLocked(f.ID)
+	f.Version++
+	if !at.IsZero() {
+		f.UpdatedAt = at
+	}
+	s.history[f.ID] = append(s.history[f.ID], Revision{Feature: f, Deleted: true})
+}
+
+// FeatureHistory returns every revision of the feature with the given ID,
+// oldest first, including the current version or its deletion.
+// It reports false if the ID was never used.
+func (s *Store) FeatureHistory(id string) ([]Revision, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	past := s.history[id]
+	f, exists := s.features[id]
+	if len(past) == 0 && !exists {
+		return nil, false
+	}
+	out := make([]Revision, 0, len(past)+1)
+	out = append(out, past...)
+	if exists {
+		out = append(out, Revision{Feature: f})
+	}
+	return out, true
+}
+
+// FeatureAt returns the feature with the given ID as it was at time at.
+// It reports false if the feature did not exist then: not yet created,
+// or deleted by that time.
+func (s *Store) FeatureAt(id string, at time.Time) (Feature, bool) {
+	revs, ok := s.FeatureHistory(id)
+	if !ok {
+		return Feature{}, false
+	}
+	// The revision in effect is the last one made at or before at
+	var cur *Revision
+	for i := range revs {
+		if revs[i].UpdatedAt.After(at) {
+			break
+		}
+		cur = &revs[i]
+	}
+	if cur == nil || cur.Deleted {
+		return Feature{}, false
+	}
+	return cur.Feature, true
+}
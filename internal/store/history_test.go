@@ -0,0 +1,139 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFeatureHistory(t *testing.T) {
+	s := New()
+	f, err := s.CreateFeature("Auth", "Login flow", "Security", []string{"auth"})
+	if err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+	created := f.UpdatedAt
+
+	summary := "Login and logout flow"
+	v2, err := s.UpdateFeature(f.ID, FeatureUpdate{Summary: &summary}, 0)
+	if err != nil {
+		t.Fatalf("UpdateFeature: %v", err)
+	}
+
+	revs, ok := s.FeatureHistory(f.ID)
+	if !ok || len(revs) != 2 {
+		t.Fatalf("FeatureHistory = %d revisions, %v; want 2, true", len(revs), ok)
+	}
+	if revs[0].Version != 1 || revs[0].Summary != "Login flow" {
+		t.Errorf("first revision = %+v", revs[0].Feature)
+	}
+	if revs[1].Version != 2 || revs[1].Summary != summary || revs[1].Deleted {
+		t.Errorf("second revision = %+v", revs[1])
+	}
+
+	if err := s.DeleteFeature(f.ID, 0); err != nil {
+		t.Fatalf("DeleteFeature: %v", err)
+	}
+	revs, ok = s.FeatureHistory(f.ID)
+	if !ok || len(revs) != 3 {
+		t.Fatalf("after delete FeatureHistory = %d revisions, %v; want 3, true", len(revs), ok)
+	}
+	last := revs[2]
+	if !last.Deleted || last.Version != 3 || last.Summary != summary || last.UpdatedAt.Before(v2.UpdatedAt) {
+		t.Errorf("deletion revision = %+v", last)
+	}
+
+	if _, ok := s.FeatureHistory("FT-999999"); ok {
+		t.Error("FeatureHistory of an unknown ID should report false")
+	}
+
+	// Point-in-time reads
+	tests := []struct {
+		name    string
+		at      time.Time
+		wantOK  bool
+		wantVer int64
+	}{
+		{"before creation", created.Add(-time.Second), false, 0},
+		{"at creation", created, true, 1},
+		{"at update", v2.UpdatedAt, true, 2},
+		{"after deletion", last.UpdatedAt.Add(time.Second), false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := s.FeatureAt(f.ID, tt.at)
+			if ok != tt.wantOK || got.Version != tt.wantVer {
+				t.Errorf("FeatureAt(%v) = v%d, %v; want v%d, %v", tt.at, got.Version, ok, tt.wantVer, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFeatureHistory_KeptBySeed(t *testing.T) {
+	s := New()
+	if err := s.SeedFeatures(3); err != nil {
+		t.Fatalf("SeedFeatures: %v", err)
+	}
+	name := "Renamed"
+	if _, err := s.UpdateFeature("FT-000001", FeatureUpdate{Name: &name}, 0); err != nil {
+		t.Fatalf("UpdateFeature: %v", err)
+	}
+	beforeReseed := time.Now()
+	if err := s.SeedFeatures(3); err != nil {
+		t.Fatalf("SeedFeatures: %v", err)
+	}
+
+	// The replaced features end deleted, and the new ones get new IDs
+	revs, _ := s.FeatureHistory("FT-000001")
+	if len(revs) != 3 || revs[1].Name != "Renamed" || !revs[2].Deleted {
+		t.Errorf("FeatureHistory(FT-000001) after reseed = %+v, want created, renamed, deleted", revs)
+	}
+	if f, ok := s.FeatureAt("FT-000001", beforeReseed); !ok || f.Name != "Renamed" {
+		t.Errorf("FeatureAt before the reseed = %q, %v; want Renamed", f.Name, ok)
+	}
+	if _, ok := s.GetFeature("FT-000001"); ok {
+		t.Error("FT-000001 still exists after the reseed")
+	}
+	if revs, ok := s.FeatureHistory("FT-000004"); !ok || len(revs) != 1 {
+		t.Errorf("FeatureHistory(FT-000004) = %d revisions, %v; want the new feature only", len(revs), ok)
+	}
+}
+
+func TestFileBackend_HistoryPersists(t *testing.T) {
+	dir := t.TempDir()
+
+	s := openFileStore(t, dir)
+	f, err := s.CreateFeature("Auth", "Login flow", "Security", nil)
+	if err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+	owner := "Identity"
+	if _, err := s.UpdateFeature(f.ID, FeatureUpdate{Owner: &owner}, 0); err != nil {
+		t.Fatalf("UpdateFeature: %v", err)
+	}
+	if err := s.DeleteFeature(f.ID, 0); err != nil {
+		t.Fatalf("DeleteFeature: %v", err)
+	}
+
+	check := func(t *testing.T, s *Store) {
+		t.Helper()
+		revs, ok := s.FeatureHistory(f.ID)
+		if !ok || len(revs) != 3 {
+			t.Fatalf("FeatureHistory = %d revisions, %v; want 3, true", len(revs), ok)
+		}
+		if revs[0].Owner != "Security" || revs[1].Owner != "Identity" || !revs[2].Deleted {
+			t.Errorf("revisions = %+v", revs)
+		}
+	}
+
+	// Replayed from the WAL
+	s2 := openFileStore(t, dir)
+	check(t, s2)
+	if err := s2.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Restored from the snapshot written on Close
+	s3 := openFileStore(t, dir)
+	defer s3.Close()
+	check(t, s3)
+}
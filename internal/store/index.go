@@ -0,0 +1,229 @@
+package store
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// field is a bit set of the feature fields a term occurs in.
+type field uint8
+
+// Indexed feature fields.
+const (
+	fieldID field = 1 << iota
+	fieldName
+	fieldTags
+	fieldOwner
+	fieldSummary
+	fieldStatus
+)
+
+// fieldWeights is the score a query term earns per field it matches.
+// Exact term matches count double; prefix matches count once.
+// Status is a filter only and earns nothing.
+var fieldWeights = []struct {
+	field  field
+	weight int
+}{
+	{fieldID, 8},
+	{fieldName, 10},
+	{fieldTags, 6},
+	{fieldOwner, 4},
+	{fieldSummary, 2},
+}
+
+// Bonuses applied on top of the term scores so the most specific hits
+// rank first regardless of how many terms they share with the query.
+const (
+	scoreExactID    = 10000
+	scoreExactName  = 5000
+	scoreNamePrefix = 1000
+)
+
+// searchIndex is an inverted index over the text fields of features.
+// It is owned by the Store and guarded by the Store's lock.
+type searchIndex struct {
+	postings map[string]map[string]field // term -> feature ID -> fields
+	terms    []string                    // sorted postings keys, for prefix lookups
+	docTerms map[string][]string         // feature ID -> its terms, for removal
+}
+
+// newSearchIndex builds an index over features.
+func newSearchIndex(features map[string]Feature) *searchIndex {
+	ix := &searchIndex{
+		postings: make(map[string]map[string]field),
+		docTerms: make(map[string][]string, len(features)),
+	}
+	for _, f := range features {
+		ix.index(f)
+	}
+	ix.terms = make([]string, 0, len(ix.postings))
+	for t := range ix.postings {
+		ix.terms = append(ix.terms, t)
+	}
+	sort.Strings(ix.terms)
+	return ix
+}
+
+// put indexes f, replacing any previous version of it.
+func (ix *searchIndex) put(f Feature) {
+	ix.remove(f.ID)
+	for _, t := range ix.index(f) {
+		i, found := slices.BinarySearch(ix.terms, t)
+		if !found {
+			ix.terms = slices.Insert(ix.terms, i, t)
+		}
+	}
+}
+
+// remove drops the feature with the given ID from the index.
+func (ix *searchIndex) remove(id string) {
+	for _, t := range ix.docTerms[id] {
+		docs := ix.postings[t]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(ix.postings, t)
+			if i, found := slices.BinarySearch(ix.terms, t); found {
+				ix.terms = slices.Delete(ix.terms, i, i+1)
+			}
+		}
+	}
+	delete(ix.docTerms, id)
+}
+
+// index adds postings for f and returns the terms that are new to the
+// index. The caller is responsible for keeping ix.terms sorted.
+func (ix *searchIndex) index(f Feature) []string {
+	fields := make(map[string]field)
+	addTerms := func(fl field, text string) {
+		for _, t := range tokenize(text) {
+			fields[t] |= fl
+		}
+	}
+	addTerms(fieldID, f.ID)
+	addTerms(fieldName, f.Name)
+	addTerms(fieldOwner, f.Owner)
+	addTerms(fieldSummary, f.Summary)
+	addTerms(fieldStatus, string(f.Status))
+	for _, tag := range f.Tags {
+		addTerms(fieldTags, tag)
+	}
+
+	var added []string
+	terms := make([]string, 0, len(fields))
+	for t, fl := range fields {
+		docs, ok := ix.postings[t]
+		if !ok {
+			docs = make(map[string]field)
+			ix.postings[t] = docs
+			added = append(added, t)
+		}
+		docs[f.ID] = fl
+		terms = append(terms, t)
+	}
+	ix.docTerms[f.ID] = terms
+	return added
+}
+
+// match returns the term score of every feature that matches all query
+// tokens within the given fields. Each token matches a whole term, or
+// with prefix set also the start of a term.
+func (ix *searchIndex) match(tokens []string, fields field, prefix bool) map[string]int {
+	var scores map[string]int
+	for _, tok := range tokens {
+		best := make(map[string]int)
+		for i := sort.SearchStrings(ix.terms, tok); i < len(ix.terms) && strings.HasPrefix(ix.terms[i], tok); i++ {
+			term := ix.terms[i]
+			if !prefix && term != tok {
+				break // Sorted: the exact term, if present, comes first
+			}
+			for id, fl := range ix.postings[term] {
+				fl &= fields
+				if fl == 0 {
+					continue
+				}
+				if scores != nil {
+					if _, ok := scores[id]; !ok {
+						continue // Already ruled out by an earlier token
+					}
+				}
+				sc := termScore(fl, term == tok)
+				if cur, ok := best[id]; !ok || sc > cur {
+					best[id] = sc
+				}
+			}
+		}
+		if scores == nil {
+			scores = best
+			continue
+		}
+		for id, sc := range scores {
+			if b, ok := best[id]; ok {
+				scores[id] = sc + b
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+	return scores
+}
+
+// termScore weighs a term occurrence by the fields it appears in.
+func termScore(fl field, exact bool) int {
+	sc := 0
+	for _, fw := range fieldWeights {
+		if fl&fw.field != 0 {
+			sc += fw.weight
+		}
+	}
+	if exact {
+		sc *= 2
+	}
+	return sc
+}
+
+// tokenize lowercases s and splits it into letter/digit runs.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// scoredFeature is a search hit with its relevance score.
+type scoredFeature struct {
+	id    string
+	score int
+}
+
+// rankLocked returns the features matching the parsed query n, best
+// first (ties by ID). Caller must hold a lock.
+func (s *Store) rankLocked(n queryNode) []scoredFeature {
+	scores := s.evalLocked(n)
+	q := freeText(n)
+
+	out := make([]scoredFeature, 0, len(scores))
+	for id, sc := range scores {
+		if q != "" {
+			f := s.features[id]
+			name := strings.ToLower(f.Name)
+			switch {
+			case strings.EqualFold(f.ID, q):
+				sc += scoreExactID
+			case name == q:
+				sc += scoreExactName
+			case strings.HasPrefix(name, q):
+				sc += scoreNamePrefix
+			}
+		}
+		out = append(out, scoredFeature{id: id, score: sc})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].score != out[j].score {
+			return out[i].score > out[j].score
+		}
+		return out[i].id < out[j].id
+	})
+	return out
+}
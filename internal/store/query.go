@@ -0,0 +1,451 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidQuery is returned when a search query cannot be parsed.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// queryFields maps the field names accepted in field:value terms to
+// the index fields they search.
+var queryFields = map[string]field{
+	"id":      fieldID,
+	"name":    fieldName,
+	"summary": fieldSummary,
+	"owner":   fieldOwner,
+	"tag":     fieldTags,
+	"tags":    fieldTags,
+	"status":  fieldStatus,
+}
+
+// queryNode is a node of a parsed search query.
+type queryNode interface {
+	isQueryNode()
+}
+
+// termNode matches features containing all tokens in one of fields.
+// Unless phrase is set each token may also match the start of a word;
+// a phrase requires exactly these words, consecutively.
+type termNode struct {
+	fields field
+	tokens []string
+	phrase bool
+	text   string // Original text, for relevance bonuses
+}
+
+// notNode matches features its child does not match.
+type notNode struct{ child queryNode }
+
+// andNode matches features every child matches.
+type andNode struct{ children []queryNode }
+
+// orNode matches features any child matches.
+type orNode struct{ children []queryNode }
+
+func (termNode) isQueryNode() {}
+func (notNode) isQueryNode()  {}
+func (andNode) isQueryNode()  {}
+func (orNode) isQueryNode()   {}
+
+// Query syntax:
+//
+//	dark mode              features matching both words (AND is implicit)
+//	"dark mode"            the exact phrase
+//	owner:payments         a word in a specific field (id, name, summary,
+//	tag:"one click"        owner, tag/tags, status); values may be phrases,
+//	                       and other words with a colon are plain text
+//	-status:deprecated     negation; NOT works too
+//	a OR b, (a OR b) AND c boolean operators (upper case) and grouping
+//
+// Words match whole words or word prefixes in any field except status.
+
+// parseQuery parses q into a query tree. It returns nil for a query with
+// nothing to match (e.g. only whitespace or punctuation).
+func parseQuery(q string) (queryNode, error) {
+	toks, err := lexQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{toks: toks}
+	if len(p.toks) == 0 {
+		return nil, nil //nolint:nilnil // nil node means "match everything"
+	}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidQuery, p.toks[p.pos].text)
+	}
+	return n, nil
+}
+
+// queryTokenKind classifies lexer output.
+type queryTokenKind int
+
+const (
+	tokTerm queryTokenKind = iota
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+// queryToken is a lexed piece of a query. For tokTerm, node is set.
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	node termNode
+}
+
+// textFields are the fields unscoped words search.
+const textFields = fieldID | fieldName | fieldTags | fieldOwner | fieldSummary
+
+// Query size limits, which keep parsing and evaluation cheap whatever a
+// client sends: the number of tokens (words, operators and parentheses) and
+// how deeply negations and parentheses may nest.
+const (
+	maxQueryTokens = 64
+	maxQueryDepth  = 16
+)
+
+// lexQuery splits q into operators, parentheses and terms.
+// Terms with no indexable characters are dropped.
+func lexQuery(q string) ([]queryToken, error) {
+	var toks []queryToken
+	tooLong := fmt.Errorf("%w: more than %d terms and operators", ErrInvalidQuery, maxQueryTokens)
+	i := 0
+	for i < len(q) {
+		if len(toks) > maxQueryTokens {
+			return nil, tooLong
+		}
+		switch c := q[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			toks = append(toks, queryToken{kind: tokLParen, text: "("})
+			i++
+		case c == ')':
+			toks = append(toks, queryToken{kind: tokRParen, text: ")"})
+			i++
+		case c == '-' && i+1 < len(q) && q[i+1] != ' ':
+			toks = append(toks, queryToken{kind: tokNot, text: "-"})
+			i++
+		case c == '"':
+			phrase, next, err := readPhrase(q, i)
+			if err != nil {
+				return nil, err
+			}
+			i = next
+			if t, ok := newTerm(textFields, phrase, true); ok {
+				toks = append(toks, queryToken{kind: tokTerm, text: phrase, node: t})
+			}
+		default:
+			start := i
+			for i < len(q) && !strings.ContainsRune(" \t\r\n()\"", rune(q[i])) {
+				i++
+			}
+			word := q[start:i]
+			switch word {
+			case "AND":
+				toks = append(toks, queryToken{kind: tokAnd, text: word})
+				continue
+			case "OR":
+				toks = append(toks, queryToken{kind: tokOr, text: word})
+				continue
+			case "NOT":
+				toks = append(toks, queryToken{kind: tokNot, text: word})
+				continue
+			}
+
+			// Only known field names make a field:value term; other words with
+			// a colon, such as URLs or "v1:beta", are plain text
+			fields, value, phrase := textFields, word, false
+			name, rest, ok := strings.Cut(word, ":")
+			if fl, known := queryFields[strings.ToLower(name)]; ok && known {
+				fields, value = fl, rest
+				if rest == "" && i < len(q) && q[i] == '"' {
+					p, next, err := readPhrase(q, i)
+					if err != nil {
+						return nil, err
+					}
+					value, phrase, i = p, true, next
+				}
+				if value == "" {
+					return nil, fmt.Errorf("%w: missing value for %s:", ErrInvalidQuery, name)
+				}
+			}
+			if t, ok := newTerm(fields, value, phrase); ok {
+				toks = append(toks, queryToken{kind: tokTerm, text: word, node: t})
+			}
+		}
+	}
+	if len(toks) > maxQueryTokens {
+		return nil, tooLong
+	}
+	return toks, nil
+}
+
+// readPhrase reads a quoted phrase starting at the quote at q[i] and
+// returns its content and the index just past the closing quote.
+func readPhrase(q string, i int) (string, int, error) {
+	end := strings.IndexByte(q[i+1:], '"')
+	if end < 0 {
+		return "", 0, fmt.Errorf("%w: unterminated quote", ErrInvalidQuery)
+	}
+	return q[i+1 : i+1+end], i + end + 2, nil
+}
+
+// newTerm builds a term for text, reporting false if it has no words.
+func newTerm(fields field, text string, phrase bool) (termNode, bool) {
+	tokens := tokenize(text)
+	if len(tokens) == 0 {
+		return termNode{}, false
+	}
+	return termNode{fields: fields, tokens: tokens, phrase: phrase, text: strings.ToLower(text)}, true
+}
+
+// queryParser is a recursive descent parser over lexed tokens.
+type queryParser struct {
+	toks  []queryToken
+	pos   int
+	depth int // negations and parentheses around the current position
+}
+
+// parseOr parses: and ("OR" and)*
+func (p *queryParser) parseOr() (queryNode, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []queryNode{first}
+	for p.peek(tokOr) {
+		p.pos++
+		n, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, n)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return orNode{children: children}, nil
+}
+
+// parseAnd parses: unary (["AND"] unary)*
+func (p *queryParser) parseAnd() (queryNode, error) {
+	var children []queryNode
+	for {
+		if p.peek(tokAnd) {
+			if len(children) == 0 {
+				return nil, fmt.Errorf("%w: AND without a left operand", ErrInvalidQuery)
+			}
+			p.pos++
+		} else if p.pos >= len(p.toks) || p.peek(tokOr) || p.peek(tokRParen) {
+			break
+		}
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, n)
+	}
+	switch len(children) {
+	case 0:
+		return nil, fmt.Errorf("%w: missing search term", ErrInvalidQuery)
+	case 1:
+		return children[0], nil
+	default:
+		return andNode{children: children}, nil
+	}
+}
+
+// parseUnary parses: ("-" | "NOT") unary | "(" or ")" | term
+func (p *queryParser) parseUnary() (queryNode, error) {
+	if p.pos >= len(p.toks) {
+		return nil, fmt.Errorf("%w: missing search term", ErrInvalidQuery)
+	}
+	tok := p.toks[p.pos]
+	p.pos++
+	if tok.kind == tokNot || tok.kind == tokLParen {
+		p.depth++
+		defer func() { p.depth-- }()
+		if p.depth > maxQueryDepth {
+			return nil, fmt.Errorf("%w: nested more than %d deep", ErrInvalidQuery, maxQueryDepth)
+		}
+	}
+	switch tok.kind {
+	case tokNot:
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		// Two negations cancel out
+		if not, ok := n.(notNode); ok {
+			return not.child, nil
+		}
+		return notNode{child: n}, nil
+	case tokLParen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(tokRParen) {
+			return nil, fmt.Errorf("%w: missing )", ErrInvalidQuery)
+		}
+		p.pos++
+		return n, nil
+	case tokTerm:
+		return tok.node, nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidQuery, tok.text)
+	}
+}
+
+// peek reports whether the next token is of the given kind.
+func (p *queryParser) peek(kind queryTokenKind) bool {
+	return p.pos < len(p.toks) && p.toks[p.pos].kind == kind
+}
+
+// evalLocked returns the features matching n with their term scores.
+// Caller must hold a lock.
+func (s *Store) evalLocked(n queryNode) map[string]int {
+	switch n := n.(type) {
+	case termNode:
+		scores := s.index.match(n.tokens, n.fields, !n.phrase)
+		if n.phrase {
+			for id := range scores {
+				if !containsPhrase(s.features[id], n.fields, n.tokens) {
+					delete(scores, id)
+				}
+			}
+		}
+		return scores
+	case notNode:
+		return s.exceptLocked(s.evalLocked(n.child))
+	case orNode:
+		scores := make(map[string]int)
+		for _, c := range n.children {
+			for id, sc := range s.evalLocked(c) {
+				scores[id] += sc
+			}
+		}
+		return scores
+	case andNode:
+		// Negations only narrow the result, so evaluate them last and
+		// as exclusions rather than materialising their complement.
+		var scores map[string]int
+		var excluded []map[string]int
+		for _, c := range n.children {
+			if not, ok := c.(notNode); ok {
+				excluded = append(excluded, s.evalLocked(not.child))
+				continue
+			}
+			m := s.evalLocked(c)
+			if scores == nil {
+				scores = m
+				continue
+			}
+			for id, sc := range scores {
+				if add, ok := m[id]; ok {
+					scores[id] = sc + add
+				} else {
+					delete(scores, id)
+				}
+			}
+		}
+		if scores == nil {
+			scores = s.exceptLocked(nil)
+		}
+		for _, ex := range excluded {
+			maps.DeleteFunc(scores, func(id string, _ int) bool {
+				_, ok := ex[id]
+				return ok
+			})
+		}
+		return scores
+	}
+	return nil
+}
+
+// exceptLocked returns every feature not in m, with a zero score.
+// Caller must hold a lock.
+func (s *Store) exceptLocked(m map[string]int) map[string]int {
+	out := make(map[string]int, len(s.featureIDs)-len(m))
+	for _, id := range s.featureIDs {
+		if _, ok := m[id]; !ok {
+			out[id] = 0
+		}
+	}
+	return out
+}
+
+// containsPhrase reports whether one of the given fields of f contains
+// tokens as consecutive words.
+func containsPhrase(f Feature, fields field, tokens []string) bool {
+	for _, text := range fieldTexts(f, fields) {
+		words := tokenize(text)
+		for i := 0; i+len(tokens) <= len(words); i++ {
+			if slices.Equal(words[i:i+len(tokens)], tokens) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldTexts returns the raw text of the given fields of f.
+func fieldTexts(f Feature, fields field) []string {
+	var out []string
+	if fields&fieldID != 0 {
+		out = append(out, f.ID)
+	}
+	if fields&fieldName != 0 {
+		out = append(out, f.Name)
+	}
+	if fields&fieldTags != 0 {
+		out = append(out, f.Tags...)
+	}
+	if fields&fieldOwner != 0 {
+		out = append(out, f.Owner)
+	}
+	if fields&fieldSummary != 0 {
+		out = append(out, f.Summary)
+	}
+	if fields&fieldStatus != 0 {
+		out = append(out, string(f.Status))
+	}
+	return out
+}
+
+// freeText returns the unscoped, non-negated text of n, which is what
+// exact-ID and name-prefix bonuses are measured against.
+func freeText(n queryNode) string {
+	var parts []string
+	var walk func(queryNode)
+	walk = func(n queryNode) {
+		switch n := n.(type) {
+		case termNode:
+			if n.fields == textFields {
+				parts = append(parts, n.text)
+			}
+		case andNode:
+			for _, c := range n.children {
+				walk(c)
+			}
+		case orNode:
+			for _, c := range n.children {
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return strings.Join(parts, " ")
+}
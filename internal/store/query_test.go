@@ -0,0 +1,144 @@
+package store
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newQueryTestStore returns a store with a small catalog for query tests.
+func newQueryTestStore(t *testing.T) *Store {
+	t.Helper()
+	s := New()
+	for _, f := range []struct {
+		name, summary, owner string
+		tags                 []string
+	}{
+		{"Dark Mode", "Switch the UI to a dark theme", "Design", []string{"ui", "theme"}},
+		{"One Click Checkout", "Buy in one click", "Payments", []string{"checkout"}},
+		{"Saved Cards", "Remember cards for checkout", "Payments", []string{"checkout", "cards"}},
+		{"Mode Switcher", "Dark and light mode toggle", "Platform", []string{"ui"}},
+		{"Invoices", "Monthly invoices", "Billing Team", []string{"billing"}},
+	} {
+		if _, err := s.CreateFeature(f.name, f.summary, f.owner, f.tags); err != nil {
+			t.Fatalf("CreateFeature: %v", err)
+		}
+	}
+	// FT-000003 is on its way out
+	st := StatusDeprecated
+	if _, err := s.UpdateFeature("FT-000003", FeatureUpdate{Status: &st}, 0); err != nil {
+		t.Fatalf("UpdateFeature: %v", err)
+	}
+	return s
+}
+
+func TestSearchQuerySyntax(t *testing.T) {
+	s := newQueryTestStore(t)
+
+	tests := []struct {
+		query string
+		want  []string // IDs in any order
+	}{
+		{"dark", []string{"FT-000001", "FT-000004"}},
+		{`"dark mode"`, []string{"FT-000001"}},
+		{`"mode dark"`, nil},
+		{"owner:payments", []string{"FT-000002", "FT-000003"}},
+		{"OWNER:Payments", []string{"FT-000002", "FT-000003"}},
+		{`owner:"billing team"`, []string{"FT-000005"}},
+		{"tag:checkout", []string{"FT-000002", "FT-000003"}},
+		{"tags:check", []string{"FT-000002", "FT-000003"}},
+		{"status:deprecated", []string{"FT-000003"}},
+		{"tag:checkout -status:deprecated", []string{"FT-000002"}},
+		{"tag:checkout NOT status:deprecated", []string{"FT-000002"}},
+		{"-tag:ui", []string{"FT-000002", "FT-000003", "FT-000005"}},
+		{"invoices OR cards", []string{"FT-000003", "FT-000005"}},
+		{"ui AND dark", []string{"FT-000001", "FT-000004"}},
+		{"(owner:design OR owner:platform) -name:switcher", []string{"FT-000001"}},
+		{"name:mode", []string{"FT-000001", "FT-000004"}},
+		{"summary:mode", []string{"FT-000004"}},
+		{"id:ft-000005", []string{"FT-000005"}},
+		{"active", nil}, // status is only searchable with status:
+		{"NOT NOT dark", []string{"FT-000001", "FT-000004"}},
+		{"--dark", []string{"FT-000001", "FT-000004"}},
+		{strings.Repeat("(", maxQueryDepth) + "dark" + strings.Repeat(")", maxQueryDepth), []string{"FT-000001", "FT-000004"}},
+		{strings.TrimSpace(strings.Repeat("dark ", maxQueryTokens)), []string{"FT-000001", "FT-000004"}},
+		{"color:dark", nil}, // not a field, so a plain word that matches nothing
+		{"ft-000002:", []string{"FT-000002"}},
+		{"https://example.org/dark", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			page, err := s.SearchPage(tt.query, 50, "")
+			if err != nil {
+				t.Fatalf("SearchPage(%q): %v", tt.query, err)
+			}
+			got := make([]string, len(page.Items))
+			for i, f := range page.Items {
+				got[i] = f.ID
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SearchPage(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryDoubleNegation(t *testing.T) {
+	for _, q := range []string{"NOT NOT dark", "--dark", "-(NOT dark)", "NOT NOT NOT NOT dark"} {
+		if n, err := parseQuery(q); err != nil || n.(termNode).text != "dark" {
+			t.Errorf("parseQuery(%q) = %#v, %v; want the term dark", q, n, err)
+		}
+	}
+	if n, err := parseQuery("NOT NOT NOT dark"); err != nil {
+		t.Errorf("parseQuery(NOT NOT NOT dark): %v", err)
+	} else if _, ok := n.(notNode); !ok {
+		t.Errorf("parseQuery(NOT NOT NOT dark) = %#v, want a single negation", n)
+	}
+}
+
+func TestSearchQueryErrors(t *testing.T) {
+	s := newQueryTestStore(t)
+
+	for _, q := range []string{
+		`"dark mode`,
+		"owner:",
+		"(dark OR mode",
+		"dark)",
+		"OR dark",
+		"dark AND",
+		"AND dark",
+		strings.Repeat("-", maxQueryDepth+1) + "dark",
+		strings.Repeat("NOT ", maxQueryDepth+1) + "dark",
+		strings.Repeat("(", maxQueryDepth+1) + "dark" + strings.Repeat(")", maxQueryDepth+1),
+		strings.Repeat("dark ", maxQueryTokens+1),
+		strings.Repeat("dark OR ", maxQueryTokens) + "mode",
+	} {
+		t.Run(q, func(t *testing.T) {
+			if _, err := s.SearchPage(q, 10, ""); !errors.Is(err, ErrInvalidQuery) {
+				t.Errorf("SearchPage(%q) error = %v, want ErrInvalidQuery", q, err)
+			}
+			if got := s.Suggest(q, 10); len(got) != 0 {
+				t.Errorf("Suggest(%q) returned %d results, want none", q, len(got))
+			}
+		})
+	}
+
+	// Queries without any words are not errors: they list everything
+	page, err := s.SearchPage(" - ", 10, "")
+	if err != nil || len(page.Items) != 5 {
+		t.Errorf("SearchPage(\" - \") = %d items, %v; want 5, nil", len(page.Items), err)
+	}
+}
+
+func TestSearchQueryRanking(t *testing.T) {
+	s := newQueryTestStore(t)
+
+	// Field filters don't disturb the name-prefix bonus of the free text
+	got := s.SearchFeatures("mode tag:ui", 10)
+	if len(got) != 2 || got[0].ID != "FT-000004" {
+		t.Errorf("SearchFeatures(mode tag:ui) = %v, want FT-000004 first", got)
+	}
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"slices"
+	"strings"
+)
+
+// Permission is a single action a client may be allowed to perform.
+type Permission string
+
+// Permission constants define what roles can grant.
+const (
+	PermFeaturesRead     Permission = "features:read"
+	PermFeaturesWriteOwn Permission = "features:write:own" // create and update features owned by the client's teams
+	PermFeaturesWrite    Permission = "features:write"     // create and update any feature
+	PermFeaturesDelete   Permission = "features:delete"    // delete features
+	PermClientsManage    Permission = "clients:manage"     // register, update, revoke and delete clients
+	PermCatalogSeed      Permission = "catalog:seed"       // replace the whole catalog
+	PermAuditRead        Permission = "audit:read"
+)
+
+// Additional roles between user and admin.
+const (
+	RoleEditor     Role = "editor"
+	RoleMaintainer Role = "maintainer"
+)
+
+// rolePermissions lists the permissions each role grants.
+// A client has exactly one role; roles are not hierarchical by declaration,
+// but each one here includes everything the previous one does.
+var rolePermissions = map[Role][]Permission{
+	RoleUser:       {PermFeaturesRead, PermFeaturesWriteOwn},
+	RoleEditor:     {PermFeaturesRead, PermFeaturesWriteOwn, PermFeaturesWrite},
+	RoleMaintainer: {PermFeaturesRead, PermFeaturesWriteOwn, PermFeaturesWrite, PermFeaturesDelete, PermCatalogSeed},
+	RoleAdmin: {
+		PermFeaturesRead, PermFeaturesWriteOwn, PermFeaturesWrite, PermFeaturesDelete,
+		PermClientsManage, PermCatalogSeed, PermAuditRead,
+	},
+}
+
+// Roles returns every known role, from least to most privileged.
+func Roles() []Role {
+	return []Role{RoleUser, RoleEditor, RoleMaintainer, RoleAdmin}
+}
+
+// ParseRole converts a string to a Role, reporting whether it is known.
+func ParseRole(s string) (Role, bool) {
+	r := Role(strings.ToLower(strings.TrimSpace(s)))
+	_, ok := rolePermissions[r]
+	return r, ok
+}
+
+// Permissions returns the permissions granted by r (none for an unknown role).
+func (r Role) Permissions() []Permission {
+	return slices.Clone(rolePermissions[r])
+}
+
+// Can reports whether r grants p.
+func (r Role) Can(p Permission) bool {
+	return slices.Contains(rolePermissions[r], p)
+}
+
+// OwnsFeatures reports whether owner is one of the client's teams.
+// Teams match owners case-insensitively; an empty owner matches no team.
+func (c Client) OwnsFeatures(owner string) bool {
+	owner = strings.TrimSpace(owner)
+	if owner == "" {
+		return false
+	}
+	return slices.ContainsFunc(c.Teams, func(t string) bool { return strings.EqualFold(t, owner) })
+}
+
+// CanWriteFeature reports whether the client may create or update a
+// feature owned by owner: anywhere with features:write, or within its
+// own teams with features:write:own.
+func (c Client) CanWriteFeature(owner string) bool {
+	return c.Role.Can(PermFeaturesWrite) || (c.Role.Can(PermFeaturesWriteOwn) && c.OwnsFeatures(owner))
+}
+
+// NormalizeTeams trims team names and drops empty and duplicate
+// (case-insensitive) entries, keeping the first spelling of each.
+func NormalizeTeams(teams []string) []string {
+	out := make([]string, 0, len(teams))
+	for _, t := range teams {
+		t = strings.TrimSpace(t)
+		if t == "" || slices.ContainsFunc(out, func(o string) bool { return strings.EqualFold(o, t) }) {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
@@ -1,10 +1,17 @@
-// Package store provides an in-memory data store for clients and features.
+// Package store provides a data store for clients and features.
+// The working set is held in memory; a pluggable Backend makes it durable.
 package store
 
 import (
 	"crypto/sha256"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -17,43 +24,311 @@ import (
 // Role represents the authorization level of a client.
 type Role string
 
-// Role constants define the authorization levels.
+// Role constants define the authorization levels. Each role grants a set
+// of permissions; see rbac.go for the remaining roles and the mapping.
 const (
 	RoleUser  Role = "user"
 	RoleAdmin Role = "admin"
 )
 
 // Client represents a registered mTLS client.
+// A revoked client stays registered so its certificate cannot quietly be
+// registered again, but it is refused by the mTLS middleware.
 type Client struct {
 	Fingerprint string    `json:"fingerprint"`
 	Name        string    `json:"name"`
 	Role        Role      `json:"role"`
+	Teams       []string  `json:"teams,omitempty"` // feature owners the client may edit with features:write:own
 	CreatedAt   time.Time `json:"created_at"`
+	Revoked     bool      `json:"revoked,omitempty"`
+	RevokedAt   time.Time `json:"revoked_at,omitzero"`
+	Source      string    `json:"source,omitempty"` // who manages the client: empty for the API, or a SyncClients source
+	// PreviousFingerprint is the certificate the last renewal replaced. It
+	// still authenticates as the client until the new one is first used, so
+	// a renewal whose response was lost can be retried.
+	PreviousFingerprint string `json:"previous_fingerprint,omitempty"`
+}
+
+// Status represents the lifecycle stage of a feature.
+type Status string
+
+// Status constants define the feature lifecycle.
+const (
+	StatusProposed   Status = "proposed"
+	StatusActive     Status = "active"
+	StatusDeprecated Status = "deprecated"
+	StatusRemoved    Status = "removed"
+)
+
+// statusTransitions lists the statuses reachable from each status.
+// Removed is terminal.
+var statusTransitions = map[Status][]Status{
+	StatusProposed:   {StatusActive, StatusRemoved},
+	StatusActive:     {StatusDeprecated},
+	StatusDeprecated: {StatusActive, StatusRemoved},
+	StatusRemoved:    nil,
+}
+
+// ParseStatus converts a string to a Status, reporting whether it is valid.
+func ParseStatus(s string) (Status, bool) {
+	st := Status(strings.ToLower(strings.TrimSpace(s)))
+	_, ok := statusTransitions[st]
+	return st, ok
+}
+
+// CanTransitionTo reports whether a feature in status s may move to next.
+// Staying in the same status is always allowed.
+func (s Status) CanTransitionTo(next Status) bool {
+	return s == next || slices.Contains(statusTransitions[s], next)
 }
 
 // Feature represents a feature catalog entry.
+// Version starts at 1 and increases with every update; it backs the
+// optimistic concurrency checks of UpdateFeature and DeleteFeature.
+// Status moves through the lifecycle according to Status.CanTransitionTo.
 type Feature struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Summary   string    `json:"summary"`
-	Owner     string    `json:"owner"`
-	Tags      []string  `json:"tags"`
-	CreatedAt time.Time `json:"created_at"`
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Summary    string    `json:"summary"`
+	Owner      string    `json:"owner"`
+	Tags       []string  `json:"tags"`
+	Status     Status    `json:"status"`
+	ReplacedBy string    `json:"replaced_by,omitempty"` // successor of a deprecated/removed feature
+	Version    int64     `json:"version"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// FeatureUpdate describes a change to a feature. Nil fields are left unchanged.
+type FeatureUpdate struct {
+	Name       *string
+	Summary    *string
+	Owner      *string
+	Tags       *[]string
+	Status     *Status
+	ReplacedBy *string
 }
 
-// Store is a thread-safe in-memory data store.
+// Store is a thread-safe data store. Reads are served from memory;
+// mutations are written ahead to the Backend before being applied.
 type Store struct {
 	mu         sync.RWMutex
+	backend    Backend
+	seq        uint64 // sequence number of the last committed record
+	pending    int    // records appended since the last compaction
 	clients    map[string]Client
+	renewed    map[string]string // current fingerprint by PreviousFingerprint
 	features   map[string]Feature
-	featureIDs []string // stable ordering for demo output
+	featureIDs []string              // sorted by ID, which is also creation order
+	index      *searchIndex          // full-text index over features
+	lastIDNum  int                   // highest feature number assigned; deleted IDs are not reused
+	audit      []AuditEntry          // append-only, oldest first
+	history    map[string][]Revision // superseded revisions by feature ID, oldest first
+	events     eventFeed             // recent catalog changes and their subscribers
 }
 
-// New creates a new empty Store.
+// compactThreshold is the number of appended records that triggers a snapshot.
+const compactThreshold = 1000
+
+// Errors returned by feature mutations.
+var (
+	ErrIDSpaceExhausted   = errors.New("feature ID space exhausted")
+	ErrFeatureNotFound    = errors.New("feature not found")
+	ErrVersionConflict    = errors.New("feature version conflict")
+	ErrInvalidStatus      = errors.New("invalid status transition")
+	ErrInvalidReplacement = errors.New("invalid replaced_by")
+	ErrInvalidSeedCount   = errors.New("invalid seed count")
+)
+
+// Errors returned by client mutations.
+var (
+	ErrClientNotFound = errors.New("client not found")
+	ErrClientRevoked  = errors.New("client revoked")
+	ErrClientExists   = errors.New("client already registered")
+	ErrClientManaged  = errors.New("client is managed by the clients file; change it there")
+)
+
+// New creates a new empty Store that keeps all state in memory.
 func New() *Store {
+	return newStore(NewMemoryBackend())
+}
+
+// Open creates a Store backed by b and restores any state b holds.
+func Open(b Backend) (*Store, error) {
+	snap, recs, err := b.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load store: %w", err)
+	}
+
+	s := newStore(b)
+	if snap != nil {
+		s.restore(snap)
+	}
+	for _, rec := range recs {
+		// Records already folded into the snapshot are skipped
+		if rec.Seq <= s.seq {
+			continue
+		}
+		s.apply(rec)
+		s.seq = rec.Seq
+		s.pending++
+	}
+	s.resetFeedLocked()
+	return s, nil
+}
+
+// newStore creates an empty Store using the given backend.
+func newStore(b Backend) *Store {
 	return &Store{
+		backend:  b,
 		clients:  make(map[string]Client),
+		renewed:  make(map[string]string),
 		features: make(map[string]Feature),
+		index:    newSearchIndex(nil),
+		history:  make(map[string][]Revision),
+	}
+}
+
+// Close writes a final snapshot if needed and closes the backend.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var compactErr error
+	if s.pending > 0 {
+		compactErr = s.compactLocked()
+	}
+	return errors.Join(compactErr, s.backend.Close())
+}
+
+// commitLocked persists rec and then applies it to the in-memory state.
+// Caller must hold the write lock.
+func (s *Store) commitLocked(rec Record) error {
+	rec.Seq = s.seq + 1
+	if err := s.backend.Append(rec); err != nil {
+		return fmt.Errorf("persist %s: %w", rec.Op, err)
+	}
+	ev, publish := s.eventLocked(rec)
+	s.seq = rec.Seq
+	s.apply(rec)
+	if publish {
+		ev.ID = rec.Seq
+		s.publishLocked(ev)
+	}
+
+	s.pending++
+	if s.pending >= compactThreshold {
+		// The record is already durable; a failed compaction is retried
+		// on the next commit, so the error is not surfaced here.
+		_ = s.compactLocked() //nolint:errcheck // Retried on next commit
+	}
+	return nil
+}
+
+// compactLocked replaces the backend's log with a snapshot of the current state.
+// Caller must hold the write lock.
+func (s *Store) compactLocked() error {
+	if err := s.backend.Compact(s.snapshotLocked()); err != nil {
+		return fmt.Errorf("compact: %w", err)
+	}
+	s.pending = 0
+	return nil
+}
+
+// snapshotLocked captures the current state. Caller must hold a lock.
+func (s *Store) snapshotLocked() *Snapshot {
+	snap := &Snapshot{
+		Seq:       s.seq,
+		LastIDNum: s.lastIDNum,
+		Clients:   make([]Client, 0, len(s.clients)),
+		Features:  make([]Feature, 0, len(s.featureIDs)),
+	}
+	for _, c := range s.clients {
+		snap.Clients = append(snap.Clients, c)
+	}
+	sort.Slice(snap.Clients, func(i, j int) bool { return snap.Clients[i].Fingerprint < snap.Clients[j].Fingerprint })
+	for _, id := range s.featureIDs {
+		snap.Features = append(snap.Features, s.features[id])
+	}
+	snap.Audit = s.audit
+	snap.History = s.history
+	return snap
+}
+
+// restore replaces the in-memory state with snap.
+func (s *Store) restore(snap *Snapshot) {
+	s.seq = snap.Seq
+	s.clients = make(map[string]Client, len(snap.Clients))
+	s.renewed = make(map[string]string)
+	for _, c := range snap.Clients {
+		s.putClientLocked(c)
+	}
+	s.features = make(map[string]Feature, len(snap.Features))
+	s.featureIDs = make([]string, 0, len(snap.Features))
+	s.lastIDNum = snap.LastIDNum
+	for _, f := range snap.Features {
+		normalizeFeature(&f)
+		s.features[f.ID] = f
+		s.featureIDs = append(s.featureIDs, f.ID)
+		s.lastIDNum = max(s.lastIDNum, featureNum(f.ID))
+	}
+	slices.Sort(s.featureIDs)
+	s.index = newSearchIndex(s.features)
+	s.audit = snap.Audit
+	s.history = snap.History
+	if s.history == nil {
+		s.history = make(map[string][]Revision)
+	}
+}
+
+// insertFeatureIDLocked adds id to featureIDs, keeping it sorted. New IDs
+// come after every other, so this is an append unless a log written before
+// IDs only increased is replayed.
+func (s *Store) insertFeatureIDLocked(id string) {
+	i, _ := slices.BinarySearch(s.featureIDs, id)
+	s.featureIDs = slices.Insert(s.featureIDs, i, id)
+}
+
+// apply mutates the in-memory state according to rec.
+// Used both for live commits and for replaying the log on Open.
+func (s *Store) apply(rec Record) {
+	switch rec.Op {
+	case OpUpsertClient:
+		if rec.Client != nil {
+			s.putClientLocked(*rec.Client)
+		}
+	case OpDeleteClient:
+		s.deleteClientLocked(rec.Fingerprint)
+	case OpRekeyClient:
+		if rec.Client != nil {
+			s.deleteClientLocked(rec.Fingerprint)
+			s.putClientLocked(*rec.Client)
+		}
+	case OpPutFeature:
+		if rec.Feature != nil {
+			normalizeFeature(rec.Feature)
+			if _, exists := s.features[rec.Feature.ID]; !exists {
+				s.insertFeatureIDLocked(rec.Feature.ID)
+			} else {
+				s.recordRevisionLocked(rec.Feature.ID)
+			}
+			s.features[rec.Feature.ID] = *rec.Feature
+			s.index.put(*rec.Feature)
+			s.lastIDNum = max(s.lastIDNum, featureNum(rec.Feature.ID))
+		}
+	case OpDeleteFeature:
+		if f, exists := s.features[rec.FeatureID]; exists {
+			s.recordDeletionLocked(f, rec.Time)
+			delete(s.features, rec.FeatureID)
+			s.index.remove(rec.FeatureID)
+			if i, found := slices.BinarySearch(s.featureIDs, rec.FeatureID); found {
+				s.featureIDs = slices.Delete(s.featureIDs, i, i+1)
+			}
+		}
+	}
+	// Any record may carry the audit entry for its change
+	if rec.Audit != nil {
+		s.audit = append(s.audit, *rec.Audit)
 	}
 }
 
@@ -63,11 +338,65 @@ func FingerprintSHA256(cert *x509.Certificate) string {
 	return hex.EncodeToString(sum[:])
 }
 
+// NormalizeFingerprint lowercases a hex fingerprint and drops colons and
+// surrounding space, so "AB:CD" and "abcd" name the same certificate.
+func NormalizeFingerprint(fp string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(fp), ":", ""))
+}
+
 // UpsertClient adds or updates a client in the store.
-func (s *Store) UpsertClient(c Client) {
+func (s *Store) UpsertClient(c Client) error {
+	return s.upsertClient(nil, c)
+}
+
+// upsertClient is UpsertClient, audited as a certificate issue if a is set.
+func (s *Store) upsertClient(a *Actor, c Client) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.clients[c.Fingerprint] = c
+
+	rec := Record{Op: OpUpsertClient, Client: &c}
+	var before any
+	if prev, exists := s.clients[c.Fingerprint]; exists {
+		before = prev
+	}
+	if err := s.auditLocked(&rec, a, AuditCertificateIssue, c.Fingerprint, before, c); err != nil {
+		return err
+	}
+	return s.commitLocked(rec)
+}
+
+// RegisterClient adds c, or replaces the registration of its certificate,
+// and returns the previous registration if there was one. A revoked
+// certificate stays revoked (ErrClientRevoked), and a client declared by a
+// SyncClients source can only be changed there (ErrClientManaged).
+func (s *Store) RegisterClient(c Client) (prev Client, existed bool, err error) {
+	return s.registerClient(nil, c)
+}
+
+// registerClient is RegisterClient, audited if a is set.
+func (s *Store) registerClient(a *Actor, c Client) (prev Client, existed bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev, existed = s.clients[c.Fingerprint]
+	switch {
+	case existed && prev.Revoked:
+		return prev, true, ErrClientRevoked
+	case existed && prev.Source != "":
+		return prev, true, ErrClientManaged
+	}
+	rec := Record{Op: OpUpsertClient, Client: &c}
+	var before any
+	if existed {
+		before = prev
+	}
+	if err := s.auditLocked(&rec, a, AuditClientUpsert, c.Fingerprint, before, c); err != nil {
+		return Client{}, false, err
+	}
+	if err := s.commitLocked(rec); err != nil {
+		return Client{}, false, err
+	}
+	return prev, existed, nil
 }
 
 // GetClient retrieves a client by fingerprint.
@@ -78,6 +407,192 @@ func (s *Store) GetClient(fp string) (Client, bool) {
 	return c, ok
 }
 
+// RevokeClient marks the client with the given fingerprint as revoked and
+// returns it. Revoking an already revoked client keeps the original time.
+// Returns ErrClientNotFound if no such client is registered.
+func (s *Store) RevokeClient(fp string) (Client, error) {
+	return s.revokeClient(nil, fp)
+}
+
+// revokeClient is RevokeClient, audited if a is set. Revoking an already
+// revoked client changes nothing and is not audited.
+func (s *Store) revokeClient(a *Actor, fp string) (Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.clients[fp]
+	if !ok {
+		return Client{}, ErrClientNotFound
+	}
+	if c.Revoked {
+		return c, nil
+	}
+	before := c
+	c.Revoked = true
+	c.RevokedAt = time.Now()
+	rec := Record{Op: OpUpsertClient, Client: &c}
+	if err := s.auditLocked(&rec, a, AuditClientRevoke, fp, before, c); err != nil {
+		return Client{}, err
+	}
+	if err := s.commitLocked(rec); err != nil {
+		return Client{}, err
+	}
+	return c, nil
+}
+
+// ClientUpdate describes a change to a client. Nil fields are left unchanged.
+type ClientUpdate struct {
+	Role  *Role
+	Teams *[]string
+}
+
+// UpdateClient applies upd to the client with the given fingerprint and
+// returns it. Returns ErrClientNotFound if no such client is registered.
+func (s *Store) UpdateClient(fp string, upd ClientUpdate) (Client, error) {
+	return s.updateClient(nil, fp, upd)
+}
+
+// updateClient is UpdateClient, audited if a is set.
+func (s *Store) updateClient(a *Actor, fp string, upd ClientUpdate) (Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.clients[fp]
+	if !ok {
+		return Client{}, ErrClientNotFound
+	}
+	before := c
+	if upd.Role != nil {
+		c.Role = *upd.Role
+	}
+	if upd.Teams != nil {
+		c.Teams = NormalizeTeams(*upd.Teams)
+	}
+	rec := Record{Op: OpUpsertClient, Client: &c}
+	if err := s.auditLocked(&rec, a, AuditClientUpdate, fp, before, c); err != nil {
+		return Client{}, err
+	}
+	if err := s.commitLocked(rec); err != nil {
+		return Client{}, err
+	}
+	return c, nil
+}
+
+// RekeyClient moves the client whose certificate is oldFP to newFP, keeping
+// its name, role and teams, in a single record. oldFP becomes the client's
+// PreviousFingerprint, so the old certificate keeps working until
+// ConfirmRekey is called for the new one; oldFP may itself be the previous
+// fingerprint of a renewal not yet confirmed, which is then replaced.
+// Returns ErrClientNotFound, ErrClientRevoked if the client is revoked, or
+// ErrClientExists if newFP is already registered.
+func (s *Store) RekeyClient(oldFP, newFP string) (Client, error) {
+	return s.rekeyClient(nil, oldFP, newFP)
+}
+
+// rekeyClient is RekeyClient, audited as a certificate renewal if a is set.
+func (s *Store) rekeyClient(a *Actor, oldFP, newFP string) (Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.clients[oldFP]
+	if !ok {
+		c, ok = s.clients[s.renewed[oldFP]]
+	}
+	if !ok {
+		return Client{}, ErrClientNotFound
+	}
+	if c.Revoked {
+		return Client{}, ErrClientRevoked
+	}
+	if _, taken := s.clients[newFP]; taken {
+		return Client{}, ErrClientExists
+	}
+	if _, taken := s.renewed[newFP]; taken {
+		return Client{}, ErrClientExists
+	}
+	before := c
+	c.Fingerprint, c.PreviousFingerprint = newFP, oldFP
+	rec := Record{Op: OpRekeyClient, Fingerprint: before.Fingerprint, Client: &c}
+	if err := s.auditLocked(&rec, a, AuditCertificateRenew, newFP, before, c); err != nil {
+		return Client{}, err
+	}
+	if err := s.commitLocked(rec); err != nil {
+		return Client{}, err
+	}
+	return c, nil
+}
+
+// RenewedClient returns the client whose PreviousFingerprint is fp: the
+// client a certificate replaced by a renewal not yet confirmed belongs to.
+func (s *Store) RenewedClient(fp string) (Client, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.clients[s.renewed[fp]]
+	return c, ok
+}
+
+// ConfirmRekey ends the grace period of the client with fingerprint fp: its
+// previous certificate stops working. It returns the client, unchanged if it
+// had no previous fingerprint, or ErrClientNotFound.
+func (s *Store) ConfirmRekey(fp string) (Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.clients[fp]
+	if !ok {
+		return Client{}, ErrClientNotFound
+	}
+	if c.PreviousFingerprint == "" {
+		return c, nil
+	}
+	c.PreviousFingerprint = ""
+	if err := s.commitLocked(Record{Op: OpUpsertClient, Client: &c}); err != nil {
+		return Client{}, err
+	}
+	return c, nil
+}
+
+// putClientLocked sets c in the client map and its renewal index.
+// Caller must hold the write lock.
+func (s *Store) putClientLocked(c Client) {
+	s.deleteClientLocked(c.Fingerprint)
+	s.clients[c.Fingerprint] = c
+	if c.PreviousFingerprint != "" {
+		s.renewed[c.PreviousFingerprint] = c.Fingerprint
+	}
+}
+
+// deleteClientLocked removes the client with fingerprint fp, if any, from
+// the client map and its renewal index. Caller must hold the write lock.
+func (s *Store) deleteClientLocked(fp string) {
+	if c, ok := s.clients[fp]; ok && c.PreviousFingerprint != "" {
+		delete(s.renewed, c.PreviousFingerprint)
+	}
+	delete(s.clients, fp)
+}
+
+// DeleteClient removes the client with the given fingerprint.
+// Returns ErrClientNotFound if no such client is registered.
+func (s *Store) DeleteClient(fp string) error {
+	return s.deleteClient(nil, fp)
+}
+
+// deleteClient is DeleteClient, audited if a is set.
+func (s *Store) deleteClient(a *Actor, fp string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before, ok := s.clients[fp]
+	if !ok {
+		return ErrClientNotFound
+	}
+	rec := Record{Op: OpDeleteClient, Fingerprint: fp}
+	if err := s.auditLocked(&rec, a, AuditClientDelete, fp, before, nil); err != nil {
+		return err
+	}
+	return s.commitLocked(rec)
+}
+
 // ListClients returns all registered clients sorted by name.
 func (s *Store) ListClients() []Client {
 	s.mu.RLock()
@@ -90,19 +605,49 @@ func (s *Store) ListClients() []Client {
 	return out
 }
 
-// SeedFeatures populates the store with fake feature data.
-func (s *Store) SeedFeatures(count int) {
+// SeedFeatures replaces the feature catalog with count features of fake
+// data. The replaced features are recorded as deleted in their history, and
+// the new ones get IDs after every ID used so far, so history and FeatureAt
+// still describe the old catalog. The new catalog is persisted as a snapshot
+// rather than record by record. count must be between 0 and the number of
+// IDs left; otherwise ErrInvalidSeedCount is returned and nothing changes.
+func (s *Store) SeedFeatures(count int) error {
+	return s.seedFeatures(nil, count)
+}
+
+// seedFeatures is SeedFeatures, audited if a is set. The audit entry goes
+// into the same snapshot as the new catalog.
+func (s *Store) seedFeatures(a *Actor, count int) error {
 	//nolint:errcheck // gofakeit.Seed error is not critical for seeding
 	gofakeit.Seed(time.Now().UnixNano())
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if left := MaxFeatureID - s.lastIDNum; count < 0 || count > left {
+		return fmt.Errorf("%w: %d (%d IDs left)", ErrInvalidSeedCount, count, left)
+	}
+
+	var rec Record
+	if err := s.auditLocked(&rec, a, AuditCatalogSeed, "catalog",
+		map[string]int{"features": len(s.features)}, map[string]int{"features": count}); err != nil {
+		return err
+	}
 
+	prevSeq, prevFeatures, prevIDs, prevIndex, prevLast, prevHistory, prevAudit := s.seq, s.features, s.featureIDs, s.index, s.lastIDNum, s.history, len(s.audit)
+	now := time.Now()
+	// Record the old catalog's deletion in a copy, so a failed compaction
+	// can put the previous history back
+	s.history = maps.Clone(s.history)
+	for _, id := range s.featureIDs {
+		s.recordDeletionLocked(s.features[id], now)
+	}
+	s.seq++
+	first := s.lastIDNum + 1
+	s.lastIDNum += count
 	s.features = make(map[string]Feature, count)
 	s.featureIDs = make([]string, 0, count)
 
-	now := time.Now()
-	for i := 1; i <= count; i++ {
+	for i := first; i <= s.lastIDNum; i++ {
 		id := "FT-" + leftPadInt(i, 6)
 		f := Feature{
 			ID:        id,
@@ -110,11 +655,46 @@ func (s *Store) SeedFeatures(count int) {
 			Summary:   gofakeit.Sentence(12),
 			Owner:     toTitle(gofakeit.BuzzWord()),
 			Tags:      []string{gofakeit.Noun(), gofakeit.Verb(), gofakeit.Adjective()},
+			Status:    StatusActive,
+			Version:   1,
 			CreatedAt: now,
+			UpdatedAt: now,
 		}
 		s.features[id] = f
 		s.featureIDs = append(s.featureIDs, id)
 	}
+	s.index = newSearchIndex(s.features)
+	if rec.Audit != nil {
+		s.audit = append(s.audit, *rec.Audit)
+	}
+
+	if err := s.compactLocked(); err != nil {
+		s.seq, s.features, s.featureIDs, s.index, s.lastIDNum, s.history = prevSeq, prevFeatures, prevIDs, prevIndex, prevLast, prevHistory
+		s.audit = s.audit[:prevAudit]
+		return err
+	}
+	s.publishLocked(Event{ID: s.seq, Type: EventCatalogReseeded, Time: now, Count: count})
+	return nil
+}
+
+// FeatureCount returns the number of features in the catalog.
+func (s *Store) FeatureCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.features)
+}
+
+// ClientCounts returns the number of registered clients and how many of
+// them are revoked.
+func (s *Store) ClientCounts() (total, revoked int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, c := range s.clients {
+		if c.Revoked {
+			revoked++
+		}
+	}
+	return len(s.clients), revoked
 }
 
 // GetFeature retrieves a feature by ID.
@@ -128,77 +708,289 @@ func (s *Store) GetFeature(id string) (Feature, bool) {
 // MaxFeatureID is the maximum feature ID number (FT-999999).
 const MaxFeatureID = 999999
 
-// CreateFeature adds a new feature with a server-assigned ID.
+// CreateFeature adds a new active feature with a server-assigned ID.
 // Returns the created feature with the assigned ID.
-// Returns zero-value Feature if ID space is exhausted (should never happen in practice).
-func (s *Store) CreateFeature(name, summary, owner string, tags []string) Feature {
+// Returns ErrIDSpaceExhausted once FT-999999 has been assigned; deleted IDs
+// are not reused.
+func (s *Store) CreateFeature(name, summary, owner string, tags []string) (Feature, error) {
+	return s.CreateFeatureWithStatus(name, summary, owner, tags, StatusActive)
+}
+
+// CreateFeatureWithStatus is like CreateFeature but sets the initial status,
+// which must be proposed or active.
+func (s *Store) CreateFeatureWithStatus(name, summary, owner string, tags []string, status Status) (Feature, error) {
+	return s.createFeature(nil, name, summary, owner, tags, status)
+}
+
+// createFeature is CreateFeatureWithStatus, audited if a is set.
+func (s *Store) createFeature(a *Actor, name, summary, owner string, tags []string, status Status) (Feature, error) {
+	if status != StatusProposed && status != StatusActive {
+		return Feature{}, fmt.Errorf("%w: new features must be %s or %s", ErrInvalidStatus, StatusProposed, StatusActive)
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Find next available ID with bounded search
-	nextNum := len(s.features) + 1
-	if nextNum > MaxFeatureID {
-		nextNum = 1 // Normalize before loop starts
-	}
-	for range MaxFeatureID {
-		id := "FT-" + leftPadInt(nextNum, 6)
-		if _, exists := s.features[id]; !exists {
-			f := Feature{
-				ID:        id,
-				Name:      name,
-				Summary:   summary,
-				Owner:     owner,
-				Tags:      tags,
-				CreatedAt: time.Now(),
-			}
-			s.features[id] = f
-			s.featureIDs = append(s.featureIDs, id)
-			return f
+	// IDs continue past every ID ever assigned, so a deleted feature's ID
+	// is never handed out again, even once the last one has been used
+	if s.lastIDNum >= MaxFeatureID {
+		return Feature{}, ErrIDSpaceExhausted
+	}
+	id := "FT-" + leftPadInt(s.lastIDNum+1, 6)
+	now := time.Now()
+	f := Feature{
+		ID:        id,
+		Name:      name,
+		Summary:   summary,
+		Owner:     owner,
+		Tags:      tags,
+		Status:    status,
+		Version:   1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	rec := Record{Op: OpPutFeature, Feature: &f}
+	if err := s.auditLocked(&rec, a, AuditFeatureCreate, id, nil, f); err != nil {
+		return Feature{}, err
+	}
+	if err := s.commitLocked(rec); err != nil {
+		return Feature{}, err
+	}
+	return f, nil
+}
+
+// UpdateFeature applies upd to the feature with the given ID.
+// If ifVersion is non-zero it must equal the feature's current version,
+// otherwise ErrVersionConflict is returned along with the current feature
+// and nothing changes.
+func (s *Store) UpdateFeature(id string, upd FeatureUpdate, ifVersion int64) (Feature, error) {
+	return s.updateFeature(nil, id, upd, ifVersion)
+}
+
+// updateFeature is UpdateFeature, audited if a is set.
+func (s *Store) updateFeature(a *Actor, id string, upd FeatureUpdate, ifVersion int64) (Feature, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.features[id]
+	if !ok {
+		return Feature{}, ErrFeatureNotFound
+	}
+	if ifVersion != 0 && ifVersion != f.Version {
+		return f, ErrVersionConflict
+	}
+
+	before := f
+	if upd.Name != nil {
+		f.Name = *upd.Name
+	}
+	if upd.Summary != nil {
+		f.Summary = *upd.Summary
+	}
+	if upd.Owner != nil {
+		f.Owner = *upd.Owner
+	}
+	if upd.Tags != nil {
+		f.Tags = *upd.Tags
+	}
+	if err := s.applyLifecycleLocked(&f, upd); err != nil {
+		return Feature{}, err
+	}
+	f.Version++
+	f.UpdatedAt = time.Now()
+
+	rec := Record{Op: OpPutFeature, Feature: &f}
+	if err := s.auditLocked(&rec, a, AuditFeatureUpdate, id, before, f); err != nil {
+		return Feature{}, err
+	}
+	if err := s.commitLocked(rec); err != nil {
+		return Feature{}, err
+	}
+	return f, nil
+}
+
+// applyLifecycleLocked validates and applies status and replaced_by changes.
+// Caller must hold the write lock.
+func (s *Store) applyLifecycleLocked(f *Feature, upd FeatureUpdate) error {
+	if upd.Status != nil {
+		if !f.Status.CanTransitionTo(*upd.Status) {
+			return fmt.Errorf("%w: %s -> %s", ErrInvalidStatus, f.Status, *upd.Status)
+		}
+		f.Status = *upd.Status
+	}
+	if upd.ReplacedBy != nil {
+		f.ReplacedBy = *upd.ReplacedBy
+	}
+
+	switch {
+	case f.Status != StatusDeprecated && f.Status != StatusRemoved:
+		// A successor only makes sense for sunset features
+		if upd.ReplacedBy != nil && *upd.ReplacedBy != "" {
+			return fmt.Errorf("%w: only deprecated or removed features can be replaced", ErrInvalidReplacement)
+		}
+		f.ReplacedBy = ""
+	case f.ReplacedBy == "":
+	case f.ReplacedBy == f.ID:
+		return fmt.Errorf("%w: feature cannot replace itself", ErrInvalidReplacement)
+	default:
+		succ, ok := s.features[f.ReplacedBy]
+		if !ok {
+			return fmt.Errorf("%w: %s does not exist", ErrInvalidReplacement, f.ReplacedBy)
 		}
-		nextNum++
-		if nextNum > MaxFeatureID {
-			nextNum = 1 // Wrap around to find gaps
+		if succ.Status == StatusRemoved {
+			return fmt.Errorf("%w: %s is removed", ErrInvalidReplacement, f.ReplacedBy)
 		}
 	}
+	return nil
+}
 
-	// Should never happen in practice - return zero-value
-	return Feature{}
+// DeleteFeature removes the feature with the given ID.
+// If ifVersion is non-zero it must equal the feature's current version.
+func (s *Store) DeleteFeature(id string, ifVersion int64) error {
+	return s.deleteFeature(nil, id, ifVersion)
 }
 
-// SearchFeatures performs a case-insensitive search across feature fields.
+// deleteFeature is DeleteFeature, audited if a is set.
+func (s *Store) deleteFeature(a *Actor, id string, ifVersion int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.features[id]
+	if !ok {
+		return ErrFeatureNotFound
+	}
+	if ifVersion != 0 && ifVersion != f.Version {
+		return ErrVersionConflict
+	}
+	rec := Record{Op: OpDeleteFeature, FeatureID: id, Time: time.Now()}
+	if err := s.auditLocked(&rec, a, AuditFeatureDelete, id, f, nil); err != nil {
+		return err
+	}
+	return s.commitLocked(rec)
+}
+
+// Page sizes of the APIs: the default and largest number of features per
+// search page, and the default and largest number of suggestions.
+const (
+	DefaultPageSize     = 20
+	MaxPageSize         = 500
+	DefaultSuggestLimit = 10
+	MaxSuggestLimit     = 100
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Page is one page of search results.
+// NextCursor is empty when there are no further results.
+type Page struct {
+	Items      []Feature
+	NextCursor string
+}
+
+// SearchFeatures performs a case-insensitive full-text search across
+// feature ID, name, summary, tags and owner, best matches first.
+// It returns the first page only, and nothing for a query that does not
+// parse; use SearchPage to walk all results and see errors.
 func (s *Store) SearchFeatures(query string, limit int) []Feature {
+	page, _ := s.SearchPage(query, limit, "") //nolint:errcheck // Invalid queries yield an empty page
+	return page.Items
+}
+
+// SearchPage returns up to limit features matching query, starting after
+// cursor (empty for the first page).
+//
+// The query syntax is described in query.go: words, "phrases",
+// field:value terms, negation, AND/OR and parentheses. Every query word
+// must match a word in the feature, or the start of one. Results are
+// ranked: an exact ID match comes first, then features whose name equals
+// or starts with the query, then by weighted term matches (name over tags
+// over owner over summary). Ties, and the empty query, are ordered by ID.
+// The cursor encodes the rank of the last result returned, so later pages
+// are not shifted by features created or deleted between requests.
+// Returns ErrInvalidQuery or ErrInvalidCursor for malformed input.
+func (s *Store) SearchPage(query string, limit int, cursor string) (Page, error) {
 	if limit <= 0 {
 		limit = 20
 	}
-	q := strings.ToLower(strings.TrimSpace(query))
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		return Page{}, err
+	}
+	n, err := parseQuery(query)
+	if err != nil {
+		return Page{}, err
+	}
 
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	out := make([]Feature, 0, limit)
-	for _, id := range s.featureIDs {
-		f := s.features[id]
-		if q == "" || matchFeature(f, q) {
-			out = append(out, f)
-			if len(out) >= limit {
-				break
+	var last scoredFeature
+	for h := range s.searchLocked(n, after) {
+		if len(out) == limit {
+			// One more match exists: hand out a cursor for it
+			return Page{Items: out, NextCursor: encodeCursor(last)}, nil
+		}
+		out = append(out, s.features[h.id])
+		last = h
+	}
+	return Page{Items: out}, nil
+}
+
+// searchLocked yields the ranked hits for the parsed query n that sort
+// after the given position (zero value for the start). A nil query lists
+// the whole catalog by ID. Caller must hold a lock while iterating.
+func (s *Store) searchLocked(n queryNode, after scoredFeature) iter.Seq[scoredFeature] {
+	if n == nil {
+		// featureIDs is sorted, so the start position is a binary search away
+		start := 0
+		if after.id != "" {
+			start = sort.SearchStrings(s.featureIDs, after.id)
+			if start < len(s.featureIDs) && s.featureIDs[start] == after.id {
+				start++
+			}
+		}
+		return func(yield func(scoredFeature) bool) {
+			for _, id := range s.featureIDs[start:] {
+				if !yield(scoredFeature{id: id}) {
+					return
+				}
 			}
 		}
 	}
-	return out
+
+	hits := s.rankLocked(n)
+	if after.id != "" {
+		i := sort.Search(len(hits), func(i int) bool {
+			h := hits[i]
+			return h.score < after.score || (h.score == after.score && h.id > after.id)
+		})
+		hits = hits[i:]
+	}
+	return slices.Values(hits)
 }
 
-// Suggest returns features matching the query for autocomplete purposes.
-func (s *Store) Suggest(query string, limit int) []Feature {
-	return s.SearchFeatures(query, limit)
+// encodeCursor makes an opaque pagination cursor from the last returned hit.
+func encodeCursor(last scoredFeature) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(last.score) + ":" + last.id))
 }
 
-// matchFeature checks if a feature matches the query string.
-func matchFeature(f Feature, q string) bool {
-	id := strings.ToLower(f.ID)
-	name := strings.ToLower(f.Name)
-	sum := strings.ToLower(f.Summary)
-	return strings.Contains(id, q) || strings.Contains(name, q) || strings.Contains(sum, q)
+// decodeCursor returns the position encoded in cursor (zero value for none).
+func decodeCursor(cursor string) (scoredFeature, error) {
+	if cursor == "" {
+		return scoredFeature{}, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return scoredFeature{}, ErrInvalidCursor
+	}
+	scoreStr, id, ok := strings.Cut(string(b), ":")
+	score, convErr := strconv.Atoi(scoreStr)
+	if !ok || convErr != nil || featureNum(id) == 0 {
+		return scoredFeature{}, ErrInvalidCursor
+	}
+	return scoredFeature{id: id, score: score}, nil
 }
 
 // leftPadInt pads an integer with leading zeros.
@@ -210,6 +1002,23 @@ func leftPadInt(n, width int) string {
 	return strings.Repeat("0", width-len(s)) + s
 }
 
+// normalizeFeature fills defaults for features persisted by older versions.
+func normalizeFeature(f *Feature) {
+	if f.Status == "" {
+		f.Status = StatusActive
+	}
+}
+
+// featureNum extracts the number from a server feature ID (FT-NNNNNN).
+// Returns 0 for IDs in any other format.
+func featureNum(id string) int {
+	n, err := strconv.Atoi(strings.TrimPrefix(id, "FT-"))
+	if err != nil || !strings.HasPrefix(id, "FT-") {
+		return 0
+	}
+	return n
+}
+
 // toTitle converts a string to title case.
 func toTitle(s string) string {
 	if len(s) == 0 {
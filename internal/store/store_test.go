@@ -3,6 +3,9 @@ package store
 import (
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"errors"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -40,6 +43,14 @@ func TestFingerprintSHA256(t *testing.T) {
 	}
 }
 
+func TestNormalizeFingerprint(t *testing.T) {
+	for _, in := range []string{"ab:cd:ef", "AB:CD:EF", " abcdef\n", "abcdef"} {
+		if got := NormalizeFingerprint(in); got != "abcdef" {
+			t.Errorf("NormalizeFingerprint(%q) = %q, want abcdef", in, got)
+		}
+	}
+}
+
 func TestClientOperations(t *testing.T) {
 	s := New()
 
@@ -79,6 +90,257 @@ func TestClientOperations(t *testing.T) {
 	}
 }
 
+func TestRevokeAndDeleteClient(t *testing.T) {
+	s := New()
+	s.UpsertClient(Client{Fingerprint: "abc123", Name: "leaked", Role: RoleUser})
+
+	if _, err := s.RevokeClient("missing"); !errors.Is(err, ErrClientNotFound) {
+		t.Errorf("RevokeClient(missing) error = %v, want ErrClientNotFound", err)
+	}
+
+	revoked, err := s.RevokeClient("abc123")
+	if err != nil {
+		t.Fatalf("RevokeClient: %v", err)
+	}
+	if !revoked.Revoked || revoked.RevokedAt.IsZero() {
+		t.Errorf("RevokeClient returned %+v, want revoked with a time", revoked)
+	}
+	got, ok := s.GetClient("abc123")
+	if !ok || !got.Revoked {
+		t.Errorf("GetClient after revoke = %+v, %v; want revoked client", got, ok)
+	}
+
+	// Revoking again keeps the original time
+	again, err := s.RevokeClient("abc123")
+	if err != nil || !again.RevokedAt.Equal(revoked.RevokedAt) {
+		t.Errorf("second RevokeClient = %+v, %v; want unchanged", again, err)
+	}
+
+	if err := s.DeleteClient("abc123"); err != nil {
+		t.Fatalf("DeleteClient: %v", err)
+	}
+	if _, ok := s.GetClient("abc123"); ok {
+		t.Error("client still present after DeleteClient")
+	}
+	if err := s.DeleteClient("abc123"); !errors.Is(err, ErrClientNotFound) {
+		t.Errorf("second DeleteClient error = %v, want ErrClientNotFound", err)
+	}
+}
+
+func TestClientCounts(t *testing.T) {
+	s := New()
+	s.UpsertClient(Client{Fingerprint: "a", Name: "a", Role: RoleUser})
+	s.UpsertClient(Client{Fingerprint: "b", Name: "b", Role: RoleUser})
+	if _, err := s.RevokeClient("b"); err != nil {
+		t.Fatalf("RevokeClient: %v", err)
+	}
+	if total, revoked := s.ClientCounts(); total != 2 || revoked != 1 {
+		t.Errorf("ClientCounts = %d, %d; want 2, 1", total, revoked)
+	}
+}
+
+func TestObservedBackend(t *testing.T) {
+	var ops []string
+	b := &ObservedBackend{
+		Backend: NewMemoryBackend(),
+		Observe: func(op string, _ time.Duration, err error) {
+			if err != nil {
+				t.Errorf("%s: %v", op, err)
+			}
+			ops = append(ops, op)
+		},
+	}
+	s, err := Open(b)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	s.UpsertClient(Client{Fingerprint: "a", Name: "a", Role: RoleUser})
+	if err := s.SeedFeatures(1); err != nil {
+		t.Fatalf("SeedFeatures: %v", err)
+	}
+
+	want := []string{"load", string(OpUpsertClient), "compact"}
+	if !slices.Equal(ops, want) {
+		t.Errorf("observed %v, want %v", ops, want)
+	}
+}
+
+func TestRolePermissions(t *testing.T) {
+	tests := []struct {
+		role    Role
+		allowed []Permission
+		denied  []Permission
+	}{
+		{RoleUser, []Permission{PermFeaturesRead, PermFeaturesWriteOwn}, []Permission{PermFeaturesWrite, PermCatalogSeed, PermClientsManage}},
+		{RoleEditor, []Permission{PermFeaturesRead, PermFeaturesWrite}, []Permission{PermFeaturesDelete, PermCatalogSeed}},
+		{RoleMaintainer, []Permission{PermFeaturesDelete, PermCatalogSeed}, []Permission{PermClientsManage, PermAuditRead}},
+		{RoleAdmin, []Permission{PermFeaturesRead, PermClientsManage, PermCatalogSeed, PermAuditRead}, nil},
+		{Role("bogus"), nil, []Permission{PermFeaturesRead}},
+	}
+	for _, tt := range tests {
+		for _, p := range tt.allowed {
+			if !tt.role.Can(p) {
+				t.Errorf("%s should have %s", tt.role, p)
+			}
+		}
+		for _, p := range tt.denied {
+			if tt.role.Can(p) {
+				t.Errorf("%s should not have %s", tt.role, p)
+			}
+		}
+	}
+
+	for _, r := range Roles() {
+		if got, ok := ParseRole(" " + strings.ToUpper(string(r)) + " "); !ok || got != r {
+			t.Errorf("ParseRole(%q) = %q, %v", r, got, ok)
+		}
+	}
+	if _, ok := ParseRole("superuser"); ok {
+		t.Error("ParseRole should reject unknown roles")
+	}
+}
+
+func TestUpdateClient(t *testing.T) {
+	s := New()
+	s.UpsertClient(Client{Fingerprint: "abc123", Name: "bob", Role: RoleUser, Teams: []string{"Payments"}})
+
+	role := RoleEditor
+	c, err := s.UpdateClient("abc123", ClientUpdate{Role: &role})
+	if err != nil || c.Role != RoleEditor || len(c.Teams) != 1 {
+		t.Fatalf("UpdateClient(role) = %+v, %v; want editor with teams kept", c, err)
+	}
+
+	teams := []string{" Billing ", "", "payments", "Payments", "billing"}
+	c, err = s.UpdateClient("abc123", ClientUpdate{Teams: &teams})
+	if err != nil {
+		t.Fatalf("UpdateClient(teams): %v", err)
+	}
+	if !slices.Equal(c.Teams, []string{"Billing", "payments"}) || c.Role != RoleEditor {
+		t.Errorf("UpdateClient(teams) = %+v, want normalized teams and role kept", c)
+	}
+	if got, _ := s.GetClient("abc123"); !slices.Equal(got.Teams, c.Teams) {
+		t.Errorf("stored teams = %v, want %v", got.Teams, c.Teams)
+	}
+
+	if _, err := s.UpdateClient("missing", ClientUpdate{Role: &role}); !errors.Is(err, ErrClientNotFound) {
+		t.Errorf("UpdateClient(missing) error = %v, want ErrClientNotFound", err)
+	}
+}
+
+func TestRekeyClient(t *testing.T) {
+	dir := t.TempDir()
+	s := openFileStore(t, dir)
+	s.UpsertClient(Client{Fingerprint: "old", Name: "bob", Role: RoleEditor, Teams: []string{"Payments"}})
+	s.UpsertClient(Client{Fingerprint: "taken", Name: "carol", Role: RoleUser})
+
+	if _, err := s.RekeyClient("old", "taken"); !errors.Is(err, ErrClientExists) {
+		t.Errorf("RekeyClient onto a registered fingerprint error = %v, want ErrClientExists", err)
+	}
+	if _, err := s.RekeyClient("missing", "new"); !errors.Is(err, ErrClientNotFound) {
+		t.Errorf("RekeyClient(missing) error = %v, want ErrClientNotFound", err)
+	}
+
+	c, err := s.RekeyClient("old", "new")
+	if err != nil {
+		t.Fatalf("RekeyClient: %v", err)
+	}
+	if c.Fingerprint != "new" || c.Name != "bob" || c.Role != RoleEditor || len(c.Teams) != 1 {
+		t.Errorf("RekeyClient = %+v, want bob's record under the new fingerprint", c)
+	}
+
+	// Replayed from the WAL
+	s2 := openFileStore(t, dir)
+	defer s2.Close()
+	if _, ok := s2.GetClient("old"); ok {
+		t.Error("old fingerprint still registered after rekey")
+	}
+	if got, ok := s2.GetClient("new"); !ok || got.Name != "bob" || got.PreviousFingerprint != "old" {
+		t.Errorf("GetClient(new) = %+v, %v; want bob, previously old", got, ok)
+	}
+	// Until the new certificate is used, the old one still belongs to bob
+	if got, ok := s2.RenewedClient("old"); !ok || got.Fingerprint != "new" {
+		t.Errorf("RenewedClient(old) = %+v, %v; want bob under new", got, ok)
+	}
+
+	// Renewing again with the old certificate replaces the unused one
+	c, err = s2.RekeyClient("old", "newer")
+	if err != nil {
+		t.Fatalf("RekeyClient from the previous fingerprint: %v", err)
+	}
+	if _, ok := s2.GetClient("new"); ok || c.Fingerprint != "newer" || c.PreviousFingerprint != "old" {
+		t.Errorf("after a repeated renewal: %+v, new still registered: %v", c, ok)
+	}
+
+	if c, err = s2.ConfirmRekey("newer"); err != nil || c.PreviousFingerprint != "" {
+		t.Fatalf("ConfirmRekey = %+v, %v", c, err)
+	}
+	if _, ok := s2.RenewedClient("old"); ok {
+		t.Error("old fingerprint still accepted after ConfirmRekey")
+	}
+	if _, err := s2.RekeyClient("old", "newest"); !errors.Is(err, ErrClientNotFound) {
+		t.Errorf("RekeyClient from a retired fingerprint error = %v, want ErrClientNotFound", err)
+	}
+
+	if _, err := s2.RevokeClient("newer"); err != nil {
+		t.Fatalf("RevokeClient: %v", err)
+	}
+	if _, err := s2.RekeyClient("newer", "newest"); !errors.Is(err, ErrClientRevoked) {
+		t.Errorf("RekeyClient(revoked) error = %v, want ErrClientRevoked", err)
+	}
+}
+
+func TestRegisterClient(t *testing.T) {
+	s := New()
+
+	prev, existed, err := s.RegisterClient(Client{Fingerprint: "fp", Name: "bob", Role: RoleUser})
+	if err != nil || existed {
+		t.Fatalf("RegisterClient(new) = %+v, %v, %v; want no previous registration", prev, existed, err)
+	}
+	prev, existed, err = s.RegisterClient(Client{Fingerprint: "fp", Name: "robert", Role: RoleEditor})
+	if err != nil || !existed || prev.Name != "bob" {
+		t.Fatalf("RegisterClient(again) = %+v, %v, %v; want bob as the previous registration", prev, existed, err)
+	}
+	if got, _ := s.GetClient("fp"); got.Name != "robert" || got.Role != RoleEditor {
+		t.Errorf("GetClient = %+v, want robert the editor", got)
+	}
+
+	s.UpsertClient(Client{Fingerprint: "declared", Name: "ci", Role: RoleUser, Source: "clients-file"})
+	if _, _, err := s.RegisterClient(Client{Fingerprint: "declared", Name: "ci", Role: RoleAdmin}); !errors.Is(err, ErrClientManaged) {
+		t.Errorf("RegisterClient(declared) error = %v, want ErrClientManaged", err)
+	}
+
+	if _, err := s.RevokeClient("fp"); err != nil {
+		t.Fatalf("RevokeClient: %v", err)
+	}
+	if _, _, err := s.RegisterClient(Client{Fingerprint: "fp", Name: "robert", Role: RoleUser}); !errors.Is(err, ErrClientRevoked) {
+		t.Errorf("RegisterClient(revoked) error = %v, want ErrClientRevoked", err)
+	}
+	if got, _ := s.GetClient("fp"); !got.Revoked {
+		t.Error("re-registering cleared the revocation")
+	}
+}
+
+func TestCanWriteFeature(t *testing.T) {
+	tests := []struct {
+		client Client
+		owner  string
+		want   bool
+	}{
+		{Client{Role: RoleUser, Teams: []string{"Payments"}}, "payments", true},
+		{Client{Role: RoleUser, Teams: []string{"Payments"}}, "Billing", false},
+		{Client{Role: RoleUser, Teams: []string{"Payments"}}, "", false},
+		{Client{Role: RoleUser}, "Payments", false},
+		{Client{Role: RoleEditor}, "Billing", true},
+		{Client{Role: RoleAdmin}, "", true},
+		{Client{Role: Role("bogus"), Teams: []string{"Payments"}}, "Payments", false},
+	}
+	for _, tt := range tests {
+		if got := tt.client.CanWriteFeature(tt.owner); got != tt.want {
+			t.Errorf("%s with teams %v: CanWriteFeature(%q) = %v, want %v", tt.client.Role, tt.client.Teams, tt.owner, got, tt.want)
+		}
+	}
+}
+
 func TestListClients(t *testing.T) {
 	s := New()
 
@@ -124,11 +386,25 @@ func TestSeedFeatures(t *testing.T) {
 		t.Errorf("feature ID = %q, want %q", f.ID, "FT-000001")
 	}
 
-	// Reseed should replace features
+	// Reseed should replace features, with IDs after the old ones
 	s.SeedFeatures(5)
 	features = s.SearchFeatures("", 100)
-	if len(features) != 5 {
-		t.Errorf("reseed with 5 resulted in %d features", len(features))
+	if len(features) != 5 || features[0].ID != "FT-000011" {
+		t.Errorf("reseed with 5 resulted in %d features from %s", len(features), features[0].ID)
+	}
+
+	// Out-of-range counts are refused before anything changes
+	for _, count := range []int{-5, MaxFeatureID - 15 + 1} {
+		if err := s.SeedFeatures(count); !errors.Is(err, ErrInvalidSeedCount) {
+			t.Errorf("SeedFeatures(%d) error = %v, want ErrInvalidSeedCount", count, err)
+		}
+	}
+	if n := s.FeatureCount(); n != 5 {
+		t.Errorf("after refused seeds FeatureCount = %d, want 5", n)
+	}
+	f, err := s.CreateFeature("After", "Refused seeds", "Team", nil)
+	if err != nil || f.ID != "FT-000016" {
+		t.Errorf("CreateFeature after refused seeds = %q, %v; want FT-000016", f.ID, err)
 	}
 }
 
@@ -157,12 +433,13 @@ func TestSearchFeatures(t *testing.T) {
 
 	// Manually add features for predictable testing
 	s.mu.Lock()
-	s.features = map[string]Feature{
-		"FT-001": {ID: "FT-001", Name: "Authentication", Summary: "User login flow"},
-		"FT-002": {ID: "FT-002", Name: "Authorization", Summary: "Permission checks"},
-		"FT-003": {ID: "FT-003", Name: "Billing", Summary: "Payment processing"},
+	for _, f := range []Feature{
+		{ID: "FT-001", Name: "Authentication", Summary: "User login flow"},
+		{ID: "FT-002", Name: "Authorization", Summary: "Permission checks"},
+		{ID: "FT-003", Name: "Billing", Summary: "Payment processing"},
+	} {
+		s.apply(Record{Op: OpPutFeature, Feature: &f})
 	}
-	s.featureIDs = []string{"FT-001", "FT-002", "FT-003"}
 	s.mu.Unlock()
 
 	tests := []struct {
@@ -194,13 +471,18 @@ func TestSearchFeatures(t *testing.T) {
 
 func TestSuggest(t *testing.T) {
 	s := New()
-	s.SeedFeatures(20)
+	s.SeedFeatures(MaxSuggestLimit + 20)
 
 	// Suggest should return same results as SearchFeatures
 	suggestions := s.Suggest("", 5)
 	if len(suggestions) != 5 {
 		t.Errorf("Suggest returned %d items, want 5", len(suggestions))
 	}
+
+	// An oversized limit is capped rather than allocated
+	if n := len(s.Suggest("", 2_000_000_000)); n != MaxSuggestLimit {
+		t.Errorf("Suggest with an oversized limit returned %d items, want %d", n, MaxSuggestLimit)
+	}
 }
 
 func TestLeftPadInt(t *testing.T) {
@@ -244,31 +526,30 @@ func TestToTitle(t *testing.T) {
 	}
 }
 
-func TestMatchFeature(t *testing.T) {
-	f := Feature{
-		ID:      "FT-001",
-		Name:    "Authentication",
-		Summary: "User login flow",
-	}
+func TestSearchIndexMatch(t *testing.T) {
+	ix := newSearchIndex(map[string]Feature{
+		"FT-001": {ID: "FT-001", Name: "Authentication", Summary: "User login flow"},
+	})
 
-	// Note: matchFeature expects query to already be lowercased
-	// (SearchFeatures does the lowercasing before calling matchFeature)
+	// Note: match expects tokens from tokenize, which lowercases
 	tests := []struct {
 		query string
 		want  bool
 	}{
 		{"ft-001", true},         // ID match
-		{"auth", true},           // name match
+		{"auth", true},           // name prefix match
 		{"login", true},          // summary match
 		{"xyz", false},           // no match
-		{"ft", true},             // partial ID (lowercase)
-		{"authentication", true}, // full name match (lowercase)
+		{"ft", true},             // partial ID
+		{"authentication", true}, // full name match
+		{"user flow", true},      // every word matches
+		{"user billing", false},  // one word missing
 	}
 
 	for _, tt := range tests {
-		got := matchFeature(f, tt.query)
+		_, got := ix.match(tokenize(tt.query), textFields, true)["FT-001"]
 		if got != tt.want {
-			t.Errorf("matchFeature(f, %q) = %v, want %v", tt.query, got, tt.want)
+			t.Errorf("match(%q) = %v, want %v", tt.query, got, tt.want)
 		}
 	}
 }
@@ -332,7 +613,10 @@ func TestCreateFeature(t *testing.T) {
 	s := New()
 
 	// Create first feature
-	f1 := s.CreateFeature("Auth", "User login flow", "Security Team", []string{"auth", "security"})
+	f1, err := s.CreateFeature("Auth", "User login flow", "Security Team", []string{"auth", "security"})
+	if err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
 	if f1.ID != "FT-000001" {
 		t.Errorf("first feature ID = %q, want %q", f1.ID, "FT-000001")
 	}
@@ -353,7 +637,10 @@ func TestCreateFeature(t *testing.T) {
 	}
 
 	// Create second feature - should auto-increment
-	f2 := s.CreateFeature("Billing", "Payment processing", "", nil)
+	f2, err := s.CreateFeature("Billing", "Payment processing", "", nil)
+	if err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
 	if f2.ID != "FT-000002" {
 		t.Errorf("second feature ID = %q, want %q", f2.ID, "FT-000002")
 	}
@@ -381,7 +668,10 @@ func TestCreateFeature_WithExistingFeatures(t *testing.T) {
 	s.SeedFeatures(5) // Seeds FT-000001 through FT-000005
 
 	// New feature should get FT-000006
-	f := s.CreateFeature("New Feature", "Test", "", nil)
+	f, err := s.CreateFeature("New Feature", "Test", "", nil)
+	if err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
 	if f.ID != "FT-000006" {
 		t.Errorf("feature ID = %q, want %q", f.ID, "FT-000006")
 	}
@@ -393,6 +683,34 @@ func TestCreateFeature_WithExistingFeatures(t *testing.T) {
 	}
 }
 
+func TestCreateFeature_IDSpaceExhausted(t *testing.T) {
+	s := New()
+	s.lastIDNum = MaxFeatureID - 10 // as if earlier reseeds used the rest
+
+	// A reseed takes all but the last ID, and a create the last one
+	if err := s.SeedFeatures(9); err != nil {
+		t.Fatalf("SeedFeatures: %v", err)
+	}
+	f, err := s.CreateFeature("Last", "Takes the last ID", "Team", nil)
+	if err != nil || f.ID != "FT-999999" {
+		t.Fatalf("CreateFeature = %q, %v; want FT-999999", f.ID, err)
+	}
+
+	// Deleted IDs are not reused once the space is used up
+	if err := s.DeleteFeature("FT-999990", 0); err != nil {
+		t.Fatalf("DeleteFeature: %v", err)
+	}
+	if f, err := s.CreateFeature("Over", "No ID left", "Team", nil); !errors.Is(err, ErrIDSpaceExhausted) {
+		t.Errorf("CreateFeature past FT-999999 = %q, %v; want ErrIDSpaceExhausted", f.ID, err)
+	}
+	if err := s.SeedFeatures(1); !errors.Is(err, ErrInvalidSeedCount) {
+		t.Errorf("SeedFeatures past FT-999999 error = %v, want ErrInvalidSeedCount", err)
+	}
+	if n := s.FeatureCount(); n != 9 {
+		t.Errorf("FeatureCount = %d, want 9", n)
+	}
+}
+
 func TestCreateFeature_Concurrent(t *testing.T) {
 	s := New()
 	done := make(chan string, 10)
@@ -401,7 +719,7 @@ func TestCreateFeature_Concurrent(t *testing.T) {
 	for i := range 10 {
 		go func(idx int) {
 			name := "Feature " + string(rune('A'+idx))
-			f := s.CreateFeature(name, "Summary", "", nil)
+			f, _ := s.CreateFeature(name, "Summary", "", nil)
 			done <- f.ID
 		}(i)
 	}
@@ -422,6 +740,88 @@ func TestCreateFeature_Concurrent(t *testing.T) {
 	}
 }
 
+func TestUpdateFeature(t *testing.T) {
+	s := New()
+	f, err := s.CreateFeature("Auth", "User login flow", "Security", []string{"auth"})
+	if err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+	if f.Version != 1 {
+		t.Fatalf("new feature version = %d, want 1", f.Version)
+	}
+
+	// Partial update leaves other fields alone
+	summary := "User login and logout"
+	got, err := s.UpdateFeature(f.ID, FeatureUpdate{Summary: &summary}, 1)
+	if err != nil {
+		t.Fatalf("UpdateFeature: %v", err)
+	}
+	if got.Summary != summary || got.Name != "Auth" || got.Owner != "Security" {
+		t.Errorf("unexpected feature after update: %+v", got)
+	}
+	if got.Version != 2 {
+		t.Errorf("version = %d, want 2", got.Version)
+	}
+	if !got.UpdatedAt.After(f.UpdatedAt) && !got.UpdatedAt.Equal(f.UpdatedAt) {
+		t.Error("UpdatedAt should not go backwards")
+	}
+
+	// Stale version is rejected and nothing changes
+	name := "Stale"
+	current, err := s.UpdateFeature(f.ID, FeatureUpdate{Name: &name}, 1)
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("stale update error = %v, want ErrVersionConflict", err)
+	}
+	if current.Version != 2 {
+		t.Errorf("conflict should report current version, got %d", current.Version)
+	}
+	stored, _ := s.GetFeature(f.ID)
+	if stored.Name != "Auth" {
+		t.Errorf("stale update was applied: name = %q", stored.Name)
+	}
+
+	// Zero version is unconditional
+	if _, err := s.UpdateFeature(f.ID, FeatureUpdate{Name: &name}, 0); err != nil {
+		t.Errorf("unconditional update: %v", err)
+	}
+
+	if _, err := s.UpdateFeature("FT-999999", FeatureUpdate{Name: &name}, 0); !errors.Is(err, ErrFeatureNotFound) {
+		t.Errorf("update missing feature error = %v, want ErrFeatureNotFound", err)
+	}
+}
+
+func TestDeleteFeature(t *testing.T) {
+	s := New()
+	if err := s.SeedFeatures(3); err != nil {
+		t.Fatalf("SeedFeatures: %v", err)
+	}
+
+	if err := s.DeleteFeature("FT-000002", 5); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("delete with stale version error = %v, want ErrVersionConflict", err)
+	}
+	if err := s.DeleteFeature("FT-000002", 1); err != nil {
+		t.Fatalf("DeleteFeature: %v", err)
+	}
+	if _, ok := s.GetFeature("FT-000002"); ok {
+		t.Error("deleted feature still retrievable")
+	}
+	if got := s.SearchFeatures("", 10); len(got) != 2 {
+		t.Errorf("search after delete returned %d features, want 2", len(got))
+	}
+	if err := s.DeleteFeature("FT-000002", 0); !errors.Is(err, ErrFeatureNotFound) {
+		t.Errorf("second delete error = %v, want ErrFeatureNotFound", err)
+	}
+
+	// Deleted IDs are never handed out again
+	f, err := s.CreateFeature("Next", "After delete", "", nil)
+	if err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+	if f.ID != "FT-000004" {
+		t.Errorf("new feature ID = %q, want %q", f.ID, "FT-000004")
+	}
+}
+
 // =============================================================================
 // Benchmarks
 // =============================================================================
@@ -522,16 +922,29 @@ func BenchmarkListClients(b *testing.B) {
 	}
 }
 
-func BenchmarkMatchFeature(b *testing.B) {
-	f := Feature{
-		ID:      "FT-000123",
-		Name:    "Enable Dark Mode Toggle",
-		Summary: "Allow users to switch between light and dark themes",
+func BenchmarkSearchIndexMatch(b *testing.B) {
+	ix := newSearchIndex(map[string]Feature{
+		"FT-000123": {
+			ID:      "FT-000123",
+			Name:    "Enable Dark Mode Toggle",
+			Summary: "Allow users to switch between light and dark themes",
+		},
+	})
+	tokens := tokenize("dark")
+
+	b.ResetTimer()
+	for range b.N {
+		_ = ix.match(tokens, textFields, true)
 	}
+}
+
+func BenchmarkSearchFeatures_LargeCatalog(b *testing.B) {
+	s := New()
+	s.SeedFeatures(20000)
 
 	b.ResetTimer()
 	for range b.N {
-		_ = matchFeature(f, "dark")
+		_ = s.SearchFeatures("enable", 20)
 	}
 }
 
@@ -560,3 +973,247 @@ func BenchmarkSeedFeatures(b *testing.B) {
 		s.SeedFeatures(200)
 	}
 }
+
+func TestFeatureLifecycle(t *testing.T) {
+	s := New()
+	f, err := s.CreateFeatureWithStatus("Auth v1", "Legacy login", "", nil, StatusProposed)
+	if err != nil {
+		t.Fatalf("CreateFeatureWithStatus: %v", err)
+	}
+	if f.Status != StatusProposed {
+		t.Fatalf("status = %q, want %q", f.Status, StatusProposed)
+	}
+	next, _ := s.CreateFeature("Auth v2", "New login", "", nil)
+
+	setStatus := func(st Status, replacedBy string) (Feature, error) {
+		upd := FeatureUpdate{Status: &st}
+		if replacedBy != "" {
+			upd.ReplacedBy = &replacedBy
+		}
+		return s.UpdateFeature(f.ID, upd, 0)
+	}
+
+	// Proposed features cannot skip straight to deprecated
+	if _, err := setStatus(StatusDeprecated, ""); !errors.Is(err, ErrInvalidStatus) {
+		t.Errorf("proposed -> deprecated error = %v, want ErrInvalidStatus", err)
+	}
+	if _, err := setStatus(StatusActive, ""); err != nil {
+		t.Fatalf("proposed -> active: %v", err)
+	}
+
+	// Successor must exist and not be the feature itself
+	if _, err := setStatus(StatusDeprecated, f.ID); !errors.Is(err, ErrInvalidReplacement) {
+		t.Errorf("self replacement error = %v, want ErrInvalidReplacement", err)
+	}
+	if _, err := setStatus(StatusDeprecated, "FT-999999"); !errors.Is(err, ErrInvalidReplacement) {
+		t.Errorf("missing replacement error = %v, want ErrInvalidReplacement", err)
+	}
+	got, err := setStatus(StatusDeprecated, next.ID)
+	if err != nil {
+		t.Fatalf("active -> deprecated: %v", err)
+	}
+	if got.ReplacedBy != next.ID {
+		t.Errorf("replaced_by = %q, want %q", got.ReplacedBy, next.ID)
+	}
+
+	// Reactivating clears the successor
+	got, err = setStatus(StatusActive, "")
+	if err != nil {
+		t.Fatalf("deprecated -> active: %v", err)
+	}
+	if got.ReplacedBy != "" {
+		t.Errorf("replaced_by after reactivation = %q, want empty", got.ReplacedBy)
+	}
+
+	if _, err := setStatus(StatusDeprecated, ""); err != nil {
+		t.Fatalf("active -> deprecated: %v", err)
+	}
+	if _, err := setStatus(StatusRemoved, ""); err != nil {
+		t.Fatalf("deprecated -> removed: %v", err)
+	}
+	if _, err := setStatus(StatusActive, ""); !errors.Is(err, ErrInvalidStatus) {
+		t.Errorf("removed -> active error = %v, want ErrInvalidStatus", err)
+	}
+
+	// Removed features drop out of suggestions
+	for _, item := range s.Suggest("Auth", 10) {
+		if item.ID == f.ID {
+			t.Errorf("removed feature %s returned by Suggest", f.ID)
+		}
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	for _, in := range []string{"proposed", "active", "deprecated", "removed"} {
+		if st, ok := ParseStatus(in); !ok || string(st) != in {
+			t.Errorf("ParseStatus(%q) = %q, %v", in, st, ok)
+		}
+	}
+	if _, ok := ParseStatus("archived"); ok {
+		t.Error("ParseStatus accepted unknown status")
+	}
+}
+
+func TestSearchPage(t *testing.T) {
+	s := New()
+	if err := s.SeedFeatures(25); err != nil {
+		t.Fatalf("SeedFeatures: %v", err)
+	}
+
+	page, err := s.SearchPage("", 10, "")
+	if err != nil {
+		t.Fatalf("SearchPage: %v", err)
+	}
+	if len(page.Items) != 10 || page.NextCursor == "" {
+		t.Fatalf("first page: %d items, cursor %q", len(page.Items), page.NextCursor)
+	}
+
+	// Inserts and deletes between requests must not shift the next page
+	if err := s.DeleteFeature(page.Items[9].ID, 0); err != nil {
+		t.Fatalf("DeleteFeature: %v", err)
+	}
+	if _, err := s.CreateFeature("Late", "Created while paging", "", nil); err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, f := range page.Items {
+		seen[f.ID] = true
+	}
+	cursor := page.NextCursor
+	for cursor != "" {
+		page, err = s.SearchPage("", 10, cursor)
+		if err != nil {
+			t.Fatalf("SearchPage(%q): %v", cursor, err)
+		}
+		for _, f := range page.Items {
+			if seen[f.ID] {
+				t.Errorf("feature %s returned twice", f.ID)
+			}
+			seen[f.ID] = true
+		}
+		cursor = page.NextCursor
+	}
+	// 25 seeded + 1 created, the deleted one was already returned
+	if len(seen) != 26 {
+		t.Errorf("saw %d features, want 26", len(seen))
+	}
+
+	// Exact page boundary yields no dangling cursor
+	page, _ = s.SearchPage("", 26, "")
+	if len(page.Items) != 25 || page.NextCursor != "" {
+		t.Errorf("full page: %d items, cursor %q", len(page.Items), page.NextCursor)
+	}
+
+	if _, err := s.SearchPage("", 10, "not a cursor!"); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("bad cursor error = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestSearchPage_ReplayedOutOfOrder(t *testing.T) {
+	// Logs from before IDs only increased can create an ID below others
+	s := New()
+	for _, id := range []string{"FT-000003", "FT-000001", "FT-000004", "FT-000002"} {
+		s.apply(Record{Op: OpPutFeature, Feature: &Feature{ID: id, Name: "Feature " + id, Status: StatusActive, Version: 1}})
+	}
+	s.apply(Record{Op: OpDeleteFeature, FeatureID: "FT-000004"})
+
+	var got []string
+	for cursor := ""; ; {
+		page, err := s.SearchPage("", 1, cursor)
+		if err != nil {
+			t.Fatalf("SearchPage(%q): %v", cursor, err)
+		}
+		for _, f := range page.Items {
+			got = append(got, f.ID)
+		}
+		if cursor = page.NextCursor; cursor == "" {
+			break
+		}
+	}
+	if want := []string{"FT-000001", "FT-000002", "FT-000003"}; !slices.Equal(got, want) {
+		t.Errorf("paged IDs = %v, want %v", got, want)
+	}
+}
+
+func TestSearchRanking(t *testing.T) {
+	s := New()
+	mustCreate := func(name, summary, owner string, tags []string) Feature {
+		t.Helper()
+		f, err := s.CreateFeature(name, summary, owner, tags)
+		if err != nil {
+			t.Fatalf("CreateFeature: %v", err)
+		}
+		return f
+	}
+	sso := mustCreate("Sign-in audit", "Track login attempts for the login page", "Security", []string{"auth"})
+	login := mustCreate("Login", "Sign in form", "Identity", nil)
+	loginFlow := mustCreate("Login flow", "Multi-step sign in", "Identity", nil)
+	tagged := mustCreate("Checkout", "Pay for the cart", "Payments", []string{"billing"})
+
+	ids := func(fs []Feature) []string {
+		out := make([]string, len(fs))
+		for i, f := range fs {
+			out[i] = f.ID
+		}
+		return out
+	}
+
+	// Exact name beats name prefix, which beats summary-only matches
+	got := ids(s.SearchFeatures("login", 10))
+	want := []string{login.ID, loginFlow.ID, sso.ID}
+	if !slices.Equal(got, want) {
+		t.Errorf("SearchFeatures(login) = %v, want %v", got, want)
+	}
+
+	// Exact ID ranks first
+	got = ids(s.SearchFeatures(strings.ToLower(tagged.ID), 10))
+	if len(got) == 0 || got[0] != tagged.ID {
+		t.Errorf("SearchFeatures(id) = %v, want %s first", got, tagged.ID)
+	}
+
+	// Tags and owner are searchable
+	if got := ids(s.SearchFeatures("billing", 10)); !slices.Equal(got, []string{tagged.ID}) {
+		t.Errorf("SearchFeatures(billing) = %v", got)
+	}
+	if got := ids(s.SearchFeatures("payments", 10)); !slices.Equal(got, []string{tagged.ID}) {
+		t.Errorf("SearchFeatures(payments) = %v", got)
+	}
+
+	// Updates and deletes keep the index current
+	name := "Basket"
+	if _, err := s.UpdateFeature(tagged.ID, FeatureUpdate{Name: &name}, 0); err != nil {
+		t.Fatalf("UpdateFeature: %v", err)
+	}
+	if got := s.SearchFeatures("checkout", 10); len(got) != 0 {
+		t.Errorf("stale name still indexed: %v", ids(got))
+	}
+	if got := ids(s.SearchFeatures("bask", 10)); !slices.Equal(got, []string{tagged.ID}) {
+		t.Errorf("SearchFeatures(bask) = %v", got)
+	}
+	if err := s.DeleteFeature(login.ID, 0); err != nil {
+		t.Fatalf("DeleteFeature: %v", err)
+	}
+	if got := ids(s.SearchFeatures("login", 10)); slices.Contains(got, login.ID) {
+		t.Errorf("deleted feature still indexed: %v", got)
+	}
+
+	// Ranked results paginate without gaps or repeats
+	page, err := s.SearchPage("login", 1, "")
+	if err != nil {
+		t.Fatalf("SearchPage: %v", err)
+	}
+	var walked []string
+	for {
+		walked = append(walked, ids(page.Items)...)
+		if page.NextCursor == "" {
+			break
+		}
+		if page, err = s.SearchPage("login", 1, page.NextCursor); err != nil {
+			t.Fatalf("SearchPage: %v", err)
+		}
+	}
+	if want := []string{loginFlow.ID, sso.ID}; !slices.Equal(walked, want) {
+		t.Errorf("walked pages = %v, want %v", walked, want)
+	}
+}
@@ -0,0 +1,300 @@
+package store
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Suggestion is an autocomplete hit with the spans of its name and ID
+// that matched the query.
+type Suggestion struct {
+	Feature
+	Highlights []Highlight
+}
+
+// Highlight marks the matched span [Start, End) of a feature field, in
+// rune (not byte) offsets. Field is "id" or "name".
+type Highlight struct {
+	Field string `json:"field"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// fuzzyFields are the fields searched for typo-tolerant suggestions.
+const fuzzyFields = fieldID | fieldName
+
+// Suggest returns the best matching features for autocomplete purposes.
+// It accepts the same query syntax as SearchPage; a query that does not
+// parse (typically one still being typed) suggests nothing.
+//
+// Search hits come first. If they don't fill limit and the query is plain
+// words, features whose name or ID contains each word give or take a typo
+// (see maxEdits) follow, closest first. Removed features are never
+// suggested. limit is capped at MaxSuggestLimit.
+func (s *Store) Suggest(query string, limit int) []Suggestion {
+	if limit <= 0 {
+		limit = 20
+	}
+	limit = min(limit, MaxSuggestLimit)
+	n, err := parseQuery(query)
+	if err != nil {
+		return []Suggestion{}
+	}
+	tokens := tokenize(freeText(n))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := []Suggestion{}
+	seen := make(map[string]bool)
+	add := func(id string) bool {
+		f := s.features[id]
+		if f.Status == StatusRemoved || seen[id] {
+			return true
+		}
+		seen[id] = true
+		out = append(out, Suggestion{Feature: f, Highlights: highlights(f, tokens)})
+		return len(out) < limit
+	}
+
+	for h := range s.searchLocked(n, scoredFeature{}) {
+		if !add(h.id) {
+			return out
+		}
+	}
+	if !isPlainText(n) {
+		return out
+	}
+
+	type fuzzyHit struct {
+		id   string
+		dist int
+	}
+	dists := s.index.fuzzy(tokens, fuzzyFields)
+	hits := make([]fuzzyHit, 0, len(dists))
+	for id, d := range dists {
+		if !seen[id] {
+			hits = append(hits, fuzzyHit{id: id, dist: d})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].dist != hits[j].dist {
+			return hits[i].dist < hits[j].dist
+		}
+		return hits[i].id < hits[j].id
+	})
+	for _, h := range hits {
+		if !add(h.id) {
+			break
+		}
+	}
+	return out
+}
+
+// isPlainText reports whether n consists only of unscoped words, the
+// only queries that get fuzzy suggestions.
+func isPlainText(n queryNode) bool {
+	switch n := n.(type) {
+	case termNode:
+		return n.fields == textFields && !n.phrase
+	case andNode:
+		for _, c := range n.children {
+			if !isPlainText(c) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// maxEdits is the number of typos tolerated in a query word: none for
+// words of up to two letters, one up to five letters, two beyond.
+func maxEdits(tok string) int {
+	switch n := len([]rune(tok)); {
+	case n <= 2:
+		return 0
+	case n <= 5:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// fuzzy returns the features that, in one of fields, have a word within
+// maxEdits of every token (or starting with such a prefix), mapped to a
+// closeness cost: the sum of edit distances, weighted so that matching a
+// whole word beats matching the start of a longer one.
+func (ix *searchIndex) fuzzy(tokens []string, fields field) map[string]int {
+	var dists map[string]int
+	for _, tok := range tokens {
+		best := make(map[string]int)
+		k := maxEdits(tok)
+		visit := func(term string, d int) {
+			for id, fl := range ix.postings[term] {
+				if fl&fields == 0 {
+					continue
+				}
+				if cur, ok := best[id]; !ok || d < cur {
+					best[id] = d
+				}
+			}
+		}
+		if k == 0 {
+			for i := sort.SearchStrings(ix.terms, tok); i < len(ix.terms) && strings.HasPrefix(ix.terms[i], tok); i++ {
+				visit(ix.terms[i], 0)
+			}
+		} else {
+			want := []rune(tok)
+			for _, term := range ix.terms {
+				runes := []rune(term)
+				if d, n, ok := prefixDistance(want, runes, k); ok {
+					// At equal distance, whole words rank before completions
+					partial := 0
+					if n < len(runes) {
+						partial = 1
+					}
+					visit(term, 2*d+partial)
+				}
+			}
+		}
+
+		if dists == nil {
+			dists = best
+			continue
+		}
+		for id, d := range dists {
+			if b, ok := best[id]; ok {
+				dists[id] = d + b
+			} else {
+				delete(dists, id)
+			}
+		}
+	}
+	return dists
+}
+
+// prefixDistance returns the smallest edit distance between a and any
+// prefix of b, and the length of that prefix. Edits are insertions,
+// deletions, substitutions and transpositions of adjacent runes (optimal
+// string alignment). ok is false if the distance exceeds k; the
+// computation stops as soon as that is certain.
+func prefixDistance(a, b []rune, k int) (dist, prefixLen int, ok bool) {
+	// Prefixes longer than len(a)+k are always more than k edits away
+	b = b[:min(len(b), len(a)+k)]
+
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > k {
+			return 0, 0, false
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+
+	// prev holds the last row: distance from a to each prefix of b.
+	// On ties prefer the longer prefix, so highlights cover more.
+	dist, prefixLen = prev[0], 0
+	for j, d := range prev {
+		if d <= dist {
+			dist, prefixLen = d, j
+		}
+	}
+	return dist, prefixLen, dist <= k
+}
+
+// highlightFields are the fields suggestions carry highlights for,
+// in the order highlights are reported.
+var highlightFields = []string{"id", "name"}
+
+// highlights returns the spans of f's ID and name that match tokens.
+// Each token highlights only its closest matches, so a word found exactly
+// is not accompanied by near misses elsewhere.
+func highlights(f Feature, tokens []string) []Highlight {
+	if len(tokens) == 0 {
+		return nil
+	}
+	fieldWords := [][]word{words(f.ID), words(f.Name)}
+
+	// Span end per (field, word), across all tokens
+	ends := make([]map[int]int, len(fieldWords))
+	for fi := range ends {
+		ends[fi] = make(map[int]int)
+	}
+	type match struct {
+		fi, start, end, dist int
+	}
+	for _, tok := range tokens {
+		want, k := []rune(tok), maxEdits(tok)
+		var matches []match
+		best := k
+		for fi, ws := range fieldWords {
+			for _, w := range ws {
+				if d, n, ok := prefixDistance(want, w.runes, k); ok && n > 0 {
+					matches = append(matches, match{fi: fi, start: w.start, end: w.start + n, dist: d})
+					best = min(best, d)
+				}
+			}
+		}
+		for _, m := range matches {
+			if m.dist == best {
+				ends[m.fi][m.start] = max(ends[m.fi][m.start], m.end)
+			}
+		}
+	}
+
+	var out []Highlight
+	for fi, spans := range ends {
+		starts := make([]int, 0, len(spans))
+		for start := range spans {
+			starts = append(starts, start)
+		}
+		sort.Ints(starts)
+		for _, start := range starts {
+			out = append(out, Highlight{Field: highlightFields[fi], Start: start, End: spans[start]})
+		}
+	}
+	return out
+}
+
+// word is a lowercased letter/digit run and its rune offset in the text.
+type word struct {
+	start int
+	runes []rune
+}
+
+// words splits text like tokenize, keeping rune offsets.
+func words(text string) []word {
+	var out []word
+	var cur *word
+	for i, r := range []rune(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if cur == nil {
+				out = append(out, word{start: i})
+				cur = &out[len(out)-1]
+			}
+			cur.runes = append(cur.runes, unicode.ToLower(r))
+			continue
+		}
+		cur = nil
+	}
+	return out
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSuggest_Fuzzy(t *testing.T) {
+	s := New()
+	for _, name := range []string{"One Click Checkout", "Check Balance", "Dark Mode"} {
+		if _, err := s.CreateFeature(name, "", "", nil); err != nil {
+			t.Fatalf("CreateFeature: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"chekout", []string{"FT-000001"}},                            // one typo
+		{"chekou", []string{"FT-000001"}},                             // typo while still typing
+		{"drak mdoe", []string{"FT-000003"}},                          // typos in every word
+		{"check", []string{"FT-000002", "FT-000001"}},                 // exact hits first
+		{"ft-00003", []string{"FT-000003", "FT-000001", "FT-000002"}}, // closest ID first
+		{"xyzzy", nil},
+		{"chekout -name:click", nil}, // no fuzzy matching for structured queries
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got []string
+			for _, sg := range s.Suggest(tt.query, 10) {
+				got = append(got, sg.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Suggest(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggest_Highlights(t *testing.T) {
+	s := New()
+	if _, err := s.CreateFeature("One Click Checkout", "", "", nil); err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  []Highlight
+	}{
+		{"check", []Highlight{{Field: "name", Start: 10, End: 15}}},
+		{"one chekout", []Highlight{{Field: "name", Start: 0, End: 3}, {Field: "name", Start: 10, End: 18}}},
+		{"ft-000001", []Highlight{{Field: "id", Start: 0, End: 2}, {Field: "id", Start: 3, End: 9}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := s.Suggest(tt.query, 10)
+			if len(got) != 1 {
+				t.Fatalf("Suggest(%q) returned %d results, want 1", tt.query, len(got))
+			}
+			if !slices.Equal(got[0].Highlights, tt.want) {
+				t.Errorf("Suggest(%q) highlights = %+v, want %+v", tt.query, got[0].Highlights, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrefixDistance(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		k       int
+		dist, n int
+		ok      bool
+	}{
+		{"check", "checkout", 1, 0, 5, true},
+		{"chekout", "checkout", 2, 1, 8, true},
+		{"chekou", "checkout", 1, 1, 7, true},
+		{"drak", "dark", 1, 1, 4, true}, // transposition is one edit
+		{"abc", "xyz", 1, 0, 0, false},
+		{"ab", "ab", 0, 0, 2, true},
+	}
+	for _, tt := range tests {
+		dist, n, ok := prefixDistance([]rune(tt.a), []rune(tt.b), tt.k)
+		if ok != tt.ok || (ok && (dist != tt.dist || n != tt.n)) {
+			t.Errorf("prefixDistance(%q, %q, %d) = %d, %d, %v; want %d, %d, %v",
+				tt.a, tt.b, tt.k, dist, n, ok, tt.dist, tt.n, tt.ok)
+		}
+	}
+}
+
+func BenchmarkSuggest_Fuzzy(b *testing.B) {
+	s := New()
+	s.SeedFeatures(20000)
+
+	b.ResetTimer()
+	for range b.N {
+		_ = s.Suggest("markting stratgy", 10)
+	}
+}
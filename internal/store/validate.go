@@ -0,0 +1,111 @@
+package store
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Length limits of feature fields in bytes, enforced by the APIs.
+const (
+	MaxNameLen    = 200
+	MaxSummaryLen = 1000
+	MaxOwnerLen   = 100
+)
+
+// FieldError explains why one submitted field is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// CheckFeatureFields checks trimmed feature fields against the length
+// limits, returning an error for each field that exceeds its limit.
+func CheckFeatureFields(name, summary, owner string) []FieldError {
+	var errs []FieldError
+	for _, f := range []struct {
+		field, value string
+		max          int
+	}{
+		{"name", name, MaxNameLen},
+		{"summary", summary, MaxSummaryLen},
+		{"owner", owner, MaxOwnerLen},
+	} {
+		if len(f.value) > f.max {
+			errs = append(errs, FieldError{Field: f.field, Message: "too long (max " + strconv.Itoa(f.max) + ")"})
+		}
+	}
+	return errs
+}
+
+// NewFeature is a feature submitted for creation, as ParseNewFeature
+// returns it.
+type NewFeature struct {
+	Name    string
+	Summary string
+	Owner   string
+	Tags    []string
+	Status  Status
+}
+
+// ParseNewFeature trims and checks the fields of a feature submitted for
+// creation: name and summary are required, lengths are limited, empty tags
+// are dropped, and status must be proposed or active (active if empty). It
+// returns an error for each invalid field.
+func ParseNewFeature(name, summary, owner string, tags []string, status string) (NewFeature, []FieldError) {
+	f := NewFeature{
+		Name:    strings.TrimSpace(name),
+		Summary: strings.TrimSpace(summary),
+		Owner:   strings.TrimSpace(owner),
+		Tags:    CleanTags(tags),
+		Status:  StatusActive,
+	}
+	var errs []FieldError
+	if f.Name == "" {
+		errs = append(errs, FieldError{Field: "name", Message: "required"})
+	}
+	if f.Summary == "" {
+		errs = append(errs, FieldError{Field: "summary", Message: "required"})
+	}
+	errs = append(errs, CheckFeatureFields(f.Name, f.Summary, f.Owner)...)
+	if status != "" {
+		st, ok := ParseStatus(status)
+		if !ok || (st != StatusProposed && st != StatusActive) {
+			errs = append(errs, FieldError{Field: "status", Message: "must be proposed or active"})
+		}
+		f.Status = st
+	}
+	return f, errs
+}
+
+// CheckRole parses a submitted role, RoleUser if empty. For an unknown role
+// it returns an error naming the known ones.
+func CheckRole(role string) (Role, []FieldError) {
+	if role == "" {
+		return RoleUser, nil
+	}
+	if r, ok := ParseRole(role); ok {
+		return r, nil
+	}
+	return "", []FieldError{UnknownRole(role)}
+}
+
+// UnknownRole returns the error for a role that ParseRole rejects.
+func UnknownRole(role string) FieldError {
+	names := make([]string, 0, len(Roles()))
+	for _, r := range Roles() {
+		names = append(names, string(r))
+	}
+	return FieldError{Field: "role", Message: "unknown role " + strconv.Quote(role) + "; expected one of " + strings.Join(names, ", ")}
+}
+
+// CleanTags trims tags and drops empty ones.
+func CleanTags(in []string) []string {
+	var tags []string
+	for _, t := range in {
+		t = strings.TrimSpace(t)
+		if t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckFeatureFields(t *testing.T) {
+	if errs := CheckFeatureFields(strings.Repeat("n", MaxNameLen), "", strings.Repeat("o", MaxOwnerLen)); len(errs) != 0 {
+		t.Errorf("fields at the limits: %v, want none", errs)
+	}
+	errs := CheckFeatureFields(strings.Repeat("n", MaxNameLen+1), "ok", strings.Repeat("o", MaxOwnerLen+1))
+	want := []FieldError{{"name", "too long (max 200)"}, {"owner", "too long (max 100)"}}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("CheckFeatureFields = %v, want %v", errs, want)
+	}
+}
+
+func TestCleanTags(t *testing.T) {
+	if got := CleanTags([]string{" ui ", "", "  ", "web"}); !reflect.DeepEqual(got, []string{"ui", "web"}) {
+		t.Errorf("CleanTags = %q, want [ui web]", got)
+	}
+	if got := CleanTags(nil); got != nil {
+		t.Errorf("CleanTags(nil) = %q, want nil", got)
+	}
+}
+
+func TestParseNewFeature(t *testing.T) {
+	f, errs := ParseNewFeature(" Dark mode ", " Darker ", " web ", []string{" ui ", ""}, "")
+	want := NewFeature{Name: "Dark mode", Summary: "Darker", Owner: "web", Tags: []string{"ui"}, Status: StatusActive}
+	if len(errs) != 0 || !reflect.DeepEqual(f, want) {
+		t.Errorf("ParseNewFeature = %+v, %v; want %+v", f, errs, want)
+	}
+	if f, errs := ParseNewFeature("n", "s", "", nil, "proposed"); len(errs) != 0 || f.Status != StatusProposed {
+		t.Errorf("proposed: %+v, %v", f, errs)
+	}
+
+	_, errs = ParseNewFeature(" ", "", "", nil, "deprecated")
+	wantErrs := []FieldError{{"name", "required"}, {"summary", "required"}, {"status", "must be proposed or active"}}
+	if !reflect.DeepEqual(errs, wantErrs) {
+		t.Errorf("ParseNewFeature errors = %v, want %v", errs, wantErrs)
+	}
+}
+
+func TestCheckRole(t *testing.T) {
+	if r, errs := CheckRole(""); r != RoleUser || errs != nil {
+		t.Errorf("CheckRole(\"\") = %q, %v; want user", r, errs)
+	}
+	if r, errs := CheckRole("admin"); r != RoleAdmin || errs != nil {
+		t.Errorf("CheckRole(admin) = %q, %v", r, errs)
+	}
+	_, errs := CheckRole("root")
+	if len(errs) != 1 || errs[0].Field != "role" || !strings.Contains(errs[0].Message, `unknown role "root"; expected one of user`) {
+		t.Errorf("CheckRole(root) errors = %v", errs)
+	}
+}
@@ -0,0 +1,129 @@
+// Package testpki issues throwaway certificates for tests. Its functions
+// panic on failure, which only a broken random source can cause, so
+// fixtures can be package variables shared by a package's tests.
+package testpki
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"time"
+)
+
+// CA is a self-signed certificate authority. Its Leaf is always set.
+type CA struct {
+	tls.Certificate
+}
+
+// NewCA returns a new CA named cn.
+func NewCA(cn string) *CA {
+	return &CA{SelfSigned(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: cn},
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	})}
+}
+
+// Issue signs tmpl with the CA for a fresh key. It sets the serial number
+// and, unless tmpl has them, a validity from an hour ago to a day from now.
+func (c *CA) Issue(tmpl *x509.Certificate) tls.Certificate {
+	return issue(tmpl, c.Leaf, c.PrivateKey)
+}
+
+// Client returns a client certificate for cn.
+func (c *CA) Client(cn string) tls.Certificate {
+	return c.Issue(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: cn},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+}
+
+// Server returns a server certificate for dnsNames.
+func (c *CA) Server(dnsNames ...string) tls.Certificate {
+	return c.Issue(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:    dnsNames,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+}
+
+// Pool returns a certificate pool holding only the CA.
+func (c *CA) Pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(c.Leaf)
+	return pool
+}
+
+// SelfSigned signs tmpl with a fresh key, completing it as Issue does.
+func SelfSigned(tmpl *x509.Certificate) tls.Certificate {
+	return issue(tmpl, nil, nil)
+}
+
+// issue signs tmpl with parent and parentKey, or self-signs it if parent
+// is nil.
+func issue(tmpl, parent *x509.Certificate, parentKey any) tls.Certificate {
+	key := newKey()
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	must(err)
+	tmpl.SerialNumber = serial
+	if tmpl.NotBefore.IsZero() {
+		tmpl.NotBefore = time.Now().Add(-time.Hour)
+	}
+	if tmpl.NotAfter.IsZero() {
+		tmpl.NotAfter = time.Now().Add(24 * time.Hour)
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	must(err)
+	cert, err := x509.ParseCertificate(der)
+	must(err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert}
+}
+
+// CSR returns a PEM certificate request for cn and a fresh key.
+func CSR(cn string) string {
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: cn}}, newKey())
+	must(err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+}
+
+// CertPEM returns cert's leaf certificate as PEM.
+func CertPEM(cert tls.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Leaf.Raw})
+}
+
+// KeyPEM returns cert's private key as a PKCS #8 PEM block.
+func KeyPEM(cert tls.Certificate) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	must(err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+// WriteFiles writes cert and its key as PEM to certFile and keyFile.
+func WriteFiles(cert tls.Certificate, certFile, keyFile string) error {
+	if err := os.WriteFile(certFile, CertPEM(cert), 0o600); err != nil {
+		return err
+	}
+	return os.WriteFile(keyFile, KeyPEM(cert), 0o600)
+}
+
+func newKey() *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	must(err)
+	return key
+}
+
+func must(err error) {
+	if err != nil {
+		panic("testpki: " + err.Error())
+	}
+}
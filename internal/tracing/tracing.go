@@ -0,0 +1,106 @@
+// Package tracing sets up OpenTelemetry tracing for featctl and
+// feature-atlasd. Tracing is off unless configured; instrumented code then
+// runs against OpenTelemetry's no-op provider at negligible cost.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Setup installs a global tracer provider for service that exports spans to
+// dest, and the W3C trace-context and baggage propagators. dest is one of:
+//
+//	""             tracing off
+//	"otlp"         OTLP over HTTP, configured by the standard
+//	               OTEL_EXPORTER_OTLP_* environment variables
+//	"otlp:<url>"   OTLP over HTTP to the collector at url, e.g. http://localhost:4318
+//	"file:<path>"  one JSON span per line, appended to path
+//
+// The returned function flushes pending spans and releases the exporter; it
+// must be called before the process exits.
+func Setup(ctx context.Context, service, dest string) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+	if dest == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var exporter sdktrace.SpanExporter
+	var file *os.File
+	kind, arg, _ := strings.Cut(dest, ":")
+	switch kind {
+	case "otlp":
+		var opts []otlptracehttp.Option
+		if arg != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(arg))
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("otlp exporter: %w", err)
+		}
+	case "file":
+		if arg == "" {
+			return nil, errors.New("trace file path is empty")
+		}
+		//nolint:gosec // path is from trusted command-line flag
+		file, err = os.OpenFile(arg, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("open trace file: %w", err)
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("file exporter: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown trace destination %q; expected otlp, otlp:<url> or file:<path>", dest)
+	}
+
+	res, err := resource.Merge(resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(service)))
+	if err != nil {
+		return nil, fmt.Errorf("trace resource: %w", err)
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if file != nil {
+			err = errors.Join(err, file.Close())
+		}
+		return err
+	}, nil
+}
+
+// storeScope names the tracer of StoreCall's spans.
+const storeScope = "github.com/JoobyPM/feature-atlas-service/internal/store"
+
+// StoreCall starts a span named "store.<op>" under ctx's span for a call
+// into the feature store, which takes no context of its own. The returned
+// function ends the span, recording err as its error if it is not nil; call
+// it as soon as the store call returns.
+func StoreCall(ctx context.Context, op string) (end func(err error)) {
+	_, span := otel.Tracer(storeScope).Start(ctx, "store."+op, trace.WithSpanKind(trace.SpanKindInternal))
+	return func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetupFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+	shutdown, err := Setup(context.Background(), "test-service", "file:"+path)
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	ctx, span := otel.Tracer("test").Start(context.Background(), "parent")
+	_, child := otel.Tracer("test").Start(ctx, "child")
+	child.End()
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	for _, want := range []string{`"Name":"parent"`, `"Name":"child"`, `"test-service"`, span.SpanContext().TraceID().String()} {
+		if !strings.Contains(out, want) {
+			t.Errorf("trace file missing %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestSetupPropagation(t *testing.T) {
+	shutdown, err := Setup(context.Background(), "test-service", "")
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	defer shutdown(context.Background())
+
+	carrier := propagation.HeaderCarrier{}
+	carrier.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), carrier)
+	out := propagation.HeaderCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, out)
+	if got := out.Get("traceparent"); got != carrier.Get("traceparent") {
+		t.Errorf("traceparent = %q, want %q", got, carrier.Get("traceparent"))
+	}
+}
+
+func TestSetupInvalid(t *testing.T) {
+	for _, dest := range []string{"jaeger", "file:", "file"} {
+		if _, err := Setup(context.Background(), "test-service", dest); err == nil {
+			t.Errorf("Setup(%q) succeeded, want error", dest)
+		}
+	}
+}
+
+func TestStoreCall(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(prev)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+	StoreCall(ctx, "GetFeature")(nil)
+	StoreCall(ctx, "UpdateFeature")(errors.New("feature not found"))
+	parent.End()
+
+	spans := rec.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("recorded %d spans, want 3", len(spans))
+	}
+	read, write := spans[0], spans[1]
+	if read.Name() != "store.GetFeature" || write.Name() != "store.UpdateFeature" {
+		t.Errorf("span names = %q, %q; want store.GetFeature, store.UpdateFeature", read.Name(), write.Name())
+	}
+	for _, s := range []sdktrace.ReadOnlySpan{read, write} {
+		if s.Parent().SpanID() != parent.SpanContext().SpanID() || s.SpanKind() != trace.SpanKindInternal {
+			t.Errorf("%s: parent %s, kind %s; want an internal child of the request", s.Name(), s.Parent().SpanID(), s.SpanKind())
+		}
+	}
+	if read.Status().Code != codes.Unset || len(read.Events()) != 0 {
+		t.Errorf("successful call: status %+v, %d events; want no error", read.Status(), len(read.Events()))
+	}
+	if write.Status() != (sdktrace.Status{Code: codes.Error, Description: "feature not found"}) ||
+		len(write.Events()) != 1 || write.Events()[0].Name != "exception" {
+		t.Errorf("failed call: status %+v, events %+v; want the error recorded", write.Status(), write.Events())
+	}
+}
@@ -71,8 +71,8 @@ const (
 
 // API query limits.
 const (
-	duplicateCheckLimit = 50  // Limit for duplicate name search
-	cacheRefreshLimit   = 100 // Limit for cache refresh (marks incomplete if hit)
+	duplicateCheckLimit  = 50  // Limit for duplicate name search
+	cacheRefreshPageSize = 500 // Page size when walking the catalog for cache refresh
 )
 
 // NewFormModel creates a new form model.
@@ -416,13 +416,14 @@ func (m *FormModel) checkDuplicateCmd() tea.Cmd {
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
 
-			// Search for features with similar name
-			results, err := client.Search(ctx, name, duplicateCheckLimit)
+			// Search for features with similar name (quoted so the name
+			// is not parsed as query syntax)
+			results, err := client.Search(ctx, apiclient.PhraseQuery("name", name), duplicateCheckLimit)
 			if err != nil {
 				return duplicateCheckResultMsg{err: err}
 			}
 
-			// Client-side exact match (server uses Contains)
+			// Client-side exact match (server matches the phrase anywhere in the name)
 			for i := range results {
 				if strings.EqualFold(results[i].Name, name) {
 					return duplicateCheckResultMsg{duplicate: &results[i]}
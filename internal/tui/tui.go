@@ -97,6 +97,10 @@ var (
 	selectedCountStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color(colorPrimary)).
 				Bold(true)
+
+	matchHighlightStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color(colorYellow)).
+				Bold(true)
 )
 
 // FeatureStatus indicates where a feature exists.
@@ -113,10 +117,12 @@ const (
 
 // featureItem represents a feature in the list.
 type featureItem struct {
-	id      string
-	name    string
-	summary string
-	status  FeatureStatus
+	id         string
+	name       string
+	summary    string
+	status     FeatureStatus
+	lifecycle  string                // Server lifecycle status (proposed, active, deprecated)
+	highlights []apiclient.Highlight // Spans of id/name that matched the query
 }
 
 // State represents the current UI state.
@@ -165,9 +171,10 @@ type Options struct {
 
 // selectedItem stores full data for a selected feature (SST for selections).
 type selectedItem struct {
-	id      string
-	name    string
-	summary string
+	id        string
+	name      string
+	summary   string
+	lifecycle string
 }
 
 // Model is the Bubble Tea model for the TUI.
@@ -211,7 +218,7 @@ type suggestionsMsg struct {
 // New creates a new TUI model.
 func New(client *apiclient.Client, opts Options) Model {
 	ti := textinput.New()
-	ti.Placeholder = "Search features..."
+	ti.Placeholder = "Search features... (e.g. owner:payments -status:deprecated)"
 	ti.Focus()
 	ti.CharLimit = 100
 	ti.Width = 40
@@ -299,6 +306,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						Summary:  created.Summary,
 						Owner:    created.Owner,
 						Tags:     created.Tags,
+						Status:   created.Status,
 						IsSynced: true, // Created on server, so it's synced
 					}))
 				}
@@ -309,6 +317,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						ID:      created.ID,
 						Name:    created.Name,
 						Summary: created.Summary,
+						Status:  created.Status,
 					})
 					cmds = append(cmds, m.saveCacheCmd())
 				}
@@ -354,10 +363,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.items = make([]featureItem, len(msg.items))
 		for i, item := range msg.items {
 			m.items[i] = featureItem{
-				id:      item.ID,
-				name:    item.Name,
-				summary: item.Summary,
-				status:  m.getFeatureStatus(item.ID),
+				id:         item.ID,
+				name:       item.Name,
+				summary:    item.Summary,
+				status:     m.getFeatureStatus(item.ID),
+				lifecycle:  item.Status,
+				highlights: item.Highlights,
 			}
 		}
 		// Reset cursor if it's out of bounds
@@ -509,9 +520,10 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 	if len(m.selected) == 0 && len(m.items) > 0 && m.cursorIndex < len(m.items) {
 		item := m.items[m.cursorIndex]
 		m.selected[item.id] = selectedItem{
-			id:      item.id,
-			name:    item.name,
-			summary: item.summary,
+			id:        item.id,
+			name:      item.name,
+			summary:   item.summary,
+			lifecycle: item.lifecycle,
 		}
 	}
 
@@ -549,9 +561,10 @@ func (m Model) handleSpaceToggle() (tea.Model, tea.Cmd) {
 		delete(m.selected, item.id)
 	} else {
 		m.selected[item.id] = selectedItem{
-			id:      item.id,
-			name:    item.name,
-			summary: item.summary,
+			id:        item.id,
+			name:      item.name,
+			summary:   item.summary,
+			lifecycle: item.lifecycle,
 		}
 	}
 
@@ -566,9 +579,10 @@ func (m Model) handleSelectAll() (tea.Model, tea.Cmd) {
 
 	for _, item := range m.items {
 		m.selected[item.id] = selectedItem{
-			id:      item.id,
-			name:    item.name,
-			summary: item.summary,
+			id:        item.id,
+			name:      item.name,
+			summary:   item.summary,
+			lifecycle: item.lifecycle,
 		}
 	}
 	return m, nil
@@ -680,8 +694,15 @@ func (m Model) renderItemList() string {
 		// Status indicator
 		status := m.renderStatus(item.status)
 
+		// Flag features that are on their way out
+		if item.lifecycle == apiclient.StatusDeprecated {
+			status += " " + itemDimStyle.Render("(deprecated)")
+		}
+
 		// Build line content
-		content := fmt.Sprintf("%s %s - %s  %s", checkbox, item.id, item.name, status)
+		id := renderHighlights(item.id, "id", item.highlights)
+		name := renderHighlights(item.name, "name", item.highlights)
+		content := fmt.Sprintf("%s %s - %s  %s", checkbox, id, name, status)
 
 		// Apply cursor styling
 		if i == m.cursorIndex {
@@ -702,6 +723,28 @@ func (m Model) renderItemList() string {
 	return b.String()
 }
 
+// renderHighlights renders text with the highlighted spans for field
+// (rune offsets, as sent by the server) in matchHighlightStyle.
+func renderHighlights(text, field string, highlights []apiclient.Highlight) string {
+	runes := []rune(text)
+	var b strings.Builder
+	pos := 0
+	for _, h := range highlights {
+		if h.Field != field {
+			continue
+		}
+		start, end := max(h.Start, pos), min(h.End, len(runes))
+		if start >= end {
+			continue
+		}
+		b.WriteString(string(runes[pos:start]))
+		b.WriteString(matchHighlightStyle.Render(string(runes[start:end])))
+		pos = end
+	}
+	b.WriteString(string(runes[pos:]))
+	return b.String()
+}
+
 // calculateVisibleRange returns the start and end indices for visible items.
 // Ensures cursor is always visible with proper scrolling.
 func (m Model) calculateVisibleRange() (start, end int) {
@@ -802,6 +845,7 @@ func (m Model) getSelectedItems() []apiclient.SuggestItem {
 			ID:      sel.id,
 			Name:    sel.name,
 			Summary: sel.summary,
+			Status:  sel.lifecycle,
 		})
 	}
 	return items
@@ -889,7 +933,7 @@ type cacheSavedMsg struct{ err error }
 
 type manifestSavedMsg struct{ err error }
 
-// refreshCacheCmd fetches features and returns result via message.
+// refreshCacheCmd fetches the full catalog and returns result via message.
 func (m Model) refreshCacheCmd() tea.Cmd {
 	// Capture dependencies for closure
 	clientRef := m.client
@@ -906,26 +950,24 @@ func (m Model) refreshCacheCmd() tea.Cmd {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
-		// Fetch features (API doesn't support pagination, so single request)
-		features, err := clientRef.Search(ctx, "", cacheRefreshLimit)
-		if err != nil {
-			return cacheRefreshResultMsg{err: err}
-		}
-
-		allFeatures := make([]cache.CachedFeature, len(features))
-		for i, f := range features {
-			allFeatures[i] = cache.CachedFeature{
+		// Walk every page so the cache holds the full catalog
+		var allFeatures []cache.CachedFeature
+		for f, err := range clientRef.SearchAll(ctx, "", cacheRefreshPageSize) {
+			if err != nil {
+				return cacheRefreshResultMsg{err: err}
+			}
+			allFeatures = append(allFeatures, cache.CachedFeature{
 				ID:      f.ID,
 				Name:    f.Name,
 				Summary: f.Summary,
-			}
+				Status:  f.Status,
+			})
 		}
 
-		// Mark incomplete if we hit limit (server may have more features)
 		return cacheRefreshResultMsg{
 			features:  allFeatures,
 			serverURL: serverURL,
-			complete:  len(features) < cacheRefreshLimit,
+			complete:  true,
 		}
 	}
 }
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -611,3 +612,39 @@ func TestModel_FetchSuggestions_NilClient(t *testing.T) {
 	require.True(t, ok, "should return suggestionsMsg")
 	assert.ErrorIs(t, sugMsg.err, ErrNoServerConnection, "should return ErrNoServerConnection")
 }
+
+// TestRenderHighlights verifies matched spans are styled and the text is preserved.
+func TestRenderHighlights(t *testing.T) {
+	highlights := []apiclient.Highlight{
+		{Field: "id", Start: 0, End: 2},
+		{Field: "name", Start: 0, End: 3},
+		{Field: "name", Start: 10, End: 99}, // Out of range is clamped
+	}
+
+	got := renderHighlights("One Click Checkout", "name", highlights)
+	assert.Equal(t, "One Click Checkout", stripANSI(got), "text should be unchanged")
+	assert.Contains(t, got, matchHighlightStyle.Render("One"))
+	assert.Contains(t, got, matchHighlightStyle.Render("Checkout"))
+
+	assert.Equal(t, "Dark Mode", renderHighlights("Dark Mode", "name", nil))
+	assert.Equal(t, "Café", stripANSI(renderHighlights("Café", "name", []apiclient.Highlight{{Field: "name", Start: 2, End: 4}})))
+}
+
+// stripANSI removes terminal escape sequences from s.
+func stripANSI(s string) string {
+	var b strings.Builder
+	inEscape := false
+	for _, r := range s {
+		switch {
+		case r == '\x1b':
+			inEscape = true
+		case inEscape:
+			if r == 'm' {
+				inEscape = false
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/JoobyPM/feature-atlas-service/internal/apiclient"
 	"github.com/JoobyPM/feature-atlas-service/internal/manifest"
 	"github.com/JoobyPM/feature-atlas-service/test/integration/testutil"
 )
@@ -469,3 +470,68 @@ func TestLint_InvalidFeatureID(t *testing.T) {
 			strings.Contains(strings.ToLower(stderr), "not found"),
 		"error should indicate invalid ID: %s", stderr)
 }
+
+// TestLint_SyncedStatusFromServer verifies that lint reads the status of a
+// synced feature from the server rather than the manifest's stale copy.
+func TestLint_SyncedStatusFromServer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test in short mode")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	env, err := testutil.SetupTestEnv(ctx)
+	require.NoError(t, err, "setup test environment")
+	defer env.Cleanup(ctx)
+
+	adminClient, err := testutil.NewAdminClient(env)
+	require.NoError(t, err)
+	features, err := adminClient.Search(ctx, "status:active", 1)
+	require.NoError(t, err)
+	require.NotEmpty(t, features)
+	serverID := features[0].ID
+
+	// The manifest still has the feature as active
+	workDir := t.TempDir()
+	m := manifest.New()
+	m.Features[serverID] = manifest.Entry{
+		Name:     features[0].Name,
+		Summary:  features[0].Summary,
+		Status:   "active",
+		Synced:   true,
+		SyncedAt: "2024-01-01T00:00:00Z",
+	}
+	manifestPath := filepath.Join(workDir, ".feature-atlas.yaml")
+	require.NoError(t, m.Save(manifestPath))
+
+	removed := "removed"
+	deprecated := "deprecated"
+	_, err = adminClient.UpdateFeature(ctx, serverID, apiclient.UpdateFeatureRequest{Status: &deprecated}, 0)
+	require.NoError(t, err)
+	_, err = adminClient.UpdateFeature(ctx, serverID, apiclient.UpdateFeatureRequest{Status: &removed}, 0)
+	require.NoError(t, err)
+
+	testFile := createTestYAMLFile(t, workDir, serverID)
+	args := []string{
+		"--manifest", manifestPath,
+		"--server", env.Server.APIURL(),
+		"--ca", env.Certs.CACertPath,
+		"--cert", env.Certs.AdminCertPath,
+		"--key", env.Certs.AdminKeyPath,
+	}
+
+	_, stderr, exitCode := runFeatctl(t, workDir, append([]string{"lint"}, append(args, testFile)...)...)
+	assert.NotEqual(t, 0, exitCode, "lint should fail for a feature removed on the server")
+	assert.Contains(t, stderr, "has been removed")
+
+	// Offline, lint trusts the manifest until sync refreshes it
+	_, stderr, exitCode = runFeatctl(t, workDir, "lint", "--offline", "--manifest", manifestPath, testFile)
+	assert.Equal(t, 0, exitCode, "lint --offline should use the manifest: %s", stderr)
+
+	_, stderr, exitCode = runFeatctl(t, workDir, append([]string{"manifest", "sync"}, args...)...)
+	require.Equal(t, 0, exitCode, "manifest sync failed: %s", stderr)
+	_, stderr, exitCode = runFeatctl(t, workDir, "lint", "--offline", "--manifest", manifestPath, testFile)
+	assert.NotEqual(t, 0, exitCode, "lint --offline should fail after sync refreshed the status")
+	assert.Contains(t, stderr, "has been removed")
+}
@@ -4,6 +4,10 @@ package integration
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -85,8 +89,9 @@ func TestAdminCreateFeature_Unauthorized(t *testing.T) {
 		Summary: "This should fail",
 	})
 	require.Error(t, err, "non-admin should not be able to create features")
-	// Server returns "admin only" for non-admin users trying to access /admin/ routes
-	assert.Contains(t, err.Error(), "admin")
+	// A user without teams cannot create features
+	require.ErrorIs(t, err, apiclient.ErrPermissionDenied)
+	assert.Contains(t, err.Error(), "features:write")
 }
 
 // TestAdminCreateFeature_BadRequest verifies validation errors.
@@ -106,32 +111,46 @@ func TestAdminCreateFeature_BadRequest(t *testing.T) {
 	require.NoError(t, err, "create admin client")
 
 	testCases := []struct {
-		name    string
-		req     apiclient.CreateFeatureRequest
-		wantErr string
+		name       string
+		req        apiclient.CreateFeatureRequest
+		wantFields []string
 	}{
 		{
-			name:    "missing name",
-			req:     apiclient.CreateFeatureRequest{Summary: "Has summary but no name"},
-			wantErr: "name and summary required",
+			name:       "missing name",
+			req:        apiclient.CreateFeatureRequest{Summary: "Has summary but no name"},
+			wantFields: []string{"name"},
 		},
 		{
-			name:    "missing summary",
-			req:     apiclient.CreateFeatureRequest{Name: "Has name but no summary"},
-			wantErr: "name and summary required",
+			name:       "missing summary",
+			req:        apiclient.CreateFeatureRequest{Name: "Has name but no summary"},
+			wantFields: []string{"summary"},
 		},
 		{
-			name:    "both missing",
-			req:     apiclient.CreateFeatureRequest{},
-			wantErr: "name and summary required",
+			name:       "both missing",
+			req:        apiclient.CreateFeatureRequest{},
+			wantFields: []string{"name", "summary"},
+		},
+		{
+			name:       "name too long",
+			req:        apiclient.CreateFeatureRequest{Name: strings.Repeat("n", 201), Summary: "Fine"},
+			wantFields: []string{"name"},
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			_, err := adminClient.CreateFeature(ctx, tc.req)
-			require.Error(t, err)
-			assert.Contains(t, err.Error(), tc.wantErr)
+			require.ErrorIs(t, err, apiclient.ErrValidation)
+			var apiErr *apiclient.APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+			assert.Equal(t, "validation_failed", apiErr.Code)
+			assert.NotEmpty(t, apiErr.RequestID)
+			var fields []string
+			for _, f := range apiErr.Fields {
+				fields = append(fields, f.Field)
+			}
+			assert.Equal(t, tc.wantFields, fields)
 		})
 	}
 }
@@ -246,6 +265,49 @@ func TestSearch(t *testing.T) {
 	}
 }
 
+// TestSearch_Pagination verifies that walking all pages returns every feature once.
+func TestSearch_Pagination(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	env, err := testutil.SetupTestEnv(ctx)
+	require.NoError(t, err, "setup test environment")
+	defer env.Cleanup(ctx)
+
+	adminClient, err := testutil.NewAdminClient(env)
+	require.NoError(t, err, "create admin client")
+
+	first, err := adminClient.SearchPage(ctx, "", 7, "")
+	require.NoError(t, err, "first page")
+	assert.Len(t, first.Items, 7)
+	assert.NotEmpty(t, first.NextCursor, "first page should have a next cursor")
+
+	// A feature created mid-walk must not shift the remaining pages
+	_, err = adminClient.CreateFeature(ctx, apiclient.CreateFeatureRequest{
+		Name:    "Paginated Feature",
+		Summary: "Created while paging",
+	})
+	require.NoError(t, err, "create feature")
+
+	seen := make(map[string]bool)
+	var ids []string
+	for f, iterErr := range adminClient.SearchAll(ctx, "", 7) {
+		require.NoError(t, iterErr, "walk pages")
+		assert.False(t, seen[f.ID], "feature %s returned twice", f.ID)
+		seen[f.ID] = true
+		ids = append(ids, f.ID)
+	}
+	assert.IsNonDecreasing(t, ids, "pages should be ordered by ID")
+
+	all, err := adminClient.Search(ctx, "", 500)
+	require.NoError(t, err, "single page search")
+	assert.Len(t, ids, len(all), "walking pages should return the full catalog")
+}
+
 // TestSuggest verifies autocomplete functionality.
 func TestSuggest(t *testing.T) {
 	if testing.Short() {
@@ -329,3 +391,253 @@ func TestMe(t *testing.T) {
 		assert.NotEmpty(t, info.Fingerprint)
 	})
 }
+
+// TestAudit verifies that feature writes are recorded in the audit log.
+func TestAudit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	env, err := testutil.SetupTestEnv(ctx)
+	require.NoError(t, err, "setup test environment")
+	defer env.Cleanup(ctx)
+
+	adminClient, err := testutil.NewAdminClient(env)
+	require.NoError(t, err, "create admin client")
+
+	created, err := adminClient.CreateFeature(ctx, apiclient.CreateFeatureRequest{
+		Name:    "Audited Feature",
+		Summary: "Every change to this feature is recorded",
+	})
+	require.NoError(t, err, "create feature")
+
+	summary := "Changed summary"
+	_, err = adminClient.UpdateFeature(ctx, created.ID, apiclient.UpdateFeatureRequest{Summary: &summary}, created.Version)
+	require.NoError(t, err, "update feature")
+	require.NoError(t, adminClient.DeleteFeature(ctx, created.ID, 0), "delete feature")
+
+	entries, err := adminClient.Audit(ctx, apiclient.AuditQuery{Target: created.ID})
+	require.NoError(t, err, "read audit log")
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, "feature.create", entries[0].Action)
+	assert.Empty(t, entries[0].Before)
+	assert.Contains(t, string(entries[0].After), "Audited Feature")
+	assert.Equal(t, "feature.update", entries[1].Action)
+	assert.Contains(t, string(entries[1].Before), "Every change to this feature is recorded")
+	assert.Contains(t, string(entries[1].After), summary)
+	assert.Equal(t, "feature.delete", entries[2].Action)
+	assert.Empty(t, entries[2].After)
+	for _, e := range entries {
+		assert.Equal(t, "admin", e.ActorName)
+		assert.NotEmpty(t, e.ActorFingerprint)
+	}
+
+	// Following the log from the last seen entry returns nothing new
+	newer, err := adminClient.Audit(ctx, apiclient.AuditQuery{AfterID: entries[2].ID})
+	require.NoError(t, err, "follow audit log")
+	assert.Empty(t, newer)
+
+	userClient, err := testutil.NewUserClient(env)
+	require.NoError(t, err, "create user client")
+	require.NoError(t, testutil.RegisterUserClient(ctx, adminClient, env.Certs), "register user")
+	_, err = userClient.Audit(ctx, apiclient.AuditQuery{})
+	require.Error(t, err, "non-admins cannot read the audit log")
+}
+
+// TestTeamScopedWrites verifies that features:write:own covers the client's teams only.
+func TestTeamScopedWrites(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	env, err := testutil.SetupTestEnv(ctx)
+	require.NoError(t, err, "setup test environment")
+	defer env.Cleanup(ctx)
+
+	adminClient, err := testutil.NewAdminClient(env)
+	require.NoError(t, err, "create admin client")
+	require.NoError(t, testutil.RegisterUserClient(ctx, adminClient, env.Certs), "register user")
+
+	clients, err := adminClient.ListClients(ctx)
+	require.NoError(t, err, "list clients")
+	var fingerprint string
+	for _, c := range clients {
+		if c.Name == "testuser" {
+			fingerprint = c.Fingerprint
+		}
+	}
+	require.NotEmpty(t, fingerprint, "registered user not listed")
+
+	teams := []string{"Payments"}
+	updated, err := adminClient.UpdateClient(ctx, fingerprint, apiclient.ClientUpdate{Teams: &teams})
+	require.NoError(t, err, "set teams")
+	assert.Equal(t, teams, updated.Teams)
+
+	userClient, err := testutil.NewUserClient(env)
+	require.NoError(t, err, "create user client")
+
+	me, err := userClient.Me(ctx)
+	require.NoError(t, err, "get me")
+	assert.Equal(t, teams, me.Teams)
+	assert.Contains(t, me.Permissions, "features:write:own")
+
+	// Owners match teams case-insensitively
+	own, err := userClient.CreateFeature(ctx, apiclient.CreateFeatureRequest{
+		Name:    "Refunds",
+		Summary: "Refund processing",
+		Owner:   "payments",
+	})
+	require.NoError(t, err, "create feature for own team")
+
+	summary := "Refund and chargeback processing"
+	_, err = userClient.UpdateFeature(ctx, own.ID, apiclient.UpdateFeatureRequest{Summary: &summary}, 0)
+	require.NoError(t, err, "update own feature")
+
+	// Moving a feature to another team is outside the client's scope
+	other := "Billing"
+	_, err = userClient.UpdateFeature(ctx, own.ID, apiclient.UpdateFeatureRequest{Owner: &other}, 0)
+	require.ErrorIs(t, err, apiclient.ErrPermissionDenied)
+
+	_, err = userClient.CreateFeature(ctx, apiclient.CreateFeatureRequest{
+		Name:    "Invoices",
+		Summary: "Invoice generation",
+		Owner:   "Billing",
+	})
+	require.ErrorIs(t, err, apiclient.ErrPermissionDenied)
+
+	billing, err := adminClient.CreateFeature(ctx, apiclient.CreateFeatureRequest{
+		Name:    "Invoices",
+		Summary: "Invoice generation",
+		Owner:   "Billing",
+	})
+	require.NoError(t, err, "admins write any feature")
+	_, err = userClient.UpdateFeature(ctx, billing.ID, apiclient.UpdateFeatureRequest{Summary: &summary}, 0)
+	require.ErrorIs(t, err, apiclient.ErrPermissionDenied)
+}
+
+// TestRateLimit verifies per-role limits: a limited client gets 429 once its
+// bucket is empty, while roles without a limit are unaffected.
+func TestRateLimit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	env, err := testutil.SetupTestEnv(ctx, "-rate-limit", "user=3/m")
+	require.NoError(t, err, "setup test environment")
+	defer env.Cleanup(ctx)
+
+	adminClient, err := testutil.NewAdminClient(env)
+	require.NoError(t, err, "create admin client")
+	require.NoError(t, testutil.RegisterUserClient(ctx, adminClient, env.Certs), "register user")
+
+	userClient, err := testutil.NewUserClient(env)
+	require.NoError(t, err, "create user client")
+	for i := range 3 {
+		_, err = userClient.Me(ctx)
+		require.NoError(t, err, "request %d within the burst", i+1)
+	}
+	// The next token is 20s away, longer than the client waits before giving up
+	_, err = userClient.Me(ctx)
+	require.ErrorIs(t, err, apiclient.ErrRateLimited)
+
+	for range 10 {
+		_, err = adminClient.Me(ctx)
+		require.NoError(t, err, "admin is not limited")
+	}
+}
+
+// TestMetrics verifies that /metrics on the health port counts API requests
+// and reports catalog size.
+func TestMetrics(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	env, err := testutil.SetupTestEnv(ctx)
+	require.NoError(t, err, "setup test environment")
+	defer env.Cleanup(ctx)
+
+	adminClient, err := testutil.NewAdminClient(env)
+	require.NoError(t, err, "create admin client")
+	_, err = adminClient.Me(ctx)
+	require.NoError(t, err, "get me")
+
+	url := fmt.Sprintf("http://%s:%d/metrics", env.Server.Host, env.Server.HealthPort)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err, "scrape metrics")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	text := string(body)
+	assert.Contains(t, text, `feature_atlas_http_requests_total{route="/api/v1/me",method="GET",status="200"} 1`)
+	assert.Contains(t, text, `feature_atlas_http_request_duration_seconds_count{route="/api/v1/me",method="GET",status="200"} 1`)
+	assert.Contains(t, text, "\nfeature_atlas_features 10\n")
+	assert.Contains(t, text, "\nfeature_atlas_clients 1\n")
+}
+
+// TestEvents verifies the event stream: changes arrive as events, and a
+// subscription resumed from an event ID gets exactly what came after it.
+func TestEvents(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	env, err := testutil.SetupTestEnv(ctx)
+	require.NoError(t, err, "setup test environment")
+	defer env.Cleanup(ctx)
+
+	adminClient, err := testutil.NewAdminClient(env)
+	require.NoError(t, err, "create admin client")
+
+	created, err := adminClient.CreateFeature(ctx, apiclient.CreateFeatureRequest{
+		Name:    "Streamed Feature",
+		Summary: "Announced on the event stream",
+		Owner:   "Test Team",
+	})
+	require.NoError(t, err, "create feature")
+
+	// The server keeps every event since it started, seeding included
+	var createdEvent apiclient.Event
+	for ev, err := range adminClient.Events(ctx, "0") {
+		require.NoError(t, err, "stream events")
+		if ev.Type == apiclient.EventFeatureCreated {
+			createdEvent = ev
+			break
+		}
+	}
+	require.NotNil(t, createdEvent.Feature, "feature.created carries the feature")
+	assert.Equal(t, created.ID, createdEvent.Feature.ID)
+	assert.Regexp(t, `^\d+$`, createdEvent.ID)
+
+	summary := "Updated while nobody was listening"
+	_, err = adminClient.UpdateFeature(ctx, created.ID, apiclient.UpdateFeatureRequest{Summary: &summary}, 0)
+	require.NoError(t, err, "update feature")
+
+	for ev, err := range adminClient.Events(ctx, createdEvent.ID) {
+		require.NoError(t, err, "resume events")
+		assert.Equal(t, apiclient.EventFeatureUpdated, ev.Type, "first event after %s", createdEvent.ID)
+		require.NotNil(t, ev.Feature)
+		assert.Equal(t, summary, ev.Feature.Summary)
+		break
+	}
+}
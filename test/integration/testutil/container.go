@@ -40,6 +40,9 @@ type ServerContainerConfig struct {
 
 	// SeedCount is the number of features to seed. Defaults to 10.
 	SeedCount int
+
+	// Args are extra feature-atlasd flags, e.g. "-rate-limit", "user=3/m".
+	Args []string
 }
 
 // StartServerContainer starts a feature-atlasd container for testing.
@@ -57,7 +60,7 @@ func StartServerContainer(ctx context.Context, cfg ServerContainerConfig) (*Serv
 	req := testcontainers.ContainerRequest{
 		Image:        cfg.Image,
 		ExposedPorts: []string{containerAPIPort, containerHealthPort},
-		Cmd: []string{
+		Cmd: append([]string{
 			"-listen", ":8443",
 			"-health-port", ":8080",
 			"-tls-cert", "/certs/server.crt",
@@ -65,7 +68,7 @@ func StartServerContainer(ctx context.Context, cfg ServerContainerConfig) (*Serv
 			"-client-ca", "/certs/ca.crt",
 			"-admin-cert", "/certs/admin.crt",
 			"-seed", strconv.Itoa(cfg.SeedCount),
-		},
+		}, cfg.Args...),
 		Files: []testcontainers.ContainerFile{
 			{
 				HostFilePath:      cfg.Certs.CACertPath,
@@ -159,7 +162,8 @@ type TestEnv struct {
 }
 
 // SetupTestEnv creates a complete test environment with certs and running server.
-func SetupTestEnv(ctx context.Context) (*TestEnv, error) {
+// args are passed to feature-atlasd in addition to the defaults.
+func SetupTestEnv(ctx context.Context, args ...string) (*TestEnv, error) {
 	certs, err := GenerateCerts()
 	if err != nil {
 		return nil, fmt.Errorf("generate certs: %w", err)
@@ -168,6 +172,7 @@ func SetupTestEnv(ctx context.Context) (*TestEnv, error) {
 	server, err := StartServerContainer(ctx, ServerContainerConfig{
 		Certs:     certs,
 		SeedCount: 10,
+		Args:      args,
 	})
 	if err != nil {
 		certs.Cleanup()